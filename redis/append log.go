@@ -0,0 +1,43 @@
+package redis
+
+//An AppendLog is a log of entries stored one after another in a single String.
+//This takes up much less memory than a List of small strings, but the log has no idea where one entry ends and the next begins,
+//so entries should either be a fixed size or carry their own delimiters.
+//The length returned by Append is the offset to ReadFrom to get everything appended after that point
+type AppendLog struct {
+	String
+}
+
+//AsAppendLog creates an AppendLog stored in this String.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this String) AsAppendLog() AppendLog {
+	return AppendLog{this}
+}
+
+//APPEND command - 
+//Append adds the entry to the end of the log;
+//returns the new length of the log
+func (this AppendLog) Append(entry []byte) <-chan int {
+	return this.String.Append(string(entry))
+}
+
+//GETRANGE command - 
+//ReadFrom returns everything in the log from the byte offset given to the end.
+//If the offset is at (or past) the end of the log, the result is empty
+func (this AppendLog) ReadFrom(offset int) <-chan []byte {
+	in := this.GetRange(offset, -1)
+	out := make(chan []byte, 1)
+	go func() {
+		defer close(out)
+		if s, ok := <-in; ok {
+			out <- []byte(s)
+		}
+	}()
+	return out
+}
+
+//Use allows you to use this log on a different executor
+func (this AppendLog) Use(e SafeExecutor) AppendLog {
+	this.String = this.String.Use(e)
+	return this
+}
@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"sync"
+)
+
+//A Batch is an executor which holds on to every command issued on it until Run is called, then sends them all at once (thus saving on network costs).
+//It does the same thing as Pipeline, but without needing every command to be issued from within one function;
+//ie, for key := range keys { scores[key] = set.Use(batch).ScoreOf(key) } followed by <-batch.Run().
+//The channels the commands give back don't get anything until the batch has been run, so don't wait on them before calling Run
+type Batch struct {
+	client *Client
+	mutex  sync.Mutex
+	pipe   *pipe
+}
+
+//Batch creates an empty Batch, whose commands will be sent on this client.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *Client) Batch() *Batch {
+	return &Batch{client: this, pipe: this.newPipe()}
+}
+
+func (this *Batch) Execute(command command) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.pipe.Execute(command)
+}
+
+func (this *Batch) errCallback(err error, s string) {
+	this.client.errCallback(err, s)
+}
+
+func (this *Batch) configuration() Config {
+	return this.client.configuration()
+}
+
+//Size is the number of commands waiting for the batch to be run
+func (this *Batch) Size() int {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return len(this.pipe.commands)
+}
+
+//Run sends every command issued on the batch so far in a single round trip;
+//the channel closes once all of their replies have been read (and so their own channels have their results).
+//The batch is empty again afterwards, so it can be reused for the next set of commands
+func (this *Batch) Run() <-chan nothing {
+	this.mutex.Lock()
+	commands := this.pipe.commands
+	this.pipe = this.client.newPipe()
+	this.mutex.Unlock()
+
+	out := make(chan nothing, 1)
+	go func() {
+		defer close(out)
+		if len(commands) > 0 {
+			this.client.send(commands, false)
+		}
+		out <- nothing{}
+	}()
+	return out
+}
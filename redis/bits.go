@@ -8,7 +8,7 @@ type Bits struct {
 
 func newBits(client SafeExecutor, key string) Bits {
 	return Bits{
-		newKey(client, key),
+		newTypedKey(client, key, KeyTypeString),
 	}
 }
 
@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"context"
+	"errors"
+)
+
+//A CappedSortedSet is a zset which never holds more than a certain number of members;
+//every Add trims it back down to the members with the highest scores, so a "top N only" leaderboard doesn't need a separate sweeper.
+//Only Add enforces the cap; members added through the other SortedSet methods stay until the next Add
+type CappedSortedSet struct {
+	SortedSet
+	maxSize int
+}
+
+//errCappedSize is given when a CappedSortedSet is made with room for nothing
+var errCappedSize = errors.New("a CappedSortedSet needs a maximum size of at least 1")
+
+func newCappedSortedSet(client SafeExecutor, key string, maxSize int) CappedSortedSet {
+	return CappedSortedSet{
+		SortedSet: newSortedSet(client, key),
+		maxSize:   maxSize,
+	}
+}
+
+//MaxSize is the most members this zset keeps
+func (this CappedSortedSet) MaxSize() int {
+	return this.maxSize
+}
+
+const cappedAddScript = `
+redis.call('ZADD', KEYS[1], ARGV[1], ARGV[2])
+redis.call('ZREMRANGEBYRANK', KEYS[1], 0, -tonumber(ARGV[3]) - 1)
+if redis.call('ZSCORE', KEYS[1], ARGV[2]) then
+	return 1
+end
+return 0
+`
+
+//ZADD and ZREMRANGEBYRANK commands (within a lua script) - 
+//Add adds a member to the zset (or updates its score), then removes the lowest scoring members until it's back down to its maximum size, atomically;
+//returns whether the member is still in the zset afterwards (false means its score was too low to make the cut)
+func (this CappedSortedSet) Add(item string, score float64) <-chan bool {
+	if this.maxSize < 1 {
+		return BoolCommand(failingExecutor{this.client, errCappedSize}, "ZADD", this.key, ftoa(score), item)
+	}
+	return BoolCommand(this, evalArgs(cappedAddScript, []string{this.key}, ftoa(score), item, itoa(this.maxSize))...)
+}
+
+//Use allows you to use this zset on a different executor, still capped at the same size
+func (this CappedSortedSet) Use(e SafeExecutor) CappedSortedSet {
+	this.SortedSet = this.SortedSet.Use(e)
+	return this
+}
+
+//WithContext gives back the same capped zset, but with its commands tied to the context (see ContextExecutor).
+//(This is a lightweight function - does *not* involve network I/O)
+func (this CappedSortedSet) WithContext(ctx context.Context) CappedSortedSet {
+	this.SortedSet = this.SortedSet.WithContext(ctx)
+	return this
+}
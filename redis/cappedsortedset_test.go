@@ -0,0 +1,80 @@
+package redis
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCappedSortedSet(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.CappedSortedSet("Test_CappedSortedSet", 2)
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	if !<-ss.Add("A", 1) || !<-ss.Add("B", 2) {
+		t.Error("There should be room for the first two members")
+	}
+	if !<-ss.Add("C", 3) {
+		t.Error("A higher score should make the cut")
+	}
+	if <-ss.Add("D", 0) {
+		t.Error("A lower score should not make the cut")
+	}
+	if res := <-ss.IndexedBetween(0, -1); len(res) != 2 || res[0] != "B" || res[1] != "C" {
+		t.Error("Only the top two should be left, not", res)
+	}
+}
+
+func TestCappedSortedSetSize(t *testing.T) {
+	client := &Client{}
+	failed := false
+	client.SetErrorCallback(func(e error, s string) {
+		failed = e == errCappedSize
+	})
+
+	if res, ok := <-newCappedSortedSet(client, "Test_CappedSortedSetSize", 0).Add("A", 1); ok {
+		t.Error("A zset capped at nothing should not add anything, not", res)
+	}
+	if !failed {
+		t.Error("A zset capped at nothing should report an error")
+	}
+}
+
+func TestCappedSortedSetUse(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.CappedSortedSet("Test_CappedSortedSetUse", 1)
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	batch := r.Batch()
+	ss.Use(batch).Add("A", 1)
+	ss.Use(batch).Add("B", 2)
+	<-batch.Run()
+	if res := <-ss.IndexedBetween(0, -1); len(res) != 1 || res[0] != "B" {
+		t.Error("Adding through Use should still keep the zset capped, not", res)
+	}
+
+	<-ss.WithContext(context.Background()).Add("C", 3)
+	if res := <-ss.IndexedBetween(0, -1); len(res) != 1 || res[0] != "C" {
+		t.Error("Adding with a context should still keep the zset capped, not", res)
+	}
+}
+
+func TestCappedSortedSetUseKeepsCap(t *testing.T) {
+	client := &Client{}
+	failed := false
+	client.SetErrorCallback(func(e error, s string) {
+		failed = e == errCappedSize
+	})
+
+	if res, ok := <-newCappedSortedSet(nil, "Test_CappedSortedSetUseKeepsCap", 0).Use(client).Add("A", 1); ok {
+		t.Error("A zset capped at nothing should not add anything through Use either, not", res)
+	}
+	if !failed {
+		t.Error("Use should give back a zset that is still capped")
+	}
+}
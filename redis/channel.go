@@ -128,6 +128,12 @@ func (this Channel) Publish(message string) <-chan int {
 	return IntCommand(this, this.args("publish", message)...)
 }
 
+//PublishBytes publishes a binary message on this channel, byte for byte;
+//use Client.SubscribeBinary to receive it as bytes
+func (this Channel) PublishBytes(message []byte) <-chan int {
+	return this.Publish(string(message))
+}
+
 //Use allows you to use this key on a different executor
 func (this Channel) Use(e SafeExecutor) Channel {
 	this.Key.client = e
@@ -41,6 +41,7 @@ type Executor interface {
 type SafeExecutor interface {
 	Executor
 	errCallback(error, string)
+	configuration() Config
 }
 
 func buildCommand(arguments []string) ([]byte, error) {
@@ -90,7 +91,7 @@ func getResponse(conn io.Reader) (*response, error) {
 			return nil, err
 		}
 
-		return nil, errors.New(errString)
+		return nil, replyError(errString)
 	case isStatus, isInt:
 		return getStringResponse(conn)
 	case isBulk:
@@ -146,7 +147,8 @@ func getBulk(conn io.Reader) (*response, error) {
 	}
 
 	b := make([]byte, strlen+len(delimiter))
-	i, err := conn.Read(b)
+	//large values won't necessarily arrive in a single read
+	i, err := io.ReadFull(conn, b)
 	if err != nil {
 		//the read should be successful
 		return nil, err
@@ -289,7 +291,7 @@ func (this floatCommand) callback() func(*response) error {
 		defer close(this.output)
 		if r != nil {
 			f, err := atof(r.val)
-			if err != nil {
+			if err == nil {
 				this.output <- f
 			}
 		}
@@ -456,6 +458,196 @@ func (this mapCommand) callback() func(*response) error {
 
 /*
 
+rawCommand - the command type used when the response has to be looked at directly, usually because it's nested
+
+*/
+
+type rawCommand struct {
+	args   []string
+	output chan<- *response
+}
+
+//rawResponse executes the command specified by the arguments specified.
+//It returns the response exactly as Redis sent it
+func rawResponse(e Executor, args ...string) <-chan *response {
+	c := make(chan *response, 1)
+	e.Execute(rawCommand{args, c})
+	return c
+}
+
+func (this rawCommand) arguments() []string {
+	return this.args
+}
+
+func (this rawCommand) callback() func(*response) error {
+	return func(r *response) error {
+		defer close(this.output)
+		if r != nil {
+			this.output <- r
+		}
+		return nil
+	}
+}
+
+/*
+
+ReplyCommand - the command type used when nothing is known about the reply ahead of time
+
+*/
+
+//A Reply is a reply from redis that hasn't been converted to any particular type yet.
+//It is either nil, a single value, or an array of other replies
+type Reply struct {
+	response *response
+}
+
+//IsNil returns whether redis replied with nil
+func (this Reply) IsNil() bool {
+	return this.response == nil
+}
+
+//IsArray returns whether redis replied with an array of replies
+func (this Reply) IsArray() bool {
+	return this.response != nil && this.response.subresponses != nil
+}
+
+//String returns the reply as a string; nil and arrays give an empty string
+func (this Reply) String() string {
+	if this.response == nil {
+		return ""
+	}
+	return this.response.val
+}
+
+//Int returns the reply as an integer
+func (this Reply) Int() (int, error) {
+	if this.response == nil {
+		return 0, errors.New("Reply is nil")
+	}
+	return atoi(this.response.val)
+}
+
+//Float returns the reply as a floating point number
+func (this Reply) Float() (float64, error) {
+	if this.response == nil {
+		return 0, errors.New("Reply is nil")
+	}
+	return atof(this.response.val)
+}
+
+//Array returns the replies within an array reply; anything else gives nil
+func (this Reply) Array() []Reply {
+	if !this.IsArray() {
+		return nil
+	}
+	replies := make([]Reply, len(this.response.subresponses))
+	for i, sub := range this.response.subresponses {
+		replies[i] = Reply{sub}
+	}
+	return replies
+}
+
+type replyCommand struct {
+	args    []string
+	output  chan<- Reply
+	failure *bool //	set when the command fails, so that it isn't mistaken for a nil reply
+}
+
+//ReplyCommand executes the command specified by the arguments specified.
+//It returns the reply without converting it, so it can be used for any command, including ones added by modules.
+//Unlike the other command types, a nil reply is still sent (check with IsNil); the channel only closes without a value if there was an error
+func ReplyCommand(e Executor, args ...string) <-chan Reply {
+	c := make(chan Reply, 1)
+	e.Execute(replyCommand{args, c, new(bool)})
+	return c
+}
+
+func (this replyCommand) arguments() []string {
+	return this.args
+}
+
+func (this replyCommand) failed() {
+	*this.failure = true
+}
+
+func (this replyCommand) callback() func(*response) error {
+	return func(r *response) error {
+		defer close(this.output)
+		if !*this.failure {
+			this.output <- Reply{r}
+		}
+		return nil
+	}
+}
+
+/*
+
+ErrorCommand - the command type used when the caller wants to know whether or not the command worked
+
+*/
+
+//errorHandler is a command that wants to be told about its own errors, rather than having them go to the error callback
+type errorHandler interface {
+	handleError(error)
+}
+
+//failureListener is a command that needs to know that it failed, but still has its errors go to the error callback
+type failureListener interface {
+	failed()
+}
+
+//fail tells a command that it won't be getting a response
+func fail(command command, err error) {
+	if r, ok := command.(errorRecorder); ok && err != nil {
+		if w, ok := command.(errorWrapper); ok {
+			r.recordError(w.wrapError(err))
+		} else {
+			r.recordError(err)
+		}
+	}
+	inner := innerCommand(command)
+	if h, ok := inner.(errorHandler); ok && err != nil {
+		h.handleError(err)
+	}
+	if l, ok := inner.(failureListener); ok {
+		l.failed()
+	}
+	command.callback()(nil)
+}
+
+type errorCommand struct {
+	args   []string
+	output chan error
+}
+
+//ErrorCommand executes the command specified by the arguments specified.
+//It sends back nil if the command worked, or the error if it didn't; these errors do not go to the error callback
+func ErrorCommand(e Executor, args ...string) <-chan error {
+	c := make(chan error, 1)
+	e.Execute(errorCommand{args, c})
+	return c
+}
+
+func (this errorCommand) arguments() []string {
+	return this.args
+}
+
+func (this errorCommand) handleError(e error) {
+	this.output <- e
+}
+
+func (this errorCommand) callback() func(*response) error {
+	return func(r *response) error {
+		defer close(this.output)
+		if r != nil {
+			this.output <- nil
+		}
+		return nil
+	}
+}
+
+/*
+
 NilCommand - the command type used when no response is expected
 
 */
@@ -2,15 +2,35 @@ package redis
 
 import (
 	"net"
-	"strings"
+	"time"
 )
 
 //A Connection is a single connection to a Redis Instance.
 //Each client typically has a pool of these to work with
 type Connection struct {
 	net.Conn
-	id     int
-	client *Client
+	id       int
+	client   *Client
+	lastUsed time.Time
+	db       int   //the database selected on this connection
+	broken   *bool //set once a reply couldn't be read properly, since there's no telling what is still left to read
+}
+
+//ConnInfo describes where a connection's commands end up, for when a command isn't behaving the way it is expected to
+type ConnInfo struct {
+	NetType    string //ie, "tcp" or "unix"
+	RemoteAddr string //the address of the redis server at the other end of the connection
+	DB         int    //the database selected on the connection
+}
+
+//ConnectionInfo returns the server address and database this connection is using
+func (this Connection) ConnectionInfo() ConnInfo {
+	info := ConnInfo{DB: this.db}
+	if addr := this.RemoteAddr(); addr != nil {
+		info.NetType = addr.Network()
+		info.RemoteAddr = addr.String()
+	}
+	return info
 }
 
 func (this Connection) input(command command) error {
@@ -20,34 +40,87 @@ func (this Connection) input(command command) error {
 	}
 
 	_, err = this.Write(comm)
-	return err
+	return this.check(err)
 }
 
 func (this Connection) output(command command) error {
-	res, err := getResponse(this)
+	res, err := this.response()
 	if err != nil {
-		command.callback()(nil)
+		fail(command, err)
 		return err
 	}
 
 	return command.callback()(res)
 }
 
+//receive gets the reply to a command that has already been sent;
+//commands that stream their own replies are given the connection to read from directly
+func (this Connection) receive(command command) error {
+	if s, ok := asStreamer(command); ok {
+		return this.check(s.stream(this))
+	}
+	return this.output(command)
+}
+
+//response reads the next reply off of the connection
+func (this Connection) response() (*response, error) {
+	res, err := getResponse(this)
+	return res, this.check(err)
+}
+
+//check marks the connection as broken if the error came from the connection itself, rather than from redis refusing a command.
+//A broken connection might be partway through a reply (or have one still on its way), so it never goes back into the pool
+func (this Connection) check(err error) error {
+	if _, refused := err.(replyError); err != nil && !refused && this.broken != nil {
+		*this.broken = true
+	}
+	return err
+}
+
+//markBroken makes sure the connection never goes back into the pool (ie, when it was interrupted partway through a command)
+func (this Connection) markBroken() {
+	if this.broken != nil {
+		*this.broken = true
+	}
+}
+
+//isBroken returns whether something went wrong reading from or writing to the connection
+func (this Connection) isBroken() bool {
+	return this.broken != nil && *this.broken
+}
+
+//ping makes sure the connection is still usable
+func (this Connection) ping() error {
+	this.SetDeadline(time.Now().Add(pingTimeout))
+	defer this.SetDeadline(time.Time{})
+
+	comm, err := buildCommand([]string{"PING"})
+	if err != nil {
+		return err
+	}
+	if _, err = this.Write(comm); err != nil {
+		return err
+	}
+	_, err = getResponse(this)
+	return err
+}
+
 //Error is how an error gets reported.
 //Since The redis code operates in a separate goroutine, errors can't always be reported directly
 func (this Connection) Error(e error, c command) {
-	this.client.errCallback(e, strings.Join(c.arguments(), " "))
+	this.client.commandError(e, c)
 }
 
 //Execute allows a command to be executed on a specific connection
 func (this Connection) Execute(command command) {
 	err := this.input(command)
 	if err != nil {
+		fail(command, err)
 		this.Error(err, command)
 		return
 	}
 
-	err = this.output(command)
+	err = this.receive(command)
 	if err != nil {
 		this.Error(err, command)
 	}
@@ -0,0 +1,248 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+//A ContextClient issues commands through a Client, but gives up on them once its context is done.
+//Commands that haven't been sent yet are never sent, and commands that are waiting on a reply are abandoned;
+//either way, their channels close without a value.
+//Since a connection with an abandoned command might still get a reply later, that connection gets replaced rather than reused
+type ContextClient struct {
+	client *Client
+	ctx    context.Context
+}
+
+//WithContext creates a ContextClient, which can be used anywhere the Client could be, but whose commands are tied to the context.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *Client) WithContext(ctx context.Context) *ContextClient {
+	return &ContextClient{
+		client: this,
+		ctx:    ctx,
+	}
+}
+
+//ContextExecutor gives back an executor whose commands are tied to the context, the same way a ContextClient's are,
+//so that any of the command functions can be cancelled - ie, IntCommand(ContextExecutor(ctx, client), ...).
+//A ContextClient has its context replaced by this one, and anything wrapped around a client (ie, an ErrorCollector) keeps doing its job, but on top of the context.
+//Anything else (ie, a pipeline or transaction, whose commands are all sent together) is given back unchanged
+func ContextExecutor(ctx context.Context, e SafeExecutor) SafeExecutor {
+	switch client := e.(type) {
+	case *Client:
+		return client.WithContext(ctx)
+	case *ContextClient:
+		return client.client.WithContext(ctx)
+	case *ErrorCollector:
+		return collectingExecutor{ContextExecutor(ctx, client.SafeExecutor), client}
+	case collectingExecutor:
+		return collectingExecutor{ContextExecutor(ctx, client.SafeExecutor), client.collector}
+	case failingExecutor:
+		return failingExecutor{ContextExecutor(ctx, client.SafeExecutor), client.err}
+	}
+	return e
+}
+
+//Execute allows commands to be executed directly through the ContextClient without needing to specify a key
+func (this *ContextClient) Execute(command command) {
+	go func() {
+		command, end := this.client.trace(this.ctx, command)
+		defer end()
+		if !this.client.limiter.waitUntil(this.ctx.Done(), 1) {
+			fail(command, this.ctx.Err())
+			return
+		}
+		if err := this.client.useConnectionContext(this.ctx, func(conn *Connection) {
+			conn.executeContext(this.ctx, command)
+		}); err != nil {
+			fail(command, err)
+			if err == ErrPoolTimeout {
+				this.client.commandError(err, command)
+			}
+		}
+	}()
+}
+
+func (this *ContextClient) errCallback(e error, s string) {
+	this.client.errCallback(e, s)
+}
+
+func (this *ContextClient) configuration() Config {
+	return this.client.configuration()
+}
+
+//useConnectionContext borrows a connection like useConnection does, but stops waiting for one once the context is done (or the WaitTimeout is up, whichever is first),
+//and interrupts whatever the connection is in the middle of if the context finishes while it's being used;
+//returns why the callback was never called, or nil if it was
+func (this *Client) useConnectionContext(ctx context.Context, callback func(*Connection)) error {
+	if this.isClosed {
+		return errors.New("Redis is already closed!")
+	}
+
+	conn, err := this.borrow(ctx.Done())
+	if err == errCanceled {
+		return ctx.Err()
+	} else if err != nil {
+		return err
+	}
+	defer this.giveBack(conn)
+
+	finished := make(chan nothing)
+	interrupted := make(chan bool, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			//an expired deadline makes any read or write in progress fail immediately
+			conn.SetDeadline(time.Now())
+			interrupted <- true
+		case <-finished:
+			interrupted <- false
+		}
+	}()
+
+	callback(conn)
+	close(finished)
+
+	if <-interrupted {
+		//we can't be sure there isn't still a reply on its way, so this connection can't be trusted anymore, and giveBack replaces it.
+		//(a RESET can't help here - the old reply could still arrive after RESET's own)
+		conn.markBroken()
+	}
+	return nil
+}
+
+//executeContext is like Execute, except that errors caused by the context finishing aren't reported
+func (this Connection) executeContext(ctx context.Context, command command) {
+	err := this.input(command)
+	if err != nil {
+		fail(command, err)
+	} else {
+		err = this.receive(command)
+	}
+
+	if err != nil && ctx.Err() == nil {
+		this.Error(err, command)
+	}
+}
+
+//Creates a basic key.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) Key(key string) Key {
+	return newKey(this, key)
+}
+
+//Creates a String object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) String(key string) String {
+	return newString(this, key)
+}
+
+//Creates an Integer object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) Integer(key string) Integer {
+	return newInteger(this, key)
+}
+
+//Creates a Float object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) Float(key string) Float {
+	return newFloat(this, key)
+}
+
+//Creates a Bits object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) Bits(key string) Bits {
+	return newBits(this, key)
+}
+
+//Creates a Hash object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) Hash(key string) Hash {
+	return newHash(this, key)
+}
+
+//Creates a List object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) List(key string) List {
+	return newList(this, key)
+}
+
+//Creates an IntList object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) IntList(key string) IntList {
+	return newIntList(this, key)
+}
+
+//Creates a Set Object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) Set(key string) Set {
+	return newSet(this, key)
+}
+
+//Creates an IntSet Object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) IntSet(key string) IntSet {
+	return newIntSet(this, key)
+}
+
+//Creates a SortedSet Object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) SortedSet(key string) SortedSet {
+	return newSortedSet(this, key)
+}
+
+//Creates a CappedSortedSet Object, which never keeps more than maxSize members.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) CappedSortedSet(key string, maxSize int) CappedSortedSet {
+	return newCappedSortedSet(this, key, maxSize)
+}
+
+//Creates a DecimalSortedSet Object, which ranks its members by exact decimal scores.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) DecimalSortedSet(key string) DecimalSortedSet {
+	return newDecimalSortedSet(this, key)
+}
+
+//Creates a SortedIntSet Object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) SortedIntSet(key string) SortedIntSet {
+	return newSortedIntSet(this, key)
+}
+
+//Creates a HyperLogLog Object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) HyperLogLog(key string) HyperLogLog {
+	return newHyperLogLog(this, key)
+}
+
+//Creates a Mutex Object.
+//(Warning - this is *not* a lightweight function - there is some network I/O involved in mutex initialization)
+func (this *ContextClient) Mutex(key string) Mutex {
+	return newMutex(this, key, 1)
+}
+
+//Creates a Semaphore Object.
+//(Warning - this is *not* a lightweight function - there is some network I/O involved in mutex initialization)
+func (this *ContextClient) Semaphore(key string, count int) Mutex {
+	return newMutex(this, key, count)
+}
+
+//Creates a ReadWriteMutex Object.
+//(Warning - this is *not* a lightweight function - there is some network I/O involved in mutex initialization)
+func (this *ContextClient) ReadWriteMutex(key string, readers int) *ReadWriteMutex {
+	return newRWMutex(this, key, readers)
+}
+
+//Creates a Channel Object.
+//Publishing is tied to the context, but subscriptions still use their own connections and are not.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) Channel(key string) Channel {
+	return newChannel(this.client, key).Use(this)
+}
+
+//Creates a Prefix Object, which helps namespace other Redis Objects.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) Prefix(key string) Prefix {
+	return newPrefix(this, key)
+}
@@ -0,0 +1,354 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextCancelsBlockingCommand(t *testing.T) {
+	config := DefaultConfiguration()
+	config.ConnectionCount = 1
+	r, err := New(config)
+	if err != nil {
+		t.Fatal("Can't load redis - " + err.Error())
+	}
+	defer r.Close()
+	r.SetErrorCallback(func(e error, s string) {
+		t.Error(e.Error() + " - " + s)
+	})
+
+	<-r.List("Test_ContextBlocking").Delete()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	popped := r.WithContext(ctx).List("Test_ContextBlocking").BlockUntilLeftPop()
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+	select {
+	case res, ok := <-popped:
+		if ok {
+			t.Error("A cancelled pop should not return anything, not", res)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Cancelling the context should abort the blocking pop")
+	}
+
+	//the only connection in the pool should have been replaced with a clean one
+	l := r.List("Test_ContextBlocking")
+	<-l.RightPush("A")
+	if res := <-l.LeftPop(); res != "A" {
+		t.Error("The pool should still work after a cancelled command, but got", res)
+	}
+}
+
+func TestContextCancelsBlockingPopMin(t *testing.T) {
+	config := DefaultConfiguration()
+	config.ConnectionCount = 1
+	r, err := New(config)
+	if err != nil {
+		t.Fatal("Can't load redis - " + err.Error())
+	}
+	defer r.Close()
+	r.SetErrorCallback(func(e error, s string) {
+		t.Error(e.Error() + " - " + s)
+	})
+
+	ss := r.SortedSet("Test_ContextBlockingPopMin")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	popped := BlockingPopMin(0, ss.WithContext(ctx))
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+	select {
+	case res, ok := <-popped:
+		if ok {
+			t.Error("A cancelled pop should not return anything, not", res)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Cancelling the context should abort the BZPOPMIN")
+	}
+
+	//if the cancelled BZPOPMIN were still waiting on the server, it would take this member
+	<-ss.Add("A", 1)
+	time.Sleep(50 * time.Millisecond)
+	if res := <-ss.Size(); res != 1 {
+		t.Error("The cancelled pop should not have taken anything, but the zset has", res, "members")
+	}
+}
+
+func TestContextCancelsRangeInFlight(t *testing.T) {
+	//a stand in for redis that takes forever to answer a ZRANGEBYSCORE, the same as one over a huge zset would
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Can't listen -", err)
+	}
+	defer listener.Close()
+	received := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buffer := make([]byte, 256)
+				for {
+					n, err := conn.Read(buffer)
+					if err != nil {
+						return
+					}
+					received <- string(buffer[:n])
+				}
+			}()
+		}
+	}()
+
+	config := DefaultConfiguration()
+	config.NetAddress = listener.Addr().String()
+	config.ConnectionCount = 1
+	r, err := New(config)
+	if err != nil {
+		t.Fatal("Can't connect -", err)
+	}
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ranged := r.WithContext(ctx).SortedSet("Test_ContextRangeInFlight").Scores().Get()
+
+	select {
+	case command := <-received:
+		if !strings.Contains(strings.ToLower(command), "zrangebyscore") {
+			t.Fatal("Should have sent a ZRANGEBYSCORE, not", command)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("The ZRANGEBYSCORE should have been sent")
+	}
+	cancel()
+
+	select {
+	case res, ok := <-ranged:
+		if ok {
+			t.Error("A cancelled range should not return anything, not", res)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Cancelling the context should abort the ZRANGEBYSCORE that's waiting on its reply")
+	}
+}
+
+func TestContextCancelledBeforeSending(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_ContextCancelled")
+	<-ss.Delete()
+	<-ss.Add("A", 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cancelled := r.WithContext(ctx)
+	if res, ok := <-cancelled.SortedSet("Test_ContextCancelled").Scores().Get(); ok {
+		t.Error("A cancelled context should not get anything back, not", res)
+	}
+	if res, ok := <-ss.Use(cancelled).ScoreOf("A"); ok {
+		t.Error("Using a cancelled context should not get anything back, not", res)
+	}
+	if res, ok := <-cancelled.Prefix("Test_").SortedSet("ContextCancelled").Size(); ok {
+		t.Error("Prefixes of a cancelled context should not get anything back, not", res)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if res := <-r.WithContext(ctx).SortedSet("Test_ContextCancelled").Scores().Get(); len(res) != 1 || res[0] != "A" {
+		t.Error("A live context should work like normal, but got", res)
+	}
+}
+
+func TestContextCancelDoesNotLeakReply(t *testing.T) {
+	config := DefaultConfiguration()
+	config.ConnectionCount = 1
+	r, err := New(config)
+	if err != nil {
+		t.Fatal("Can't load redis - " + err.Error())
+	}
+	defer r.Close()
+	r.SetErrorCallback(func(e error, s string) {
+		t.Error(e.Error() + " - " + s)
+	})
+
+	l := r.List("Test_ContextLeak")
+	<-l.Delete()
+	defer func() { <-l.Delete() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	popped := r.WithContext(ctx).List("Test_ContextLeak").BlockUntilLeftPop()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-popped
+
+	//if the cancelled BLPOP were still waiting on the server, it would take this item,
+	//and its reply would be left for whoever borrowed the connection next
+	<-l.RightPush("A")
+	time.Sleep(50 * time.Millisecond)
+	if res := <-l.Length(); res != 1 {
+		t.Error("The cancelled pop should not have taken anything, but the list has", res, "items")
+	}
+	if res := <-r.String("Test_ContextLeak:other").Get(); res != "" {
+		t.Error("The next command should get its own reply, not", res)
+	}
+}
+
+func TestBrokenConnection(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	conn := Connection{Conn: client, broken: new(bool)}
+
+	reply := func(frame string) {
+		go func() {
+			buffer := make([]byte, 64)
+			server.Read(buffer)
+			server.Write([]byte(frame))
+		}()
+	}
+
+	reply("-ERR refused\r\n")
+	if err := <-ErrorCommand(conn, "PING"); err == nil || conn.isBroken() {
+		t.Error("An error from redis should leave the connection usable, not", err, conn.isBroken())
+	}
+
+	reply("?garbage\r\n")
+	if err := <-ErrorCommand(conn, "PING"); err == nil || !conn.isBroken() {
+		t.Error("A reply that can't be read should break the connection, not", err, conn.isBroken())
+	}
+}
+
+func TestContextExecutor(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_ContextExecutor")
+	<-ss.Delete()
+	<-ss.Add("A", 1)
+	defer func() { <-ss.Delete() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if res, ok := <-ss.WithContext(ctx).Size(); ok {
+		t.Error("A zset with a cancelled context should not get anything back, not", res)
+	}
+	if res, ok := <-IntCommand(ContextExecutor(ctx, r), "ZCARD", ss.key); ok {
+		t.Error("A command with a cancelled context should not get anything back, not", res)
+	}
+	if res := <-ss.WithContext(context.Background()).Size(); res != 1 {
+		t.Error("A live context should work like normal, but got", res)
+	}
+	if res := <-ss.Use(r.WithContext(ctx)).WithContext(context.Background()).Size(); res != 1 {
+		t.Error("WithContext should replace the old context, but got", res)
+	}
+}
+
+func TestContextExecutorThroughErrorCollector(t *testing.T) {
+	sent := make(chan []string, 10)
+	r, done := FakeRedis(t, func(args []string) string {
+		sent <- args
+		return "-ERR refused\r\n"
+	})
+	defer done()
+	reported := make(chan bool, 1)
+	r.SetErrorCallback(func(error, string) {
+		reported <- true
+	})
+
+	collector := CollectErrors(r)
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, ok := <-IntCommand(ContextExecutor(ctx, collector), "INCR", "A"); ok {
+		t.Error("The command should have been refused")
+	}
+	<-sent
+	<-reported
+	if collector.LastError() == nil {
+		t.Error("The collector should still collect errors from commands tied to the context")
+	}
+
+	cancel()
+	if _, ok := <-IntCommand(ContextExecutor(ctx, collector), "INCR", "A"); ok {
+		t.Error("A command on a cancelled context should not give anything back")
+	}
+	if _, ok := <-r.SortedSet("A").Use(collector).WithContext(ctx).Size(); ok {
+		t.Error("A zset on a cancelled context should not give anything back")
+	}
+	select {
+	case args := <-sent:
+		t.Error("Nothing should be sent once the context is cancelled, not", args)
+	default:
+	}
+}
+
+func TestContextCancelsInFlightThroughWrappers(t *testing.T) {
+	//a stand in for redis that never answers, the same as a BZPOPMIN with nothing to pop, or a ZRANGEBYSCORE over a huge zset
+	received := make(chan string, 10)
+	stop := make(chan struct{})
+	r, done := FakeRedis(t, func(args []string) string {
+		received <- strings.ToUpper(args[0])
+		<-stop
+		return "$-1\r\n"
+	})
+	defer done()
+	defer close(stop)
+
+	zsets := map[string]func(ctx context.Context) SortedSet{
+		"ErrorCollector": func(ctx context.Context) SortedSet {
+			return r.SortedSet("A").Use(CollectErrors(r)).WithContext(ctx)
+		},
+		"ContextExecutor on an ErrorCollector": func(ctx context.Context) SortedSet {
+			return r.SortedSet("A").Use(ContextExecutor(ctx, CollectErrors(r)))
+		},
+		"ShardedClient": func(ctx context.Context) SortedSet {
+			return Shard(r).SortedSet("A").WithContext(ctx)
+		},
+	}
+	for name, zset := range zsets {
+		commands := map[string]func(ss SortedSet) bool{
+			"BZPOPMIN": func(ss SortedSet) bool {
+				_, ok := <-BlockingPopMin(0, ss)
+				return ok
+			},
+			"ZRANGEBYSCORE": func(ss SortedSet) bool {
+				_, ok := <-ss.Scores().Get()
+				return ok
+			},
+		}
+		for command, issue := range commands {
+			ctx, cancel := context.WithCancel(context.Background())
+			finished := make(chan bool, 1)
+			go func() {
+				finished <- issue(zset(ctx))
+			}()
+
+			select {
+			case got := <-received:
+				if got != command {
+					t.Error(name, "should have sent a", command, "not", got)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal(name, "never sent the", command)
+			}
+			cancel()
+			select {
+			case ok := <-finished:
+				if ok {
+					t.Error(name, "should not have given anything back from a cancelled", command)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Cancelling the context should have stopped the", command, "through the", name)
+			}
+		}
+	}
+}
@@ -1,13 +1,33 @@
 package redis
 
 import (
+	"math"
 	"strconv"
+	"time"
 )
 
 func ftoa(f float64) string {
 	return strconv.FormatFloat(f, 'f', -1, 64)
 }
 
+//roundTo rounds f to the number of decimal places given, with halfway values rounded away from zero
+func roundTo(f float64, decimals int) float64 {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return f
+	}
+	pow := math.Pow10(decimals)
+	if scaled := f * pow; !math.IsInf(scaled, 0) {
+		return math.Round(scaled) / pow
+	}
+	//there are more decimal places than a float64 can hold, so there's nothing to round
+	return f
+}
+
+//wholeSeconds gives the timeout for a blocking command, rounded up to whole seconds, since redis before 6.0 doesn't accept anything else
+func wholeSeconds(timeout time.Duration) string {
+	return itoa(int(math.Ceil(timeout.Seconds())))
+}
+
 func itoa(i int) string {
 	return strconv.Itoa(i)
 }
@@ -150,6 +170,22 @@ func maybeIntsChannel(in <-chan []*string) <-chan []*int {
 	return out
 }
 
+//maybeFloatChannel sends nil for a nil reply, rather than closing the channel without a value
+func maybeFloatChannel(in <-chan Reply) <-chan *float64 {
+	out := make(chan *float64, 1)
+	go func() {
+		defer close(out)
+		if reply, ok := <-in; ok {
+			if reply.IsNil() {
+				out <- nil
+			} else if f, err := reply.Float(); err == nil {
+				out <- &f
+			}
+		}
+	}()
+	return out
+}
+
 func maybeFloatsChannel(in <-chan []*string) <-chan []*float64 {
 	out := make(chan []*float64, 1)
 	go func() {
@@ -169,6 +205,109 @@ func maybeFloatsChannel(in <-chan []*string) <-chan []*float64 {
 	return out
 }
 
+func appliedFloatChannels(in <-chan float64) (<-chan float64, <-chan bool) {
+	out := make(chan float64, 1)
+	applied := make(chan bool, 1)
+	go func() {
+		defer close(out)
+		defer close(applied)
+		f, ok := <-in
+		if ok {
+			out <- f
+		}
+		applied <- ok
+	}()
+	return out, applied
+}
+
+func scoredMembersChannel(in <-chan []string) <-chan []ScoredMember {
+	out := make(chan []ScoredMember, 1)
+	go func() {
+		defer close(out)
+		if slice, ok := <-in; ok {
+			members := make([]ScoredMember, 0, len(slice)/2)
+			for i := 0; i+1 < len(slice); i += 2 {
+				if score, err := atof(slice[i+1]); err == nil {
+					members = append(members, ScoredMember{slice[i], score})
+				}
+			}
+			out <- members
+		}
+	}()
+	return out
+}
+
+func rankedMembersChannel(in <-chan []ScoredMember, first <-chan int) <-chan []RankedMember {
+	out := make(chan []RankedMember, 1)
+	go func() {
+		defer close(out)
+		members, ok := <-in
+		start, ok2 := <-first
+		if ok && ok2 {
+			ranked := make([]RankedMember, len(members))
+			for i, member := range members {
+				ranked[i] = RankedMember{start + i, member.Member, member.Score}
+			}
+			out <- ranked
+		}
+	}()
+	return out
+}
+
+func isZeroChannel(in <-chan int) <-chan bool {
+	out := make(chan bool, 1)
+	go func() {
+		defer close(out)
+		if i, ok := <-in; ok {
+			out <- i == 0
+		}
+	}()
+	return out
+}
+
+//slicePresence passes the slice along, with whether it had anything in it
+func slicePresence(in <-chan []string) (<-chan []string, <-chan bool) {
+	items := make(chan []string, 1)
+	present := make(chan bool, 1)
+	go func() {
+		defer close(items)
+		defer close(present)
+		if res, ok := <-in; ok {
+			items <- res
+			present <- len(res) > 0
+		}
+	}()
+	return items, present
+}
+
+//mapPresence passes the map along, with whether it had anything in it
+func mapPresence(in <-chan map[string]string) (<-chan map[string]string, <-chan bool) {
+	fields := make(chan map[string]string, 1)
+	present := make(chan bool, 1)
+	go func() {
+		defer close(fields)
+		defer close(present)
+		if res, ok := <-in; ok {
+			fields <- res
+			present <- len(res) > 0
+		}
+	}()
+	return fields, present
+}
+
+func sumChannel(in []<-chan int) <-chan int {
+	out := make(chan int, 1)
+	go func() {
+		defer close(out)
+		sum := 0
+		for _, c := range in {
+			sum += <-c
+		}
+		out <- sum
+	}()
+	return out
+}
+
 func stringfloatMapChannel(in <-chan map[string]string) <-chan map[string]float64 {
 	out := make(chan map[string]float64, 1)
 	go func() {
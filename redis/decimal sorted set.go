@@ -0,0 +1,220 @@
+package redis
+
+import (
+	"errors"
+	"strings"
+)
+
+/*
+A DecimalSortedSet ranks its members by exact decimal scores, for when a float64 would round them (ie, money).
+
+Every member is kept in a regular zset with a score of 0, so redis orders them by name (lexicographically);
+each name is the member's encoded score, a colon, then the member itself, so ordering by name orders by score.
+A hash alongside it (at the key followed by ":scores") maps each member to its encoded score, so that the old entry can be found when its score changes.
+
+A score is encoded as a sign character followed by a fixed number of digits on each side of the decimal point (30 before it, 18 after it):
+a non-negative score starts with "1", followed by its digits, zero padded;
+a negative score starts with "0", followed by the nines' complement of its absolute value's padded digits (each digit d becomes 9-d),
+so that bigger negative numbers come earlier.
+ie, 12.5 is "1" + "000...012" + "." + "500...000", and -12.5 is "0" + "999...987" + "." + "499...999".
+Members with the same score are ordered by name.
+*/
+type DecimalSortedSet struct {
+	set    SortedSet
+	scores Hash
+}
+
+const (
+	decimalIntegerDigits  = 30
+	decimalFractionDigits = 18
+)
+
+//errDecimal is given when a score isn't a decimal number that a DecimalSortedSet can hold exactly
+var errDecimal = errors.New("a DecimalSortedSet score needs to be a plain decimal number, with at most 30 digits before the decimal point and 18 after it")
+
+//DecimalMember is a member of a DecimalSortedSet along with its score, written as a plain decimal number
+type DecimalMember struct {
+	Member string
+	Score  string
+}
+
+func newDecimalSortedSet(client SafeExecutor, key string) DecimalSortedSet {
+	return DecimalSortedSet{
+		set:    newSortedSet(client, key),
+		scores: newHash(client, key+":scores"),
+	}
+}
+
+//encodeDecimal turns a decimal number into the string that sorts in the same place; returns false if it isn't a decimal number that fits
+func encodeDecimal(decimal string) (string, bool) {
+	negative := strings.HasPrefix(decimal, "-")
+	decimal = strings.TrimPrefix(decimal, "-")
+	integer, fraction := decimal, ""
+	if i := strings.Index(decimal, "."); i >= 0 {
+		integer, fraction = decimal[:i], decimal[i+1:]
+		if fraction == "" {
+			return "", false
+		}
+	}
+	integer = strings.TrimLeft(integer, "0")
+	fraction = strings.TrimRight(fraction, "0")
+	if len(integer) > decimalIntegerDigits || len(fraction) > decimalFractionDigits || decimal == "" || decimal[0] == '.' {
+		return "", false
+	}
+	for _, c := range integer + fraction {
+		if c < '0' || c > '9' {
+			return "", false
+		}
+	}
+
+	digits := strings.Repeat("0", decimalIntegerDigits-len(integer)) + integer + "." + fraction + strings.Repeat("0", decimalFractionDigits-len(fraction))
+	if !negative || strings.Trim(digits, "0.") == "" {
+		return "1" + digits, true
+	}
+	return "0" + complementDigits(digits), true
+}
+
+//decodeDecimal turns an encoded score back into a plain decimal number
+func decodeDecimal(encoded string) string {
+	if encoded == "" {
+		return ""
+	}
+	digits := encoded[1:]
+	sign := ""
+	if encoded[0] == '0' {
+		digits = complementDigits(digits)
+		sign = "-"
+	}
+	parts := strings.SplitN(digits, ".", 2)
+	integer := strings.TrimLeft(parts[0], "0")
+	if integer == "" {
+		integer = "0"
+	}
+	if len(parts) == 2 {
+		if fraction := strings.TrimRight(parts[1], "0"); fraction != "" {
+			return sign + integer + "." + fraction
+		}
+	}
+	return sign + integer
+}
+
+//complementDigits swaps every digit for 9 minus itself, leaving the decimal point alone
+func complementDigits(digits string) string {
+	complement := []byte(digits)
+	for i, c := range complement {
+		if c >= '0' && c <= '9' {
+			complement[i] = '9' - (c - '0')
+		}
+	}
+	return string(complement)
+}
+
+//splitEntry breaks an entry of the underlying zset into the member and its decoded score
+func splitEntry(entry string) DecimalMember {
+	parts := strings.SplitN(entry, ":", 2)
+	if len(parts) != 2 {
+		return DecimalMember{Member: entry}
+	}
+	return DecimalMember{Member: parts[1], Score: decodeDecimal(parts[0])}
+}
+
+const decimalAddScript = `
+local old = redis.call('HGET', KEYS[2], ARGV[1])
+if old then
+	redis.call('ZREM', KEYS[1], old .. ':' .. ARGV[1])
+end
+redis.call('ZADD', KEYS[1], 0, ARGV[2] .. ':' .. ARGV[1])
+redis.call('HSET', KEYS[2], ARGV[1], ARGV[2])
+if old then
+	return 0
+end
+return 1
+`
+
+//ZADD and HSET commands (within a lua script) -
+//Add adds a member with the decimal score given (like "-12.50"), or updates its score if it is already a member;
+//returns whether it was a new member.
+//If the score isn't a decimal number that fits (see DecimalSortedSet), the error goes to the error callback and nothing is added
+func (this DecimalSortedSet) Add(member, decimal string) <-chan bool {
+	encoded, ok := encodeDecimal(decimal)
+	if !ok {
+		return BoolCommand(failingExecutor{this.set.client, errDecimal}, "ZADD", this.set.key, decimal, member)
+	}
+	return BoolCommand(this.set, evalArgs(decimalAddScript, []string{this.set.key, this.scores.key}, member, encoded)...)
+}
+
+const decimalRemoveScript = `
+local old = redis.call('HGET', KEYS[2], ARGV[1])
+if not old then
+	return 0
+end
+redis.call('ZREM', KEYS[1], old .. ':' .. ARGV[1])
+redis.call('HDEL', KEYS[2], ARGV[1])
+return 1
+`
+
+//ZREM and HDEL commands (within a lua script) -
+//Remove removes a member; returns whether or not it was a member
+func (this DecimalSortedSet) Remove(member string) <-chan bool {
+	return BoolCommand(this.set, evalArgs(decimalRemoveScript, []string{this.set.key, this.scores.key}, member)...)
+}
+
+//HGET command -
+//ScoreOf returns the decimal score of a member, written as a plain decimal number (without any extra zeros)
+func (this DecimalSortedSet) ScoreOf(member string) <-chan string {
+	in := StringCommand(this.scores, this.scores.args("hget", member)...)
+	out := make(chan string, 1)
+	go func() {
+		defer close(out)
+		if encoded, ok := <-in; ok {
+			out <- decodeDecimal(encoded)
+		}
+	}()
+	return out
+}
+
+//ZCARD command -
+//Size returns the number of members
+func (this DecimalSortedSet) Size() <-chan int {
+	return this.set.Size()
+}
+
+//ZREVRANGEBYLEX command -
+//Top returns the n members with the highest scores, highest first
+func (this DecimalSortedSet) Top(n int) <-chan []DecimalMember {
+	return this.entries(SliceCommand(this.set, this.set.args("zrevrangebylex", "+", "-", "LIMIT", "0", itoa(n))...))
+}
+
+//ZRANGEBYLEX command -
+//Bottom returns the n members with the lowest scores, lowest first
+func (this DecimalSortedSet) Bottom(n int) <-chan []DecimalMember {
+	return this.entries(SliceCommand(this.set, this.set.args("zrangebylex", "-", "+", "LIMIT", "0", itoa(n))...))
+}
+
+func (this DecimalSortedSet) entries(in <-chan []string) <-chan []DecimalMember {
+	out := make(chan []DecimalMember, 1)
+	go func() {
+		defer close(out)
+		if entries, ok := <-in; ok {
+			members := make([]DecimalMember, len(entries))
+			for i, entry := range entries {
+				members[i] = splitEntry(entry)
+			}
+			out <- members
+		}
+	}()
+	return out
+}
+
+//DEL or UNLINK command -
+//Delete deletes the zset along with the hash of its scores; returns whether there was anything to delete
+func (this DecimalSortedSet) Delete() <-chan bool {
+	return BoolCommand(this.set.client, deleteCommand(this.set.client), this.set.key, this.scores.key)
+}
+
+//Use allows you to use this zset on a different executor
+func (this DecimalSortedSet) Use(e SafeExecutor) DecimalSortedSet {
+	this.set = this.set.Use(e)
+	this.scores = this.scores.Use(e)
+	return this
+}
@@ -0,0 +1,95 @@
+package redis
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestDecimalEncoding(t *testing.T) {
+	ordered := []string{"-1000", "-12.5", "-12.05", "-1", "-0.000000000000000001", "0", "0.000000000000000001", "1", "12.05", "12.5", "1000", "123456789012345678901234567890"}
+	encoded := make([]string, len(ordered))
+	for i, decimal := range ordered {
+		e, ok := encodeDecimal(decimal)
+		if !ok {
+			t.Fatal("Should be able to encode", decimal)
+		}
+		if res := decodeDecimal(e); res != decimal {
+			t.Error(decimal, "should decode back to itself, not", res)
+		}
+		encoded[i] = e
+	}
+	if !sort.StringsAreSorted(encoded) {
+		t.Error("Encoded scores should sort in the same order as the numbers, not", encoded)
+	}
+
+	same := map[string]string{"-0": "0", "007": "7", "1.50": "1.5", "-0.0": "0", "2.000": "2"}
+	for decimal, expected := range same {
+		if e, ok := encodeDecimal(decimal); !ok || decodeDecimal(e) != expected {
+			t.Error(decimal, "should be the same as", expected)
+		}
+	}
+
+	for _, decimal := range []string{"", "-", "1.", ".5", "1e5", "+1", "1.2.3", "abc", "0.0000000000000000001", "1234567890123456789012345678901"} {
+		if _, ok := encodeDecimal(decimal); ok {
+			t.Error(decimal, "should not be encodable")
+		}
+	}
+}
+
+func TestDecimalSortedSet(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ds := r.DecimalSortedSet("Test_DecimalSortedSet")
+	<-ds.Delete()
+	defer func() { <-ds.Delete() }()
+
+	if !<-ds.Add("A", "0.1") || !<-ds.Add("B", "0.2") || !<-ds.Add("C", "-5") || !<-ds.Add("D", "0.30000000000000001") {
+		t.Error("Each member should be new")
+	}
+	if <-ds.Add("C", "0.25") {
+		t.Error("Changing a score should not be a new member")
+	}
+	if res := <-ds.Size(); res != 4 {
+		t.Error("There should be 4 members, not", res)
+	}
+	if res := <-ds.ScoreOf("C"); res != "0.25" {
+		t.Error("C's score should have changed to 0.25, not", res)
+	}
+
+	top := <-ds.Top(3)
+	expected := []DecimalMember{{"D", "0.30000000000000001"}, {"C", "0.25"}, {"B", "0.2"}}
+	if len(top) != len(expected) {
+		t.Fatal("Top should give the top 3, not", top)
+	}
+	for i := range expected {
+		if top[i] != expected[i] {
+			t.Error("Position", i, "should be", expected[i], "not", top[i])
+		}
+	}
+	if res := <-ds.Bottom(1); len(res) != 1 || res[0].Member != "A" {
+		t.Error("A should be at the bottom, not", res)
+	}
+
+	if !<-ds.Remove("C") || <-ds.Remove("C") {
+		t.Error("C should only be removed once")
+	}
+	if res, ok := <-ds.ScoreOf("C"); ok {
+		t.Error("C should not have a score anymore, not", res)
+	}
+}
+
+func TestDecimalSortedSetInvalid(t *testing.T) {
+	client := &Client{}
+	failed := false
+	client.SetErrorCallback(func(e error, s string) {
+		failed = e == errDecimal
+	})
+
+	if res, ok := <-newDecimalSortedSet(client, "Test_DecimalSortedSetInvalid").Add("A", "1e5"); ok {
+		t.Error("An invalid score should not add anything, not", res)
+	}
+	if !failed {
+		t.Error("An invalid score should report an error")
+	}
+}
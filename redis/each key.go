@@ -0,0 +1,154 @@
+package redis
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+const (
+	//roughly how many keys get looked at at a time
+	eachKeyBatchSize = 100
+)
+
+//KeyErrors holds every error given back by the function passed to EachKey, by key
+type KeyErrors map[string]error
+
+func (this KeyErrors) Error() string {
+	keys := make([]string, 0, len(this))
+	for key := range this {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	messages := make([]string, len(keys))
+	for i, key := range keys {
+		messages[i] = key + ": " + this[key].Error()
+	}
+	return itoa(len(keys)) + " keys failed - " + strings.Join(messages, "; ")
+}
+
+//errTypeLookup is given for a key whose type never came back, without any other reason why
+var errTypeLookup = errors.New("couldn't look up the type of the key")
+
+//errScanFailed is given when a scan stops before reaching the end of the keys
+var errScanFailed = errors.New("the scan stopped before it had gone through every key")
+
+//SCAN and TYPE commands - 
+//EachKey calls f with every key matching the pattern along with its type.
+//The keys are found with SCAN, and their types are looked up in a single pipeline for each batch, so redis isn't blocked;
+//keys that disappear before their type is looked up are skipped, and keys whose type couldn't be looked up (ie, the connection failed) count as failed keys.
+//SCAN can give back the same key more than once, so the keys already seen are remembered, and f is only ever called once for each key.
+//f is called one key at a time, and an error from it doesn't stop the rest of the keys from being looked at.
+//The channel gets nil once every key has been looked at, a KeyErrors if f gave back any errors, or an error if the scan couldn't be finished
+func (this *Client) EachKey(pattern string, f func(key string, kind KeyType) error) <-chan error {
+	return eachKey(this, pattern, f, func() error { return nil })
+}
+
+//SCAN and TYPE commands - 
+//EachKey works the same way as Client.EachKey, but stops as soon as the context is done, and gives back the context's error
+func (this *ContextClient) EachKey(pattern string, f func(key string, kind KeyType) error) <-chan error {
+	return eachKey(this, pattern, f, this.ctx.Err)
+}
+
+func eachKey(e SafeExecutor, pattern string, f func(string, KeyType) error, stopped func() error) <-chan error {
+	out := make(chan error, 1)
+	go func() {
+		defer close(out)
+
+		keys := newScanner(e, func(cursor string) []string {
+			return []string{"SCAN", cursor, "MATCH", pattern, "COUNT", itoa(eachKeyBatchSize)}
+		})
+
+		failures := make(KeyErrors)
+		seen := make(map[string]struct{})
+		for batch, ok := keys.next(); ok; batch, ok = keys.next() {
+			fresh := make([]string, 0, len(batch))
+			for _, key := range batch {
+				if _, found := seen[key]; !found {
+					seen[key] = struct{}{}
+					fresh = append(fresh, key)
+				}
+			}
+
+			types, lookups := typesOf(e, fresh)
+			for i, key := range fresh {
+				kind, ok := <-types[i]
+				if !ok {
+					failures[key] = lookups[i].LastError()
+					if failures[key] == nil {
+						failures[key] = errTypeLookup
+					}
+					continue
+				}
+				if KeyType(kind) == KeyTypeNone {
+					//it went away since it was scanned
+					continue
+				}
+				if err := stopped(); err != nil {
+					out <- err
+					return
+				}
+				if err := f(key, KeyType(kind)); err != nil {
+					failures[key] = err
+				}
+			}
+		}
+
+		if err := stopped(); err != nil {
+			out <- err
+		} else if keys.failed {
+			out <- errScanFailed
+		} else if len(failures) > 0 {
+			out <- failures
+		} else {
+			out <- nil
+		}
+	}()
+	return out
+}
+
+//TYPE command - 
+//TypesOf looks up the type of every key given, in a single pipeline;
+//keys that don't exist are KeyTypeNone
+func (this *Client) TypesOf(keys ...string) <-chan map[string]KeyType {
+	types, _ := typesOf(this, keys)
+	return typesOfChannel(types, keys)
+}
+
+//TYPE command - 
+//TypesOf works the same way as Client.TypesOf, but is tied to the context
+func (this *ContextClient) TypesOf(keys ...string) <-chan map[string]KeyType {
+	types, _ := typesOf(this, keys)
+	return typesOfChannel(types, keys)
+}
+
+//typesOf issues a TYPE for each key in a single pipeline;
+//along with the types, it gives back what went wrong looking up each of them
+func typesOf(e SafeExecutor, keys []string) ([]<-chan string, []*ErrorCollector) {
+	types := make([]<-chan string, len(keys))
+	lookups := make([]*ErrorCollector, len(keys))
+	pipelined(e, func(p SafeExecutor) {
+		for i, key := range keys {
+			lookups[i] = CollectErrors(p)
+			types[i] = newKey(lookups[i], key).Type()
+		}
+	})
+	return types, lookups
+}
+
+//typesOfChannel gathers up the types of the keys; any key whose type couldn't be looked up is left out
+func typesOfChannel(types []<-chan string, keys []string) <-chan map[string]KeyType {
+	out := make(chan map[string]KeyType, 1)
+	go func() {
+		defer close(out)
+		res := make(map[string]KeyType, len(keys))
+		for i, key := range keys {
+			if kind, ok := <-types[i]; ok {
+				res[key] = KeyType(kind)
+			}
+		}
+		out <- res
+	}()
+	return out
+}
@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEachKey(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	str := r.String("Test_EachKey:string")
+	list := r.List("Test_EachKey:list")
+	<-str.Set("A")
+	<-list.RightPush("A")
+	defer func() {
+		<-str.Delete()
+		<-list.Delete()
+	}()
+
+	seen := make(map[string]KeyType)
+	err := <-r.EachKey("Test_EachKey:*", func(key string, kind KeyType) error {
+		seen[key] = kind
+		return nil
+	})
+	if err != nil {
+		t.Error("Should not have given an error, not", err)
+	}
+	if len(seen) != 2 || seen[str.key] != KeyTypeString || seen[list.key] != KeyTypeList {
+		t.Error("Should have seen both keys with their types, not", seen)
+	}
+
+	failure := errors.New("failed")
+	err = <-r.EachKey("Test_EachKey:*", func(key string, kind KeyType) error {
+		if kind == KeyTypeList {
+			return failure
+		}
+		return nil
+	})
+	if failures, ok := err.(KeyErrors); !ok || len(failures) != 1 || failures[list.key] != failure {
+		t.Error("Should have gathered up the error for the list, not", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	err = <-r.WithContext(ctx).EachKey("Test_EachKey:*", func(key string, kind KeyType) error {
+		called = true
+		return nil
+	})
+	if err != context.Canceled || called {
+		t.Error("Should stop once the context is done, not", err, called)
+	}
+}
+
+func TestTypesOf(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	str := r.String("Test_TypesOf:string")
+	set := r.Set("Test_TypesOf:set")
+	<-str.Set("A")
+	<-set.Add("A")
+	defer func() {
+		<-str.Delete()
+		<-set.Delete()
+	}()
+
+	res := <-r.TypesOf(str.key, set.key, "Test_TypesOf:missing")
+	if len(res) != 3 || res[str.key] != KeyTypeString || res[set.key] != KeyTypeSet || res["Test_TypesOf:missing"] != KeyTypeNone {
+		t.Error("Should have gotten each key's type, not", res)
+	}
+}
+
+func TestEachKeyDuplicatesAndFailedTypes(t *testing.T) {
+	r, done := FakeRedis(t, func(args []string) string {
+		switch strings.ToUpper(args[0]) {
+		case "SCAN":
+			//SCAN gives back B twice, once in each batch
+			if args[1] == "0" {
+				return "*2\r\n$1\r\n5\r\n*2\r\n$1\r\nA\r\n$1\r\nB\r\n"
+			}
+			return "*2\r\n$1\r\n0\r\n*2\r\n$1\r\nB\r\n$1\r\nC\r\n"
+		case "TYPE":
+			if args[1] == "C" {
+				return "-ERR can't look that up\r\n"
+			}
+			return "+string\r\n"
+		}
+		return "-ERR unexpected\r\n"
+	})
+	defer done()
+
+	calls := make(map[string]int)
+	err := <-r.EachKey("*", func(key string, kind KeyType) error {
+		calls[key]++
+		return nil
+	})
+	if calls["A"] != 1 || calls["B"] != 1 || calls["C"] != 0 {
+		t.Error("Each key should be looked at once, except for the one whose type couldn't be found, not", calls)
+	}
+	failures, ok := err.(KeyErrors)
+	if !ok || len(failures) != 1 || failures["C"] == nil {
+		t.Fatal("The key whose type couldn't be found should have failed, not", err)
+	}
+	if !strings.Contains(failures["C"].Error(), "can't look that up") {
+		t.Error("The failure should say why the type couldn't be found, not", failures["C"])
+	}
+}
@@ -0,0 +1,116 @@
+package redis
+
+import (
+	"sync"
+)
+
+//An ErrorCollector issues its commands on another executor, and keeps track of every error they run into.
+//Most commands just close their channel without a value when they fail, the same as when the key isn't there;
+//checking LastError after reading from the channel tells the two apart.
+//The errors still go to the error callback as well, so it doesn't change what the rest of the program sees.
+//Since the errors are shared by everything issued on it, an ErrorCollector is best made for one piece of work at a time
+type ErrorCollector struct {
+	SafeExecutor
+	mutex  sync.Mutex
+	errors []error
+}
+
+//CollectErrors gives an executor which sends everything to e, and remembers the errors along the way.
+//Use it with a key's Use method to find out why that key's commands failed, like
+//	c := redis.CollectErrors(client)
+//	if score, ok := <-set.Use(c).ScoreOf("member"); !ok && c.LastError() != nil { ... }
+//(This is a lightweight function - does *not* involve network I/O)
+func CollectErrors(e SafeExecutor) *ErrorCollector {
+	return &ErrorCollector{SafeExecutor: e}
+}
+
+func (this *ErrorCollector) Execute(c command) {
+	collectingExecutor{this.SafeExecutor, this}.Execute(c)
+}
+
+//collectingExecutor sends everything to its executor, but collects the errors on an ErrorCollector;
+//so that a collector's commands can be sent somewhere else (ie, tied to a context) while still being collected in the same place
+type collectingExecutor struct {
+	SafeExecutor
+	collector *ErrorCollector
+}
+
+func (this collectingExecutor) Execute(c command) {
+	this.SafeExecutor.Execute(checkedCommand{c, this.collector, new(sync.Once)})
+}
+
+//LastError is the most recent error that a command issued on this executor has run into, or nil if none have failed
+func (this *ErrorCollector) LastError() error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if len(this.errors) == 0 {
+		return nil
+	}
+	return this.errors[len(this.errors)-1]
+}
+
+//Errors is every error that the commands issued on this executor have run into, in the order they happened
+func (this *ErrorCollector) Errors() []error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return append([]error(nil), this.errors...)
+}
+
+//Reset forgets all of the errors that have been collected so far
+func (this *ErrorCollector) Reset() {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.errors = nil
+}
+
+func (this *ErrorCollector) add(e error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.errors = append(this.errors, e)
+}
+
+//errorRecorder is a command that wants to hear about its error, whether or not it goes to the error callback
+type errorRecorder interface {
+	recordError(error)
+}
+
+//errorExpecter is a command that deals with some of its own errors (ie, by falling back to a different command),
+//so they shouldn't be recorded as failures
+type errorExpecter interface {
+	expectedError(error) bool
+}
+
+//recordable is whether an error is worth recording for a command - ie, it isn't one the command expects and deals with itself
+func recordable(c command, e error) bool {
+	if e == nil {
+		return false
+	}
+	if x, ok := innerCommand(c).(errorExpecter); ok && x.expectedError(e) {
+		return false
+	}
+	return true
+}
+
+//checkedCommand is a command issued on an ErrorCollector.
+//A command can hear about the same failure more than once on its way out (once when it fails, again when it's reported), so only the first is kept
+type checkedCommand struct {
+	command
+	collector *ErrorCollector
+	once      *sync.Once
+}
+
+func (this checkedCommand) recordError(e error) {
+	if !recordable(this.command, e) {
+		return
+	}
+	this.once.Do(func() {
+		this.collector.add(e)
+	})
+}
+
+func (this checkedCommand) wrapError(e error) error {
+	if w, ok := this.command.(errorWrapper); ok {
+		return w.wrapError(e)
+	}
+	return e
+}
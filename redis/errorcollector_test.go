@@ -0,0 +1,68 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrorCollector(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+	reported := 0
+	r.SetErrorCallback(func(e error, s string) {
+		reported++
+	})
+
+	s := r.String("Test_ErrorCollector")
+	<-s.Set("A")
+	defer func() { <-s.Delete() }()
+
+	c := CollectErrors(r)
+	if res, ok := <-r.SortedSet("Test_ErrorCollector_Missing").Use(c).ScoreOf("A"); ok {
+		t.Error("A missing member should not have a score, not", res)
+	}
+	if err := c.LastError(); err != nil {
+		t.Error("A missing member is not an error, but got", err)
+	}
+
+	if res, ok := <-r.SortedSet("Test_ErrorCollector").Use(c).ScoreOf("A"); ok {
+		t.Error("A string should not have a score, not", res)
+	}
+	if _, ok := c.LastError().(ErrWrongType); !ok {
+		t.Error("Using a string as a zset should be collected as a type error, not", c.LastError())
+	}
+	if reported != 1 {
+		t.Error("The error should still go to the error callback once, not", reported, "times")
+	}
+
+	if err := <-ErrorCommand(c, "INVALIDCOMMAND"); err == nil {
+		t.Error("An invalid command should give back an error")
+	}
+	if errs := c.Errors(); len(errs) != 2 {
+		t.Error("Both errors should have been collected, not", errs)
+	}
+	c.Reset()
+	if err := c.LastError(); err != nil {
+		t.Error("Reset should forget the errors, not", err)
+	}
+}
+
+func TestErrorCollectorOnce(t *testing.T) {
+	reported := 0
+	client := &Client{}
+	client.SetErrorCallback(func(e error, s string) {
+		reported++
+	})
+	err := errors.New("refused")
+	c := CollectErrors(failingExecutor{client, err})
+
+	if _, ok := <-IntCommand(c, "INCR", "A"); ok {
+		t.Error("A failing command should not give anything back")
+	}
+	if errs := c.Errors(); len(errs) != 1 || errs[0] != err {
+		t.Error("The error should have been collected once, not", errs)
+	}
+	if reported != 1 {
+		t.Error("The error should still go to the error callback, not", reported, "times")
+	}
+}
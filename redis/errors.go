@@ -0,0 +1,106 @@
+package redis
+
+import (
+	"errors"
+	"strings"
+)
+
+//ErrPoolTimeout is given when a command had to wait longer than the WaitTimeout for a connection to be free
+var ErrPoolTimeout = errors.New("pool timeout: no connection became free within the WaitTimeout")
+
+//errCanceled is given when something stopped waiting for a connection, so it was never used
+var errCanceled = errors.New("stopped waiting for a connection")
+
+//ErrNoValue is given by the blocking (Sync) methods when redis didn't give anything back and nothing went wrong;
+//ie, asking for the score of a member that isn't in the zset
+var ErrNoValue = errors.New("redis did not give back a value")
+
+//errMaxArgsTooSmall is given when a bulk command can't be split up small enough to fit in the MaxArgsPerCommand
+var errMaxArgsTooSmall = errors.New("the MaxArgsPerCommand is too small to fit the key and a single member")
+
+//replyError is an error reply sent back by redis itself; unlike other errors, the connection is still fine to use after one of these
+type replyError string
+
+func (this replyError) Error() string {
+	return string(this)
+}
+
+//ErrWrongType is reported to the error callback when Redis refuses a command because the key holds a different type of value.
+//Key is the key that the command was issued against, and Expected is the type that the command needed
+type ErrWrongType struct {
+	Key      string
+	Expected KeyType
+	Message  string
+}
+
+func (this ErrWrongType) Error() string {
+	return this.Message + " (key '" + this.Key + "' was used as a " + string(this.Expected) + ")"
+}
+
+//errorWrapper is a command that knows how to give more context to the errors it causes
+type errorWrapper interface {
+	wrapError(error) error
+}
+
+//keyedCommand remembers which key a command was issued for, so that type errors can name it
+type keyedCommand struct {
+	command
+	key      string
+	expected KeyType
+}
+
+//innerCommand gives the command that was actually issued, looking past anything that was wrapped around it
+func innerCommand(c command) command {
+	for {
+		switch w := c.(type) {
+		case keyedCommand:
+			c = w.command
+		case checkedCommand:
+			c = w.command
+		case tracedCommand:
+			c = w.command
+		default:
+			return c
+		}
+	}
+}
+
+func (this keyedCommand) wrapError(e error) error {
+	if strings.HasPrefix(e.Error(), "WRONGTYPE") {
+		return ErrWrongType{Key: this.key, Expected: this.expected, Message: e.Error()}
+	}
+	return e
+}
+
+//onDifferentClients is whether the two executors are both clients, but not the same one.
+//Commands that use more than one key can't work then, since each client only sees its own keys.
+//(Anything else, like a pipeline, could be sending its commands to either of them, so there's no way to tell)
+func onDifferentClients(a, b SafeExecutor) bool {
+	ca, ok := a.(*Client)
+	cb, ok2 := b.(*Client)
+	return ok && ok2 && ca != cb
+}
+
+//crossShardGuard gives the executor for a command that uses all of these keys: the first key,
+//unless any of the others are on a different client, in which case the command can't work, so it fails with ErrCrossShard instead
+func crossShardGuard(first Key, others ...Key) Executor {
+	for _, other := range others {
+		if onDifferentClients(first.client, other.client) {
+			return failingExecutor{first.client, ErrCrossShard}
+		}
+	}
+	return first
+}
+
+//failingExecutor fails every command issued on it with the same error, without sending anything to redis
+type failingExecutor struct {
+	SafeExecutor
+	err error
+}
+
+func (this failingExecutor) Execute(c command) {
+	fail(c, this.err)
+	if _, ok := innerCommand(c).(errorHandler); !ok {
+		this.errCallback(this.err, strings.Join(c.arguments(), " "))
+	}
+}
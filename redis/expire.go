@@ -0,0 +1,54 @@
+package redis
+
+import (
+	"time"
+)
+
+const (
+	//roughly how many keys get expired at a time
+	expireBatchSize = 100
+)
+
+//SCAN and PEXPIRE (or EXPIRE) commands - 
+//ExpireMatching sets every key matching the pattern to expire after the duration given (see Key.ExpireIn for how precise that is).
+//The keys are found with SCAN, so redis isn't blocked, but keys added while it is running may or may not be included.
+//Each batch of keys is expired in a single pipeline;
+//returns how many keys were given the TTL
+func (this *Client) ExpireMatching(pattern string, ttl time.Duration) <-chan int {
+	return expireMatching(this, pattern, ttl)
+}
+
+//SCAN and PEXPIRE (or EXPIRE) commands - 
+//ExpireMatching works the same way as Client.ExpireMatching, but stops as soon as the context is done;
+//the count will include only the keys that were expired before then
+func (this *ContextClient) ExpireMatching(pattern string, ttl time.Duration) <-chan int {
+	return expireMatching(this, pattern, ttl)
+}
+
+func expireMatching(e SafeExecutor, pattern string, ttl time.Duration) <-chan int {
+	out := make(chan int, 1)
+	go func() {
+		defer close(out)
+
+		keys := newScanner(e, func(cursor string) []string {
+			return []string{"SCAN", cursor, "MATCH", pattern, "COUNT", itoa(expireBatchSize)}
+		})
+
+		expired := 0
+		for batch, ok := keys.next(); ok; batch, ok = keys.next() {
+			results := make([]<-chan bool, len(batch))
+			pipelined(e, func(p SafeExecutor) {
+				for i, key := range batch {
+					results[i] = newKey(p, key).ExpireIn(ttl)
+				}
+			})
+			for _, result := range results {
+				if <-result {
+					expired++
+				}
+			}
+		}
+		out <- expired
+	}()
+	return out
+}
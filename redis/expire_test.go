@@ -0,0 +1,43 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestExpireMatching(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	keys := []string{"Test_ExpireMatching:1", "Test_ExpireMatching:2", "Test_ExpireMatching:3"}
+	other := r.String("Test_ExpireMatchingOther")
+	for _, key := range keys {
+		<-r.String(key).Set("A")
+	}
+	<-other.Set("B")
+	defer func() {
+		for _, key := range keys {
+			<-r.Key(key).Delete()
+		}
+		<-other.Delete()
+	}()
+
+	if res := <-r.ExpireMatching("Test_ExpireMatching:*", time.Minute); res != len(keys) {
+		t.Error("Should have expired", len(keys), "keys, not", res)
+	}
+	for _, key := range keys {
+		if res := <-r.Key(key).SecondsToLive(); res <= 0 || res > 60 {
+			t.Error(key, "should have about a minute left, not", res)
+		}
+	}
+	if res := <-other.SecondsToLive(); res != -1 {
+		t.Error("Keys that don't match should not get a TTL, not", res)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if res := <-r.WithContext(ctx).ExpireMatching("Test_ExpireMatching:*", time.Hour); res != 0 {
+		t.Error("Should not expire anything once the context is done, not", res)
+	}
+}
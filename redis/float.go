@@ -8,7 +8,7 @@ type Float struct {
 
 func newFloat(client SafeExecutor, key string) Float {
 	return Float{
-		newKey(client, key),
+		newTypedKey(client, key, KeyTypeString),
 	}
 }
 
@@ -0,0 +1,98 @@
+package redis
+
+import (
+	"errors"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+const (
+	//how long GetOrSet's lock is held for at most, in case whoever holds it dies while computing
+	getOrSetLockTTL = 10 * time.Second
+	//how often GetOrSet checks whether somebody else has finished computing the value
+	getOrSetPollInterval = 10 * time.Millisecond
+)
+
+//errGetOrSetFailed is given when GetOrSet can't talk to redis at all
+var errGetOrSetFailed = errors.New("GetOrSet couldn't reach redis")
+
+//uniqueToken gives a string that nobody else should be using, to tell apart locks (or temporary keys) that would otherwise have the same name
+func uniqueToken() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + ":" + strconv.FormatInt(rand.Int63(), 36)
+}
+
+//GET and SET NX commands - 
+//GetOrSet returns the value of the key if it is there; otherwise, it calls compute and stores what it gives back with the TTL (a TTL of 0 never expires).
+//While one caller is computing the value, it holds a short lock (at key + ":lock"), and anybody else asking for the same key waits for that value instead of computing their own.
+//If somebody else stores a value while compute is running (ie, because the lock timed out), theirs is kept, and is what gets given back.
+//If compute gives back an error (or redis can't be reached), nothing is stored, the value's channel closes without a value, and the error is sent on the second channel;
+//otherwise the second channel gets nil
+func (this *Client) GetOrSet(key string, ttl time.Duration, compute func() (string, error)) (<-chan string, <-chan error) {
+	out := make(chan string, 1)
+	errs := make(chan error, 1)
+	value := this.String(key)
+	lock := this.String(key + ":lock")
+	token := uniqueToken()
+
+	go func() {
+		defer close(out)
+		defer close(errs)
+
+		deadline := time.Now().Add(getOrSetLockTTL)
+		for {
+			cached, ok := <-ReplyCommand(value, value.args("get")...)
+			if !ok {
+				errs <- errGetOrSetFailed
+				return
+			}
+			if !cached.IsNil() {
+				out <- cached.String()
+				errs <- nil
+				return
+			}
+
+			locked, ok := <-ReplyCommand(lock, lock.args("set", token, "PX", itoa(int(getOrSetLockTTL/time.Millisecond)), "NX")...)
+			if !ok {
+				errs <- errGetOrSetFailed
+				return
+			}
+			if !locked.IsNil() {
+				break
+			}
+			if time.Now().After(deadline) {
+				//whoever has the lock is taking too long, so stop waiting on them
+				break
+			}
+			time.Sleep(getOrSetPollInterval)
+		}
+		defer lock.DeleteIfEquals(token)
+
+		computed, err := compute()
+		if err != nil {
+			errs <- err
+			return
+		}
+
+		args := value.args("set", computed)
+		if ms := int(ttl / time.Millisecond); ms > 0 {
+			args = append(args, "PX", itoa(ms))
+		} else if ttl > 0 {
+			args = append(args, "PX", "1")
+		}
+		stored, ok := <-ReplyCommand(value, append(args, "NX")...)
+		if !ok {
+			errs <- errGetOrSetFailed
+			return
+		}
+		if stored.IsNil() {
+			//somebody else stored a value first (ie, after the lock timed out), so give back theirs rather than one that was never stored
+			if current, ok := <-ReplyCommand(value, value.args("get")...); ok && !current.IsNil() {
+				computed = current.String()
+			}
+		}
+		out <- computed
+		errs <- nil
+	}()
+	return out, errs
+}
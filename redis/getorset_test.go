@@ -0,0 +1,86 @@
+package redis
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrSet(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	key := r.String("Test_GetOrSet")
+	<-key.Delete()
+	defer func() { <-key.Delete() }()
+
+	var computed int32
+	compute := func() (string, error) {
+		atomic.AddInt32(&computed, 1)
+		time.Sleep(50 * time.Millisecond)
+		return "A", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, errs := r.GetOrSet("Test_GetOrSet", time.Minute, compute)
+			if res := <-value; res != "A" {
+				t.Error("Should have gotten A, not", res)
+			}
+			if err := <-errs; err != nil {
+				t.Error("Should not have gotten an error, not", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if computed != 1 {
+		t.Error("The value should only have been computed once, not", computed, "times")
+	}
+	if res := <-key.MillisecondsToLive(); res <= 0 || res > 60000 {
+		t.Error("The value should expire within a minute, not", res)
+	}
+
+	failure := errors.New("failed")
+	value, errs := r.GetOrSet("Test_GetOrSetMissing", time.Minute, func() (string, error) {
+		return "", failure
+	})
+	if res, ok := <-value; ok {
+		t.Error("Should not get a value when compute fails, not", res)
+	}
+	if err := <-errs; err != failure {
+		t.Error("Should have gotten compute's error, not", err)
+	}
+	if <-r.Key("Test_GetOrSetMissing").Exists() {
+		t.Error("Nothing should be stored when compute fails")
+	}
+}
+
+func TestGetOrSetKeepsStoredValue(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	key := r.String("Test_GetOrSetStored")
+	<-key.Delete()
+	defer func() { <-key.Delete() }()
+
+	value, errs := r.GetOrSet("Test_GetOrSetStored", time.Minute, func() (string, error) {
+		//somebody else stores a value while this is still computing, the same as after the lock times out
+		<-key.Set("theirs")
+		return "mine", nil
+	})
+	if res := <-value; res != "theirs" {
+		t.Error("Should have given back the value that was actually stored, not", res)
+	}
+	if err := <-errs; err != nil {
+		t.Error("Should not have gotten an error, not", err)
+	}
+	if res := <-key.Get(); res != "theirs" {
+		t.Error("The stored value should not have been overwritten, but got", res)
+	}
+}
@@ -0,0 +1,173 @@
+package redis
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+//Structs are filled in from hashes by matching each exported field to the hash field of the same name,
+//or to the name in its `redis:"name"` tag if it has one (a tag of "-" leaves the field alone).
+//Strings, bools, and all sizes of ints, uints and floats can be filled in; any other fields are left alone.
+//Fields that aren't in the hash are left as they are
+
+//HGETALL command - 
+//GetStruct fills in the struct that dest points to from the fields of the hash;
+//the channel gets nil once it is filled in, or the error if it couldn't be.
+//If the hash doesn't exist, the struct is left alone
+func (this Hash) GetStruct(dest interface{}) <-chan error {
+	out := make(chan error, 1)
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		out <- errors.New("GetStruct needs a pointer to a struct")
+		close(out)
+		return out
+	}
+
+	in := this.Get()
+	go func() {
+		defer close(out)
+		fields, ok := <-in
+		if !ok {
+			out <- errors.New("Couldn't get the hash " + this.key)
+			return
+		}
+		out <- decodeHash(fields, v.Elem())
+	}()
+	return out
+}
+
+//HGETALL command (pipelined) - 
+//GetStructs fills in the slice that dest points to with one struct for each of the keys, in the same order, all in a single round trip.
+//dest must point to a slice of structs (or of pointers to structs); whatever it held before is replaced.
+//Keys that don't have a hash get a zero value struct, so the slice always lines up with the keys;
+//use GetExistingStructs to leave them out instead.
+//The channel gets nil once the slice is filled in, or the first error that happened
+func (this *Client) GetStructs(keys []string, dest interface{}) <-chan error {
+	return getStructs(this, keys, dest, false)
+}
+
+//HGETALL command (pipelined) - 
+//GetExistingStructs works the same way as GetStructs, except that keys without a hash are left out of the slice
+func (this *Client) GetExistingStructs(keys []string, dest interface{}) <-chan error {
+	return getStructs(this, keys, dest, true)
+}
+
+func getStructs(e SafeExecutor, keys []string, dest interface{}, skipMissing bool) <-chan error {
+	out := make(chan error, 1)
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		out <- errors.New("GetStructs needs a pointer to a slice of structs")
+		close(out)
+		return out
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	pointers := elemType.Kind() == reflect.Ptr
+	structType := elemType
+	if pointers {
+		structType = elemType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		out <- errors.New("GetStructs needs a pointer to a slice of structs")
+		close(out)
+		return out
+	}
+
+	hashes := make([]<-chan map[string]string, len(keys))
+	pipelined(e, func(p SafeExecutor) {
+		for i, key := range keys {
+			hashes[i] = newHash(p, key).Get()
+		}
+	})
+
+	go func() {
+		defer close(out)
+		result := reflect.MakeSlice(slice.Type(), 0, len(keys))
+		var firstErr error
+		for i, key := range keys {
+			fields, ok := <-hashes[i]
+			if !ok {
+				if firstErr == nil {
+					firstErr = errors.New("Couldn't get the hash " + key)
+				}
+				continue
+			}
+			if len(fields) == 0 && skipMissing {
+				continue
+			}
+
+			elem := reflect.New(structType)
+			if err := decodeHash(fields, elem.Elem()); err != nil && firstErr == nil {
+				firstErr = errors.New(key + ": " + err.Error())
+			}
+			if pointers {
+				result = reflect.Append(result, elem)
+			} else {
+				result = reflect.Append(result, elem.Elem())
+			}
+		}
+		slice.Set(result)
+		out <- firstErr
+	}()
+	return out
+}
+
+//decodeHash fills in the struct from the fields of a hash
+func decodeHash(fields map[string]string, dest reflect.Value) error {
+	t := dest.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			//unexported
+			continue
+		}
+		name := field.Name
+		if tag := field.Tag.Get("redis"); tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+
+		val, ok := fields[name]
+		if !ok {
+			continue
+		}
+		if err := setField(dest.Field(i), val); err != nil {
+			return errors.New("field " + name + ": " + err.Error())
+		}
+	}
+	return nil
+}
+
+func setField(f reflect.Value, val string) error {
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(val, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(val, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		fl, err := strconv.ParseFloat(val, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(fl)
+	}
+	return nil
+}
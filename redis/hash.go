@@ -12,7 +12,7 @@ type Hash struct {
 
 func newHash(client SafeExecutor, key string) Hash {
 	return Hash{
-		newKey(client, key),
+		newTypedKey(client, key, KeyTypeHash),
 	}
 }
 
@@ -47,12 +47,38 @@ func (this Hash) Size() <-chan int {
 	return IntCommand(this, this.args("hlen")...)
 }
 
+//HLEN command - 
+//IsEmpty returns whether or not there are no fields in this hash
+func (this Hash) IsEmpty() <-chan bool {
+	return isZeroChannel(this.Size())
+}
+
 //HGETALL command - 
 //Get returns a map that contains all of the values in the hash
 func (this Hash) Get() <-chan map[string]string {
 	return MapCommand(this, this.args("hgetall")...)
 }
 
+//HGETALL command - 
+//GetWithPresence returns all of the values in the hash, along with whether the hash exists at all.
+//Redis deletes a hash once its last field is removed, so an empty hash is always one that doesn't exist;
+//to cache an empty result, store a placeholder field instead.
+//If something goes wrong, both channels close without a value
+func (this Hash) GetWithPresence() (<-chan map[string]string, <-chan bool) {
+	return mapPresence(this.Get())
+}
+
+//HSET command - 
+//SetMany sets all of the fields in the hash, splitting them up into several HSETs if there are more than the MaxArgsPerCommand;
+//returns how many of the fields are new
+func (this Hash) SetMany(fields map[string]string) <-chan int {
+	args := make([]string, 0, 2*len(fields))
+	for field, value := range fields {
+		args = append(args, field, value)
+	}
+	return this.bulk("hset", 2, 0, args)
+}
+
 //HashField implements basic functions that apply to Hash Fields
 type HashField struct {
 	parent Hash
@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -224,3 +225,120 @@ func TestHashes(t *testing.T) {
 	}
 
 }
+
+func TestHashIsEmpty(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	h := r.Hash("Test_HashIsEmpty")
+	<-h.Delete()
+
+	if !<-h.IsEmpty() {
+		t.Error("A hash that doesn't exist should be empty")
+	}
+	<-h.String("A").Set("B")
+	if <-h.IsEmpty() {
+		t.Error("A hash with a field should not be empty")
+	}
+}
+
+type testProfile struct {
+	Name    string
+	Age     int     `redis:"age"`
+	Score   float64 `redis:"score"`
+	Admin   bool
+	Ignored string `redis:"-"`
+	private string
+}
+
+func TestDecodeHash(t *testing.T) {
+	var p testProfile
+	err := decodeHash(map[string]string{"Name": "Alice", "age": "30", "score": "1.5", "Admin": "true", "Ignored": "x", "private": "y"}, reflect.ValueOf(&p).Elem())
+	if err != nil {
+		t.Fatal("Should be able to decode the hash -", err)
+	}
+	if p != (testProfile{Name: "Alice", Age: 30, Score: 1.5, Admin: true}) {
+		t.Error("Decoded the hash incorrectly -", p)
+	}
+	if err := decodeHash(map[string]string{"age": "old"}, reflect.ValueOf(&p).Elem()); err == nil {
+		t.Error("Should not be able to decode a non-numeric age")
+	}
+}
+
+func TestGetStructs(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	keys := []string{"Test_GetStructs_1", "Test_GetStructs_Missing", "Test_GetStructs_2"}
+	for _, key := range keys {
+		<-r.Hash(key).Delete()
+	}
+	defer func() {
+		for _, key := range keys {
+			<-r.Hash(key).Delete()
+		}
+	}()
+	<-r.Hash(keys[0]).String("Name").Set("Alice")
+	<-r.Hash(keys[0]).Integer("age").Set(30)
+	<-r.Hash(keys[2]).String("Name").Set("Bob")
+
+	var profiles []testProfile
+	if err := <-r.GetStructs(keys, &profiles); err != nil {
+		t.Fatal("Should be able to get the structs -", err)
+	}
+	if len(profiles) != 3 || profiles[0] != (testProfile{Name: "Alice", Age: 30}) || profiles[1] != (testProfile{}) || profiles[2].Name != "Bob" {
+		t.Error("Should have gotten Alice, an empty profile, and Bob, not", profiles)
+	}
+
+	var existing []*testProfile
+	if err := <-r.GetExistingStructs(keys, &existing); err != nil {
+		t.Fatal("Should be able to get the existing structs -", err)
+	}
+	if len(existing) != 2 || existing[0].Name != "Alice" || existing[1].Name != "Bob" {
+		t.Error("Should have gotten only Alice and Bob, not", existing)
+	}
+
+	var single testProfile
+	if err := <-r.Hash(keys[0]).GetStruct(&single); err != nil || single.Name != "Alice" {
+		t.Error("Should have gotten Alice, not", single, err)
+	}
+	if err := <-r.GetStructs(keys, profiles); err == nil {
+		t.Error("Should not accept a slice that isn't a pointer")
+	}
+}
+
+func TestHashSetMany(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	h := r.Hash("Test_HashSetMany")
+	<-h.Delete()
+	defer func() { <-h.Delete() }()
+	<-h.String("A").Set("old")
+
+	if res := <-h.SetMany(map[string]string{"A": "1", "B": "2", "C": "3"}); res != 2 {
+		t.Error("Only B and C should be new, but got", res)
+	}
+	if res := <-h.Get(); len(res) != 3 || res["A"] != "1" {
+		t.Error("All of the fields should have been set, not", res)
+	}
+}
+
+func TestHashGetWithPresence(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	h := r.Hash("Test_HashGetWithPresence")
+	<-h.Delete()
+	defer func() { <-h.Delete() }()
+
+	fields, present := h.GetWithPresence()
+	if res := <-fields; len(res) != 0 || <-present {
+		t.Error("A missing hash should be empty and not present, not", res)
+	}
+	<-h.String("A").Set("1")
+	fields, present = h.GetWithPresence()
+	if res := <-fields; res["A"] != "1" || !<-present {
+		t.Error("Should have gotten A, not", res)
+	}
+}
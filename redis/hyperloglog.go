@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"time"
+)
+
+//HyperLogLog is an object which implements the Redis HyperLogLog primitive, which estimates how many unique items have been added to it
+//while only using a small, fixed amount of memory.
+//See http://redis.io/commands#hyperloglog for more info on HyperLogLogs
+type HyperLogLog struct {
+	Key
+}
+
+func newHyperLogLog(client SafeExecutor, key string) HyperLogLog {
+	return HyperLogLog{
+		newTypedKey(client, key, KeyTypeString),
+	}
+}
+
+//PFADD command - 
+//Add records the items in the HyperLogLog;
+//returns whether or not the estimated count changed as a result
+func (this HyperLogLog) Add(items ...string) <-chan bool {
+	return BoolCommand(this, this.args("pfadd", items...)...)
+}
+
+//PFCOUNT command - 
+//Count returns an estimate of how many unique items have been added (the standard error is 0.81%)
+func (this HyperLogLog) Count() <-chan int {
+	return IntCommand(this, this.args("pfcount")...)
+}
+
+//PFMERGE command - 
+//MergeFrom records every item that had been added to the other HyperLogLogs in this one as well.
+//All of them need to be on the same client; otherwise it fails with ErrCrossShard
+func (this HyperLogLog) MergeFrom(others ...HyperLogLog) <-chan nothing {
+	keys := make([]string, len(others))
+	otherKeys := make([]Key, len(others))
+	for i, other := range others {
+		keys[i] = other.key
+		otherKeys[i] = other.Key
+	}
+	return NilCommand(crossShardGuard(this.Key, otherKeys...), this.args("pfmerge", keys...)...)
+}
+
+const cachedCountScript = `
+local now = redis.call('TIME')
+local nowms = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+local cached = redis.call('HMGET', KEYS[2], 'count', 'at')
+if cached[1] and cached[2] and nowms - tonumber(cached[2]) < tonumber(ARGV[1]) then
+	return tonumber(cached[1])
+end
+local count = redis.call('PFCOUNT', KEYS[1])
+redis.call('HSET', KEYS[2], 'count', count, 'at', nowms)
+redis.call('PEXPIRE', KEYS[2], ARGV[1])
+return count
+`
+
+//PFCOUNT command (within a lua script) - 
+//CachedCount returns the same estimate as Count, but reuses the last estimate it made if that was within maxAge, rather than working it out again.
+//The estimate is cached in a hash next to the HyperLogLog (with ":cachedcount" added to the key), which expires once it's too old to be used.
+//The tradeoff is that items added since the cached estimate was made don't show up until it's worked out again,
+//so the count can be up to maxAge out of date; this suits something that is polled often and only needs to be roughly right, like a "unique visitors today" counter.
+//A maxAge of 0 (or less) never uses the cache
+func (this HyperLogLog) CachedCount(maxAge time.Duration) <-chan int {
+	ms := int(maxAge / time.Millisecond)
+	if ms <= 0 {
+		return this.Count()
+	}
+	return IntCommand(this, evalArgs(cachedCountScript, []string{this.key, this.key + ":cachedcount"}, itoa(ms))...)
+}
+
+//Use allows you to use this key on a different executor
+func (this HyperLogLog) Use(e SafeExecutor) HyperLogLog {
+	this.client = e
+	return this
+}
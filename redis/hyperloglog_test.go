@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	a := r.HyperLogLog("Test_HyperLogLog_A")
+	b := r.HyperLogLog("Test_HyperLogLog_B")
+	cleanup := func() {
+		<-a.Delete()
+		<-b.Delete()
+		<-r.Key("Test_HyperLogLog_A:cachedcount").Delete()
+	}
+	cleanup()
+	defer cleanup()
+
+	if !<-a.Add("x", "y", "z") {
+		t.Error("Adding new items should change the count")
+	}
+	if <-a.Add("x") {
+		t.Error("Adding an item again should not change the count")
+	}
+	if res := <-a.Count(); res != 3 {
+		t.Error("Should have about 3 items, not", res)
+	}
+
+	<-b.Add("z", "w")
+	<-a.MergeFrom(b)
+	if res := <-a.Count(); res != 4 {
+		t.Error("Should have about 4 items after merging, not", res)
+	}
+
+	if res := <-a.CachedCount(time.Second); res != 4 {
+		t.Error("The first cached count should be worked out, not", res)
+	}
+	<-a.Add("v")
+	if res := <-a.CachedCount(time.Second); res != 4 {
+		t.Error("The cached count should not have changed yet, not", res)
+	}
+	if res := <-a.CachedCount(0); res != 5 {
+		t.Error("A maxAge of 0 should always work the count out, not", res)
+	}
+	time.Sleep(time.Second)
+	if res := <-a.CachedCount(time.Second); res != 5 {
+		t.Error("A stale cached count should be worked out again, not", res)
+	}
+}
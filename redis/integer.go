@@ -8,7 +8,7 @@ type Integer struct {
 
 func newInteger(client SafeExecutor, key string) Integer {
 	return Integer{
-		newKey(client, key),
+		newTypedKey(client, key, KeyTypeString),
 	}
 }
 
@@ -10,7 +10,7 @@ type IntList struct {
 
 func newIntList(client SafeExecutor, key string) IntList {
 	return IntList{
-		newSortableKey(client, key),
+		newSortableKey(client, key, KeyTypeList),
 	}
 }
 
@@ -30,6 +30,12 @@ func (this IntList) Length() <-chan int {
 	return IntCommand(this, this.args("llen")...)
 }
 
+//LLEN command - 
+//IsEmpty returns whether or not there are no items in this list
+func (this IntList) IsEmpty() <-chan bool {
+	return isZeroChannel(this.Length())
+}
+
 //LPUSH command - 
 //LeftPush pushes an integer onto the left side of this list
 func (this IntList) LeftPush(items ...int) <-chan int {
@@ -162,7 +168,7 @@ func (this IntList) TrimToRange(left, right int) <-chan nothing {
 //MoveLastItemToList moves the last item on this list to the front of a new list.
 //If nothing is in this list, nothing happens
 func (this IntList) MoveLastItemToList(newList IntList) <-chan int {
-	return IntCommand(this, this.args("rpoplpush", newList.key)...)
+	return IntCommand(crossShardGuard(this.Key, newList.Key), this.args("rpoplpush", newList.key)...)
 }
 
 //BRPOPLPUSH command -
@@ -176,7 +182,7 @@ func (this IntList) BlockUntilMoveLastItemToList(newList IntList) <-chan int {
 //BlockUntilMoveLastItemToListWithTimeout moves the last item on this list to the front of a new list.
 //If nothing is in this list, will wait up to "timeout" seconds for something to be there before giving up
 func (this IntList) BlockUntilMoveLastItemToListWithTimeout(newList IntList, timeout int) <-chan int {
-	return IntCommand(this, this.args("brpoplpush", newList.key, itoa(timeout))...)
+	return IntCommand(crossShardGuard(this.Key, newList.Key), this.args("brpoplpush", newList.key, itoa(timeout))...)
 }
 
 //Use allows you to use this key on a different executor
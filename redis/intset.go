@@ -10,7 +10,7 @@ type IntSet struct {
 
 func newIntSet(client SafeExecutor, key string) IntSet {
 	return IntSet{
-		newSortableKey(client, key),
+		newSortableKey(client, key, KeyTypeSet),
 	}
 }
 
@@ -54,6 +54,12 @@ func (this IntSet) Size() <-chan int {
 	return IntCommand(this, this.args("scard")...)
 }
 
+//SCARD command -
+//IsEmpty returns whether or not there are no elements in this set
+func (this IntSet) IsEmpty() <-chan bool {
+	return isZeroChannel(this.Size())
+}
+
 //SRANDMEMBER command -
 //RandomMember returns a random integer from the set
 func (this IntSet) RandomMember() <-chan int {
@@ -69,71 +75,65 @@ func (this IntSet) Pop() <-chan int {
 //SINTER command -
 //Intersection returns a list of all integers that this and another set have in common
 func (this IntSet) Intersection(otherSets ...IntSet) <-chan []int {
-	args := this.args("sinter")
-	for _, set := range otherSets {
-		args = append(args, set.key)
-	}
-	return intsChannel(SliceCommand(this, args...))
+	e, args := this.combine("sinter", otherSets)
+	return intsChannel(SliceCommand(e, args...))
 }
 
 //SUNION command -
 //Union returns a list of all integers that are either in this set or another
 func (this IntSet) Union(otherSets ...IntSet) <-chan []int {
-	args := this.args("sunion")
-	for _, set := range otherSets {
-		args = append(args, set.key)
-	}
-	return intsChannel(SliceCommand(this, args...))
+	e, args := this.combine("sunion", otherSets)
+	return intsChannel(SliceCommand(e, args...))
 }
 
 //SDIFF command -
 //Difference returns a list of all integers that are in this set, but not another
 func (this IntSet) Difference(otherSets ...IntSet) <-chan []int {
-	args := this.args("sdiff")
-	for _, set := range otherSets {
-		args = append(args, set.key)
-	}
-	return intsChannel(SliceCommand(this, args...))
+	e, args := this.combine("sdiff", otherSets)
+	return intsChannel(SliceCommand(e, args...))
 }
 
 //SINTERSTORE command -
 //StoreIntersectionOf finds the intersection of multiple other sets and stores it in this one.
 //It returns the number of elements in the new set
 func (this IntSet) StoreIntersectionOf(sets ...IntSet) <-chan int {
-	args := this.args("sinterstore")
-	for _, set := range sets {
-		args = append(args, set.key)
-	}
-	return IntCommand(this, args...)
+	e, args := this.combine("sinterstore", sets)
+	return IntCommand(e, args...)
 }
 
 //SUNIONSTORE command -
 //StoreUnionOf finds the union of multiple other sets and stores it in this one.
 //It returns the number of elements in the new set
 func (this IntSet) StoreUnionOf(sets ...IntSet) <-chan int {
-	args := this.args("sunionstore")
-	for _, set := range sets {
-		args = append(args, set.key)
-	}
-	return IntCommand(this, args...)
+	e, args := this.combine("sunionstore", sets)
+	return IntCommand(e, args...)
 }
 
 //SDIFFSTORE command -
 //StoreDifferenceOf finds the difference between two other sets and stores it in this one.
 //It returns the number of elements in the new set
 func (this IntSet) StoreDifferenceOf(sets ...IntSet) <-chan int {
-	args := this.args("sdiffstore")
-	for _, set := range sets {
-		args = append(args, set.key)
-	}
-	return IntCommand(this, args...)
+	e, args := this.combine("sdiffstore", sets)
+	return IntCommand(e, args...)
 }
 
 //SMOVE command -
 //MoveMemberTo removes an integer from this set if it exists, and then adds it to another set.
 //Nothing happens if the integer was not a member of this set
 func (this IntSet) MoveMemberTo(newSet IntSet, item int) <-chan bool {
-	return BoolCommand(this, this.args("smove", newSet.key, itoa(item))...)
+	return BoolCommand(crossShardGuard(this.Key, newSet.Key), this.args("smove", newSet.key, itoa(item))...)
+}
+
+//combine gives the executor and arguments for a command that uses this set followed by the others;
+//if any of them are on a different client, the command fails with ErrCrossShard
+func (this IntSet) combine(command string, others []IntSet) (Executor, []string) {
+	args := this.args(command)
+	keys := make([]Key, len(others))
+	for i, set := range others {
+		args = append(args, set.key)
+		keys[i] = set.Key
+	}
+	return crossShardGuard(this.Key, keys...), args
 }
 
 //Use allows you to use this key on a different executor
@@ -0,0 +1,96 @@
+package redis
+
+//A KeyScanner goes through the keys of the database a batch at a time with SCAN, so that even a huge database never blocks redis the way KEYS does.
+//Like every SCAN, keys added or removed while scanning may or may not be seen, and a key can occasionally be seen more than once
+type KeyScanner struct {
+	client  SafeExecutor
+	match   string
+	count   int
+	kind    KeyType
+	keys    *scanner
+	batch   []string
+	current string
+}
+
+//Scan creates a KeyScanner over every key in the database; call Next to get to the first key.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *Client) Scan() *KeyScanner {
+	return &KeyScanner{client: this}
+}
+
+//Scan creates a KeyScanner over every key in the database, whose batches are tied to the context.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) Scan() *KeyScanner {
+	return &KeyScanner{client: this}
+}
+
+//Match limits the scan to keys that match the glob-style pattern.
+//It should be called before the first Next
+func (this *KeyScanner) Match(pattern string) *KeyScanner {
+	this.match = pattern
+	return this
+}
+
+//Count hints to redis how many keys to look at for each batch.
+//It should be called before the first Next
+func (this *KeyScanner) Count(hint int) *KeyScanner {
+	this.count = hint
+	return this
+}
+
+//Type limits the scan to keys of one type (this needs redis 6.0 or later).
+//It should be called before the first Next
+func (this *KeyScanner) Type(kind KeyType) *KeyScanner {
+	this.kind = kind
+	return this
+}
+
+//SCAN command - 
+//Next moves on to the next key, fetching more batches from redis when needed (redis can give back empty batches part way through);
+//returns false once the cursor has gone all the way around (or if something went wrong)
+func (this *KeyScanner) Next() <-chan bool {
+	out := make(chan bool, 1)
+	go func() {
+		defer close(out)
+		if this.keys == nil {
+			this.keys = newScanner(this.client, this.args)
+		}
+
+		for len(this.batch) == 0 {
+			batch, ok := this.keys.next()
+			if !ok {
+				out <- false
+				return
+			}
+			this.batch = batch
+		}
+
+		this.current, this.batch = this.batch[0], this.batch[1:]
+		out <- true
+	}()
+	return out
+}
+
+//Key returns the name of the key Next moved on to
+func (this *KeyScanner) Key() string {
+	return this.current
+}
+
+//Failed is whether the scan stopped before going through every key because something went wrong (the error went to the error callback)
+func (this *KeyScanner) Failed() bool {
+	return this.keys != nil && this.keys.failed
+}
+
+func (this *KeyScanner) args(cursor string) []string {
+	args := []string{"SCAN", cursor}
+	if this.match != "" {
+		args = append(args, "MATCH", this.match)
+	}
+	if this.count > 0 {
+		args = append(args, "COUNT", itoa(this.count))
+	}
+	if this.kind != "" {
+		args = append(args, "TYPE", string(this.kind))
+	}
+	return args
+}
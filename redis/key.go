@@ -10,8 +10,21 @@ import (
 type Key struct {
 	key    string
 	client SafeExecutor
+	kind   KeyType
 }
 
+//KeyType is one of the types that Redis reports for a key
+type KeyType string
+
+const (
+	KeyTypeNone      KeyType = "none"
+	KeyTypeString    KeyType = "string"
+	KeyTypeList      KeyType = "list"
+	KeyTypeSet       KeyType = "set"
+	KeyTypeSortedSet KeyType = "zset"
+	KeyTypeHash      KeyType = "hash"
+)
+
 func newKey(client SafeExecutor, key string) Key {
 	return Key{
 		key:    key,
@@ -19,6 +32,12 @@ func newKey(client SafeExecutor, key string) Key {
 	}
 }
 
+func newTypedKey(client SafeExecutor, key string, kind KeyType) Key {
+	k := newKey(client, key)
+	k.kind = kind
+	return k
+}
+
 func (this Key) args(command string, arguments ...string) []string {
 	return append([]string{strings.ToUpper(command), this.key}, arguments...)
 }
@@ -29,12 +48,32 @@ func (this Key) Exists() <-chan bool {
 	return BoolCommand(this, this.args("exists")...)
 }
 
-//DEL command - 
-//Delete removes a key from Redis
+//DEL or UNLINK command - 
+//Delete removes a key from Redis.
+//If the client was configured with LazyFree, this uses UNLINK so that large keys are freed in the background
 func (this Key) Delete() <-chan bool {
+	if this.client.configuration().LazyFree {
+		return this.Unlink()
+	}
 	return BoolCommand(this, this.args("del")...)
 }
 
+//deleteCommand is what whole keys are deleted with on the executor (including from within lua scripts):
+//UNLINK if the client was configured with LazyFree, otherwise DEL
+func deleteCommand(e SafeExecutor) string {
+	if e.configuration().LazyFree {
+		return "UNLINK"
+	}
+	return "DEL"
+}
+
+//UNLINK command - 
+//Unlink removes a key from Redis, but has Redis reclaim its memory in a separate thread so large keys don't block the server.
+//This is available regardless of whether or not the client was configured with LazyFree
+func (this Key) Unlink() <-chan bool {
+	return BoolCommand(this, this.args("unlink")...)
+}
+
 //TYPE command - 
 //Type returns the type of the underlying key,
 //specifically, it will be one of: none, string, list, set, zset and hash.
@@ -45,13 +84,13 @@ func (this Key) Type() <-chan string {
 //RENAME command - 
 //MoveTo transfers this key to a different one
 func (this Key) MoveTo(other Key) <-chan nothing {
-	return NilCommand(this, this.args("rename", other.key)...)
+	return NilCommand(crossShardGuard(this, other), this.args("rename", other.key)...)
 }
 
 //RENAMENX command - 
 //MoveToIfEmpty transfers this key to a different one, but only if the new one is empty
 func (this Key) MoveToIfEmpty(other Key) <-chan bool {
-	return BoolCommand(this, this.args("renamenx", other.key)...)
+	return BoolCommand(crossShardGuard(this, other), this.args("renamenx", other.key)...)
 }
 
 //PEXPIRE or EXPIRE command - 
@@ -85,8 +124,83 @@ func (this Key) MillisecondsToLive() <-chan int {
 	return IntCommand(this, this.args("pttl")...)
 }
 
+//PEXPIRETIME command - 
+//ExpireTime returns the exact time at which this key is set to expire.
+//If the key doesn't exist, or exists but isn't set to expire, it returns the zero time (check with IsZero).
+//This needs redis 7 or later
+func (this Key) ExpireTime() <-chan time.Time {
+	in := IntCommand(this, this.args("pexpiretime")...)
+	out := make(chan time.Time, 1)
+	go func() {
+		defer close(out)
+		if ms, ok := <-in; ok {
+			if ms < 0 {
+				//-1 means there is no expiry, and -2 means there is no key
+				out <- time.Time{}
+			} else {
+				out <- time.Unix(0, int64(ms)*int64(time.Millisecond))
+			}
+		}
+	}()
+	return out
+}
+
+//DebugObjectInfo is what DEBUG OBJECT says about how redis is storing a key.
+//Fields has every field redis reported, as it reported them; the most useful ones are also parsed out (left at 0 when redis didn't report them)
+type DebugObjectInfo struct {
+	Encoding         string //	ie, listpack, quicklist, skiplist or hashtable
+	RefCount         int
+	SerializedLength int //	how many bytes the value takes up when saved to disk
+	LRUSecondsIdle   int
+	QuicklistNodes   int //	ql_nodes, only reported for lists
+	Fields           map[string]string
+}
+
+func parseDebugObject(reply string) DebugObjectInfo {
+	info := DebugObjectInfo{Fields: make(map[string]string)}
+	for _, field := range strings.Fields(reply) {
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		info.Fields[parts[0]] = parts[1]
+		n, _ := atoi(parts[1])
+		switch parts[0] {
+		case "encoding":
+			info.Encoding = parts[1]
+		case "refcount":
+			info.RefCount = n
+		case "serializedlength":
+			info.SerializedLength = n
+		case "lru_seconds_idle":
+			info.LRUSecondsIdle = n
+		case "ql_nodes":
+			info.QuicklistNodes = n
+		}
+	}
+	return info
+}
+
+//DEBUG OBJECT command - 
+//DebugObject returns the details of how redis is storing this key; it is meant for tests and diagnostics, like checking that a zset is still a listpack.
+//Redis 7 and later refuse DEBUG unless enable-debug-command is turned on in their config
+func (this Key) DebugObject() <-chan DebugObjectInfo {
+	in := StringCommand(this, "DEBUG", "OBJECT", this.key)
+	out := make(chan DebugObjectInfo, 1)
+	go func() {
+		defer close(out)
+		if reply, ok := <-in; ok {
+			out <- parseDebugObject(reply)
+		}
+	}()
+	return out
+}
+
 //Execute allows the Key to be an Executor, which makes things quicker to code
 func (this Key) Execute(command command) {
+	if this.kind != "" {
+		command = keyedCommand{command, this.key, this.kind}
+	}
 	this.client.Execute(command)
 }
 
@@ -95,3 +209,41 @@ func (this Key) Use(e SafeExecutor) Key {
 	this.client = e
 	return this
 }
+
+//bulk issues a command with a lot of arguments (in groups of step, ie a score and a member), splitting it up into pipelined commands
+//so that none of them have more than the MaxArgsPerCommand, or more than limit groups when limit isn't 0;
+//returns the sum of all of their replies.
+//If the MaxArgsPerCommand can't fit the key and even a single group, nothing is sent, and it fails with errMaxArgsTooSmall
+func (this Key) bulk(command string, step, limit int, arguments []string) <-chan int {
+	size := len(arguments)
+	if max := this.client.configuration().MaxArgsPerCommand; max > 0 {
+		size = (max - 2) / step * step
+		if size < step {
+			return IntCommand(failingExecutor{this.client, errMaxArgsTooSmall}, this.args(command)...)
+		}
+	}
+	if limit > 0 && size > limit*step {
+		size = limit * step
+	}
+	if size < step {
+		//(there are no arguments at all)
+		size = step
+	}
+
+	batches := make([]<-chan int, 0, len(arguments)/size+1)
+	out := make(chan int, 1)
+	pipelinedInBackground(this.client, func(e SafeExecutor) {
+		key := this.Use(e)
+		for start := 0; start < len(arguments); start += size {
+			end := start + size
+			if end > len(arguments) {
+				end = len(arguments)
+			}
+			batches = append(batches, IntCommand(key, key.args(command, arguments[start:end]...)...))
+		}
+	}, func() {
+		defer close(out)
+		out <- <-sumChannel(batches)
+	})
+	return out
+}
@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -98,3 +99,190 @@ func TestKeys(t *testing.T) {
 		t.Error("Should have expired, instead has ", res)
 	}
 }
+
+func TestUnlink(t *testing.T) {
+	//with LazyFree, every whole key delete (including the ones within scripts) should be sent as an UNLINK
+	sent := make(chan []string, 1)
+	fake, done := FakeRedis(t, func(args []string) string {
+		sent <- args
+		return ":1\r\n"
+	})
+	fake.config.LazyFree = true
+	deletes := map[string]func(){
+		"Key.Delete":              func() { <-fake.Key("A").Delete() },
+		"DecimalSortedSet.Delete": func() { <-fake.DecimalSortedSet("A").Delete() },
+		"String.DeleteIfEquals":   func() { <-fake.String("A").DeleteIfEquals("B") },
+		"Client.GlobalTop":        func() { <-fake.GlobalTop(1, fake.SortedSet("A")) },
+	}
+	for name, del := range deletes {
+		del()
+		args := <-sent
+		if command := strings.ToUpper(args[0]); command != "UNLINK" && (command != "EVAL" || args[len(args)-1] != "UNLINK") {
+			t.Error(name, "should have used UNLINK, not", args)
+		}
+	}
+	done()
+
+	r := GetRedis(t)
+	defer r.Close()
+
+	str := r.String("Test_Unlink")
+	str.Delete()
+
+	<-str.Set("A")
+	if !<-str.Unlink() {
+		t.Error("Should be able to unlink an existing key")
+	}
+	if <-str.Exists() {
+		t.Error("Key should not exist after being unlinked")
+	}
+	if <-str.Unlink() {
+		t.Error("Should not be able to unlink a key that doesn't exist")
+	}
+
+	config := DefaultConfiguration()
+	config.LazyFree = true
+	config.ConnectionCount = 1
+	lazy, err := New(config)
+	if err != nil {
+		t.Fatal("Can't load redis - " + err.Error())
+	}
+	defer lazy.Close()
+
+	lazyStr := lazy.String("Test_Unlink")
+	<-lazyStr.Set("B")
+	if !<-lazyStr.Delete() {
+		t.Error("Should be able to delete an existing key with lazy freeing")
+	}
+	if <-lazyStr.Exists() {
+		t.Error("Key should not exist after being lazily deleted")
+	}
+}
+
+func TestWrongType(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+	failed := make(chan error, 1)
+	r.SetErrorCallback(func(e error, s string) {
+		failed <- e
+	})
+
+	str := r.String("Test_WrongType")
+	defer func() { <-str.Delete() }()
+	<-str.Set("A")
+
+	if _, ok := <-r.SortedSet("Test_WrongType").Size(); ok {
+		t.Error("Should not get a size back from a key of the wrong type")
+	}
+	select {
+	case e := <-failed:
+		wrong, ok := e.(ErrWrongType)
+		if !ok {
+			t.Fatal("Should have gotten an ErrWrongType, not ", e)
+		}
+		if wrong.Key != "Test_WrongType" {
+			t.Error("The error should name the key, not ", wrong.Key)
+		}
+		if wrong.Expected != KeyTypeSortedSet {
+			t.Error("The error should expect a zset, not ", wrong.Expected)
+		}
+	case <-time.After(time.Second):
+		t.Error("Using a key as the wrong type should cause an error")
+	}
+}
+
+func TestExpireTime(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	str := r.String("Test_ExpireTime")
+	<-str.Delete()
+	defer func() { <-str.Delete() }()
+
+	if res := <-str.ExpireTime(); !res.IsZero() {
+		t.Error("A missing key should not have an expire time, not", res)
+	}
+	<-str.Set("A")
+	if res := <-str.ExpireTime(); !res.IsZero() {
+		t.Error("A key without a TTL should not have an expire time, not", res)
+	}
+
+	expiry := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	<-str.ExpireIn(time.Until(expiry))
+	if res := <-str.ExpireTime(); res.Sub(expiry) > 50*time.Millisecond || expiry.Sub(res) > 50*time.Millisecond {
+		t.Error("Should expire at about", expiry, "not", res)
+	}
+}
+
+func TestKeyScannerArgs(t *testing.T) {
+	scan := (&Client{}).Scan().Match("user:*").Count(500).Type(KeyTypeSortedSet)
+	if res := strings.Join(scan.args("17"), " "); res != "SCAN 17 MATCH user:* COUNT 500 TYPE zset" {
+		t.Error("Got the wrong arguments -", res)
+	}
+	if res := strings.Join((&Client{}).Scan().args("0"), " "); res != "SCAN 0" {
+		t.Error("A plain scan shouldn't have any options -", res)
+	}
+}
+
+func TestKeyScanner(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	expected := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		key := "Test_KeyScanner:" + itoa(i)
+		expected[key] = true
+		<-r.String(key).Set("A")
+		defer r.Key(key).Delete()
+	}
+	zset := r.SortedSet("Test_KeyScanner:zset")
+	<-zset.Add("A", 1)
+	defer zset.Delete()
+
+	seen := map[string]bool{}
+	scan := r.Scan().Match("Test_KeyScanner:*").Count(10).Type(KeyTypeString)
+	for <-scan.Next() {
+		seen[scan.Key()] = true
+	}
+	if scan.Failed() {
+		t.Error("The scan shouldn't have failed")
+	}
+	if len(seen) != len(expected) {
+		t.Error("Should have seen all 100 strings and nothing else, but saw", len(seen))
+	}
+	for key := range seen {
+		if !expected[key] {
+			t.Error("Shouldn't have seen", key)
+		}
+	}
+}
+
+func TestParseDebugObject(t *testing.T) {
+	info := parseDebugObject("Value at:0x7f1b2c0a3e40 refcount:1 encoding:quicklist serializedlength:19 lru:12345 lru_seconds_idle:7 ql_nodes:2 ql_avg_node:1.50")
+	if info.Encoding != "quicklist" || info.RefCount != 1 || info.SerializedLength != 19 || info.LRUSecondsIdle != 7 || info.QuicklistNodes != 2 {
+		t.Error("Parsed the fields incorrectly -", info)
+	}
+	if info.Fields["ql_avg_node"] != "1.50" || info.Fields["at"] != "0x7f1b2c0a3e40" {
+		t.Error("Every field should be kept as it was reported -", info.Fields)
+	}
+}
+
+func TestDebugObject(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_DebugObject")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+	<-ss.Add("A", 1)
+
+	r.SetErrorCallback(func(e error, s string) {})
+	c := CollectErrors(r)
+	info, ok := <-ss.Use(c).DebugObject()
+	if !ok {
+		t.Skip("DEBUG isn't enabled on this server -", c.LastError())
+	}
+	if info.Encoding != "listpack" && info.Encoding != "ziplist" {
+		t.Error("A small zset should be stored compactly, not as", info.Encoding)
+	}
+}
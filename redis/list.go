@@ -1,12 +1,20 @@
 package redis
 
+import (
+	"time"
+)
+
+//A List is a redis list, pushed onto and popped from either end (LeftPush/RightPush, LeftPop/RightPop, and their BlockUntil versions).
+//Its other commands are named after what they do rather than after redis:
+//Length (LLEN), Index (LINDEX), Set (LSET), GetFromRange (LRANGE), TrimToRange (LTRIM),
+//Remove, RemoveNFromLeft and RemoveNFromRight (LREM), and InsertBefore and InsertAfter (LINSERT)
 type List struct {
 	SortableKey
 }
 
 func newList(client SafeExecutor, key string) List {
 	return List{
-		newSortableKey(client, key),
+		newSortableKey(client, key, KeyTypeList),
 	}
 }
 
@@ -26,6 +34,12 @@ func (this List) Length() <-chan int {
 	return IntCommand(this, this.args("llen")...)
 }
 
+//LLEN command -
+//IsEmpty returns whether or not there are no items in this list
+func (this List) IsEmpty() <-chan bool {
+	return isZeroChannel(this.Length())
+}
+
 //LPUSH command -
 //LeftPush pushes an item onto the left side of this list
 func (this List) LeftPush(items ...string) <-chan int {
@@ -92,6 +106,53 @@ func (this List) BlockUntilRightPopWithTimeout(timeout int) <-chan string {
 	return stringChannel(SliceCommand(this, this.args("brpop", itoa(timeout))...), 1)
 }
 
+//PoppedItem is an item that has been popped from a list, along with which list it came from
+type PoppedItem struct {
+	Key  string
+	Item string
+}
+
+//BLPOP command -
+//BlockingLeftPop pops an item from the left side of the first of the lists that has anything in it, waiting up to timeout for one of them to get something (0 waits forever).
+//Like the BlockUntil methods, the timeout is sent in whole seconds (redis before 6.0 doesn't accept anything else), so it is rounded up to the next second.
+//The popped item says which list it came from; if the timeout passes first, the channel closes without a value.
+//The command is issued on the first list's executor, so all the lists must be on the same client
+func BlockingLeftPop(timeout time.Duration, lists ...List) <-chan PoppedItem {
+	return blockingListPop("BLPOP", timeout, lists)
+}
+
+//BRPOP command -
+//BlockingRightPop works the same way as BlockingLeftPop, but pops from the right side of the lists instead
+func BlockingRightPop(timeout time.Duration, lists ...List) <-chan PoppedItem {
+	return blockingListPop("BRPOP", timeout, lists)
+}
+
+func blockingListPop(op string, timeout time.Duration, lists []List) <-chan PoppedItem {
+	out := make(chan PoppedItem, 1)
+	if len(lists) == 0 {
+		close(out)
+		return out
+	}
+
+	args := make([]string, 0, len(lists)+2)
+	args = append(args, op)
+	keys := make([]Key, len(lists))
+	for i, list := range lists {
+		args = append(args, list.key)
+		keys[i] = list.Key
+	}
+	args = append(args, wholeSeconds(timeout))
+
+	in := SliceCommand(crossShardGuard(keys[0], keys[1:]...), args...)
+	go func() {
+		defer close(out)
+		if res, ok := <-in; ok && len(res) == 2 {
+			out <- PoppedItem{res[0], res[1]}
+		}
+	}()
+	return out
+}
+
 //LINDEX command -
 //Index returns the item at the specified index:
 //negative numbers index from the right, with -1 being the rightmost index;
@@ -146,6 +207,38 @@ func (this List) GetFromRange(left, right int) <-chan []string {
 	return SliceCommand(this, this.args("lrange", itoa(left), itoa(right))...)
 }
 
+const rangeWithPresenceScript = `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+return redis.call('LRANGE', KEYS[1], ARGV[1], ARGV[2])
+`
+
+//EXISTS and LRANGE commands (within a lua script) - 
+//RangeWithPresence returns all items from between two indices the same way as GetFromRange,
+//along with whether the list exists at all; a range past the end of a list that does exist is empty, but still gives true.
+//If something goes wrong, both channels close without a value
+func (this List) RangeWithPresence(left, right int) (<-chan []string, <-chan bool) {
+	in := ReplyCommand(this, evalArgs(rangeWithPresenceScript, []string{this.key}, itoa(left), itoa(right))...)
+	items := make(chan []string, 1)
+	present := make(chan bool, 1)
+	go func() {
+		defer close(items)
+		defer close(present)
+		reply, ok := <-in
+		if !ok {
+			return
+		}
+		result := []string{}
+		for _, item := range reply.Array() {
+			result = append(result, item.String())
+		}
+		items <- result
+		present <- !reply.IsNil()
+	}()
+	return items, present
+}
+
 //LTRIM command -
 //TrimToRange removes all items not within the two indices:
 //negative indexes index from the right with -1 being the rightmost;
@@ -158,7 +251,7 @@ func (this List) TrimToRange(left, right int) <-chan nothing {
 //MoveLastItemToList moves the last item on this list to the front of a new list.
 //If nothing is in this list, nothing happens
 func (this List) MoveLastItemToList(newList List) <-chan string {
-	return StringCommand(this, this.args("rpoplpush", newList.key)...)
+	return StringCommand(crossShardGuard(this.Key, newList.Key), this.args("rpoplpush", newList.key)...)
 }
 
 //BRPOPLPUSH command -
@@ -172,7 +265,7 @@ func (this List) BlockUntilMoveLastItemToList(newList List) <-chan string {
 //BlockUntilMoveLastItemToListWithTimeout moves the last item on this list to the front of a new list.
 //If nothing is in this list, will wait up to "timeout" seconds for something to be there before giving up
 func (this List) BlockUntilMoveLastItemToListWithTimeout(newList List, timeout int) <-chan string {
-	return StringCommand(this, this.args("brpoplpush", newList.key, itoa(timeout))...)
+	return StringCommand(crossShardGuard(this.Key, newList.Key), this.args("brpoplpush", newList.key, itoa(timeout))...)
 }
 
 //Use allows you to use this key on a different executor
@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -222,3 +223,98 @@ func TestLists(t *testing.T) {
 	}
 	print(".\n")
 }
+
+func TestListIsEmpty(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	list := r.List("Test_ListIsEmpty")
+	<-list.Delete()
+
+	if !<-list.IsEmpty() {
+		t.Error("A list that doesn't exist should be empty")
+	}
+	<-list.RightPush("A")
+	if <-list.IsEmpty() {
+		t.Error("A list with an item should not be empty")
+	}
+}
+
+func TestListRangeWithPresence(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	l := r.List("Test_ListRangeWithPresence")
+	<-l.Delete()
+	defer func() { <-l.Delete() }()
+
+	items, present := l.RangeWithPresence(0, -1)
+	if res := <-items; len(res) != 0 || <-present {
+		t.Error("A missing list should be empty and not present, not", res)
+	}
+
+	<-l.RightPush("A")
+	<-l.RightPush("B")
+	items, present = l.RangeWithPresence(5, 10)
+	if res := <-items; len(res) != 0 || !<-present {
+		t.Error("A range past the end of a list should be empty, but the list is still present, not", res)
+	}
+	items, present = l.RangeWithPresence(0, -1)
+	if res := <-items; len(res) != 2 || res[0] != "A" || res[1] != "B" || !<-present {
+		t.Error("Should have gotten A and B, not", res)
+	}
+}
+
+func TestBlockingListPop(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	a := r.List("Test_BlockingListPop_A")
+	b := r.List("Test_BlockingListPop_B")
+	<-a.Delete()
+	<-b.Delete()
+	defer func() {
+		<-a.Delete()
+		<-b.Delete()
+	}()
+
+	if res, ok := <-BlockingLeftPop(100*time.Millisecond, a, b); ok {
+		t.Error("Nothing should be popped from empty lists, not", res)
+	}
+
+	popped := BlockingLeftPop(5*time.Second, a, b)
+	<-b.RightPush("B1", "B2", "B3")
+	if res := <-popped; res != (PoppedItem{"Test_BlockingListPop_B", "B1"}) {
+		t.Error("Should have popped B1 from Test_BlockingListPop_B, not", res)
+	}
+	<-a.RightPush("A1")
+	if res := <-BlockingRightPop(time.Second, a, b); res != (PoppedItem{"Test_BlockingListPop_A", "A1"}) {
+		t.Error("Should have popped A1 from the first list with anything in it, not", res)
+	}
+	if res := <-BlockingRightPop(time.Second, a, b); res != (PoppedItem{"Test_BlockingListPop_B", "B3"}) {
+		t.Error("Should have popped B3 from Test_BlockingListPop_B, not", res)
+	}
+}
+
+func TestBlockingListPopWholeSeconds(t *testing.T) {
+	sent := make(chan string, 3)
+	r, done := FakeRedis(t, func(args []string) string {
+		sent <- strings.Join(args, " ")
+		return "*-1\r\n"
+	})
+	defer done()
+
+	a, b := r.List("A"), r.List("B")
+	for timeout, expected := range map[time.Duration]string{
+		0:                       "BLPOP A B 0",
+		100 * time.Millisecond:  "BLPOP A B 1",
+		1500 * time.Millisecond: "BLPOP A B 2",
+	} {
+		if res, ok := <-BlockingLeftPop(timeout, a, b); ok {
+			t.Error("Nothing should be popped, not", res)
+		}
+		if command := <-sent; command != expected {
+			t.Error("A timeout of", timeout, "should have been sent as", expected, "not", command)
+		}
+	}
+}
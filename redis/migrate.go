@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"strings"
+)
+
+const (
+	//roughly how many keys get migrated at a time
+	migrateBatchSize = 100
+)
+
+//SCAN, DUMP, PTTL, and RESTORE commands - 
+//Migrate copies every key matching the pattern from one redis to another, keeping their TTLs.
+//Keys that already exist in the destination are skipped rather than overwritten (RESTORE refuses them with BUSYKEY, so there's no window for one to be written in between),
+//and keys that disappear while migrating are ignored.
+//After each batch of keys, progress (if it isn't nil) is called with how many keys have been migrated and skipped so far;
+//returns the total number of keys migrated
+func Migrate(src, dst SafeExecutor, pattern string, progress func(migrated, skipped int)) <-chan int {
+	out := make(chan int, 1)
+	go func() {
+		defer close(out)
+
+		keys := newScanner(src, func(cursor string) []string {
+			return []string{"SCAN", cursor, "MATCH", pattern, "COUNT", itoa(migrateBatchSize)}
+		})
+
+		migrated, skipped := 0, 0
+		for batch, ok := keys.next(); ok; batch, ok = keys.next() {
+			//send everything for the batch before waiting on any of it
+			dumps := make([]<-chan string, len(batch))
+			ttls := make([]<-chan int, len(batch))
+			for i, key := range batch {
+				dumps[i] = StringCommand(src, "DUMP", key)
+				ttls[i] = IntCommand(src, "PTTL", key)
+			}
+
+			restores := make([]<-chan error, 0, len(batch))
+			restored := make([]string, 0, len(batch))
+			for i, key := range batch {
+				dump, ok := <-dumps[i]
+				ttl, ttlOk := <-ttls[i]
+				if !ok || !ttlOk || ttl == -2 {
+					//the key went away since it was scanned
+					continue
+				}
+				if ttl < 0 {
+					//no expiration
+					ttl = 0
+				}
+				restores = append(restores, ErrorCommand(dst, "RESTORE", key, itoa(ttl), dump))
+				restored = append(restored, key)
+			}
+
+			for i, restore := range restores {
+				err, ok := <-restore
+				switch {
+				case !ok:
+					//the restore never went out
+				case err == nil:
+					migrated++
+				case strings.HasPrefix(err.Error(), "BUSYKEY"):
+					//it already exists in the destination
+					skipped++
+				default:
+					dst.errCallback(err, "RESTORE "+restored[i])
+				}
+			}
+
+			if progress != nil {
+				progress(migrated, skipped)
+			}
+		}
+		out <- migrated
+	}()
+	return out
+}
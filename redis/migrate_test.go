@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMigrate(t *testing.T) {
+	src := GetRedis(t)
+	defer src.Close()
+
+	config := DefaultConfiguration()
+	config.DBid = 1
+	config.ConnectionCount = 5
+	dst, err := New(config)
+	if err != nil {
+		t.Fatal("Can't load redis - " + err.Error())
+	}
+	defer dst.Close()
+	dst.SetErrorCallback(func(e error, s string) {
+		t.Error(e.Error() + " - " + s)
+	})
+
+	for i := 0; i < 250; i++ {
+		<-src.String("Test_Migrate:" + itoa(i)).Set(itoa(i))
+		<-dst.String("Test_Migrate:" + itoa(i)).Delete()
+	}
+	<-src.String("Test_Migrate:0").ExpireIn(time.Hour)
+	<-src.List("Test_Migrate:list").Delete()
+	<-src.List("Test_Migrate:list").RightPush("A", "B")
+	<-dst.List("Test_Migrate:list").Delete()
+	<-src.String("Test_NotMigrated").Set("A")
+	<-dst.String("Test_NotMigrated").Delete()
+
+	//this one already exists, so it should be left alone
+	<-dst.String("Test_Migrate:1").Set("existing")
+
+	lastMigrated, lastSkipped := 0, 0
+	calls := 0
+	res := <-Migrate(src, dst, "Test_Migrate:*", func(migrated, skipped int) {
+		calls++
+		lastMigrated, lastSkipped = migrated, skipped
+	})
+	if res != 250 {
+		t.Error("Should have migrated 250 keys, not", res)
+	}
+	if calls == 0 || lastMigrated != 250 || lastSkipped != 1 {
+		t.Error("Progress should finish at 250 migrated and 1 skipped, not", lastMigrated, lastSkipped)
+	}
+
+	if res := <-dst.String("Test_Migrate:2").Get(); res != "2" {
+		t.Error("Should have migrated Test_Migrate:2, but got", res)
+	}
+	if res := <-dst.String("Test_Migrate:1").Get(); res != "existing" {
+		t.Error("Should not have overwritten Test_Migrate:1, but got", res)
+	}
+	if res := <-dst.String("Test_Migrate:0").SecondsToLive(); res <= 0 {
+		t.Error("Should have kept the TTL of Test_Migrate:0, but got", res)
+	}
+	if res := <-dst.List("Test_Migrate:list").GetFromRange(0, -1); len(res) != 2 || res[0] != "A" || res[1] != "B" {
+		t.Error("Should have migrated the list, but got", res)
+	}
+	if <-dst.String("Test_NotMigrated").Exists() {
+		t.Error("Should not migrate keys that don't match the pattern")
+	}
+}
+
+func TestMigrateBusyKey(t *testing.T) {
+	src, srcDone := FakeRedis(t, func(args []string) string {
+		switch strings.ToUpper(args[0]) {
+		case "SCAN":
+			return "*2\r\n$1\r\n0\r\n*2\r\n$1\r\nA\r\n$1\r\nB\r\n"
+		case "DUMP":
+			return "$4\r\ndump\r\n"
+		case "PTTL":
+			return ":-1\r\n"
+		}
+		return "-ERR unexpected\r\n"
+	})
+	defer srcDone()
+
+	checked := make(chan string, 10)
+	dst, dstDone := FakeRedis(t, func(args []string) string {
+		if strings.ToUpper(args[0]) != "RESTORE" {
+			checked <- args[0]
+			return "-ERR unexpected\r\n"
+		}
+		//B was written to the destination after it was scanned
+		if args[1] == "B" {
+			return "-BUSYKEY Target key name already exists.\r\n"
+		}
+		return "+OK\r\n"
+	})
+	defer dstDone()
+
+	lastMigrated, lastSkipped := 0, 0
+	res := <-Migrate(src, dst, "*", func(migrated, skipped int) {
+		lastMigrated, lastSkipped = migrated, skipped
+	})
+	if res != 1 || lastMigrated != 1 || lastSkipped != 1 {
+		t.Error("Should have migrated A and skipped B, not", res, lastMigrated, lastSkipped)
+	}
+	select {
+	case command := <-checked:
+		t.Error("Only RESTORE should be sent to the destination, not", command)
+	default:
+	}
+}
@@ -0,0 +1,46 @@
+package redis
+
+import (
+	"strings"
+)
+
+//A Module issues the commands added by a redis module, which are all named after the module (ie, JSON.SET or FT.SEARCH).
+//Nothing is known about what the commands reply with, so every reply is given back as a Reply
+type Module struct {
+	client SafeExecutor
+	prefix string
+}
+
+//Module creates a Module for issuing the commands that start with the prefix given (ie, "JSON" for RedisJSON, or "FT" for RediSearch).
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *Client) Module(prefix string) Module {
+	return Module{this, prefix}
+}
+
+//Module creates a Module whose commands are tied to the context.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ContextClient) Module(prefix string) Module {
+	return Module{this, prefix}
+}
+
+//Do issues the module's command named by the subcommand, so Do("set", ...) on the JSON module issues JSON.SET
+func (this Module) Do(subcommand string, args ...string) <-chan Reply {
+	return ReplyCommand(this.client, append([]string{strings.ToUpper(this.prefix + "." + subcommand)}, args...)...)
+}
+
+//Use allows you to use this module on a different executor
+func (this Module) Use(e SafeExecutor) Module {
+	this.client = e
+	return this
+}
+
+//Do issues any command at all, and gives back the reply as it is.
+//This is an escape hatch for commands that don't have their own methods yet
+func (this *Client) Do(args ...string) <-chan Reply {
+	return ReplyCommand(this, args...)
+}
+
+//Do issues any command at all on the context, and gives back the reply as it is
+func (this *ContextClient) Do(args ...string) <-chan Reply {
+	return ReplyCommand(this, args...)
+}
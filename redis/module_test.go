@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	s := r.String("Test_Do")
+	<-s.Delete()
+	defer func() { <-s.Delete() }()
+
+	if reply, ok := <-r.Do("GET", "Test_Do"); !ok || !reply.IsNil() {
+		t.Error("Should have gotten a nil reply for a missing key, not", reply)
+	}
+	<-r.Do("RPUSH", "Test_Do", "A", "7")
+	reply := <-r.Do("LRANGE", "Test_Do", "0", "-1")
+	if !reply.IsArray() || len(reply.Array()) != 2 || reply.Array()[0].String() != "A" {
+		t.Error("Should have gotten an array of A and 7, not", reply)
+	}
+	if i, err := reply.Array()[1].Int(); err != nil || i != 7 {
+		t.Error("Should be able to read 7 as an int, not", i, err)
+	}
+
+	failed := make(chan bool, 1)
+	r.SetErrorCallback(func(e error, s string) {
+		failed <- true
+	})
+	if _, ok := <-r.Module("NOSUCHMODULE").Do("get", "Test_Do"); ok {
+		t.Error("A command that doesn't exist should not get a reply")
+	}
+	select {
+	case <-failed:
+	case <-time.After(time.Second):
+		t.Error("A command that doesn't exist should still report its error")
+	}
+}
+
+func TestModuleCommandName(t *testing.T) {
+	p := new(pipe)
+	Module{p, "json"}.Do("set", "key", "$", "{}")
+	if len(p.commands) != 1 {
+		t.Fatal("Should have issued one command, not", len(p.commands))
+	}
+	if args := p.commands[0].arguments(); len(args) != 4 || args[0] != "JSON.SET" || args[1] != "key" {
+		t.Error("Should have issued JSON.SET key $ {}, not", args)
+	}
+}
@@ -0,0 +1,199 @@
+package redis
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	//how long a BlockingPopManager's connections block at a time, before checking for new keys to pop from
+	popPollInterval = time.Second
+)
+
+//PoppedMember is a member that has been popped from a zset, along with its score and which zset it came from
+type PoppedMember struct {
+	Key    string
+	Member string
+	Score  float64
+}
+
+type popWaiter struct {
+	keys []string
+	out  chan PoppedMember
+}
+
+//A BlockingPopManager lets any number of goroutines wait to pop from zsets, while only tying up a fixed number of connections.
+//Each connection issues a BZPOPMIN across every zset that somebody is waiting on, and hands what it pops to whoever has been waiting the longest on that zset.
+//If the member popped no longer has anyone waiting for it (because they were handed something else in the meantime), it is added back to its zset
+type BlockingPopManager struct {
+	client   *Client
+	mutex    sync.Mutex
+	waiters  map[string][]*popWaiter //	everyone waiting on each key, in the order they started waiting
+	wake     chan nothing            //	gets closed (and replaced) whenever a new waiter arrives
+	stop     chan nothing
+	finished sync.WaitGroup
+}
+
+//BlockingPopManager creates a BlockingPopManager that uses "connections" dedicated connections to do all of its popping.
+//These connections are separate from the pool, so the manager should be closed when it isn't needed anymore
+func (this *Client) BlockingPopManager(connections int) *BlockingPopManager {
+	manager := &BlockingPopManager{
+		client:  this,
+		waiters: make(map[string][]*popWaiter),
+		wake:    make(chan nothing),
+		stop:    make(chan nothing),
+	}
+	for i := 0; i < connections; i++ {
+		manager.finished.Add(1)
+		go manager.work()
+	}
+	return manager
+}
+
+//BZPOPMIN command - 
+//PopMin waits until one of the zsets has a member, and then pops the member with the lowest score.
+//If the manager is closed before that happens, nothing is returned
+func (this *BlockingPopManager) PopMin(sets ...SortedSet) <-chan PoppedMember {
+	waiter := &popWaiter{
+		keys: make([]string, len(sets)),
+		out:  make(chan PoppedMember, 1),
+	}
+	for i, set := range sets {
+		waiter.keys[i] = set.key
+	}
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	select {
+	case <-this.stop:
+		close(waiter.out)
+		return waiter.out
+	default:
+	}
+	for _, key := range waiter.keys {
+		this.waiters[key] = append(this.waiters[key], waiter)
+	}
+	close(this.wake)
+	this.wake = make(chan nothing)
+
+	return waiter.out
+}
+
+//Close stops all of the manager's connections; anybody still waiting will not receive anything
+func (this *BlockingPopManager) Close() {
+	this.mutex.Lock()
+	select {
+	case <-this.stop:
+		this.mutex.Unlock()
+		return
+	default:
+	}
+	close(this.stop)
+	this.mutex.Unlock()
+
+	this.finished.Wait()
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	//waiters can be waiting on more than one key, so make sure each only gets closed once
+	closed := make(map[*popWaiter]bool)
+	for _, queue := range this.waiters {
+		for _, waiter := range queue {
+			if !closed[waiter] {
+				closed[waiter] = true
+				close(waiter.out)
+			}
+		}
+	}
+	this.waiters = make(map[string][]*popWaiter)
+}
+
+func (this *BlockingPopManager) work() {
+	defer this.finished.Done()
+
+	conn, err := this.client.newConnection()
+	if err != nil {
+		this.client.errCallback(err, "BlockingPopManager")
+		return
+	}
+	defer conn.Close()
+
+	for {
+		keys := this.waitForKeys()
+		if keys == nil {
+			return
+		}
+
+		args := append([]string{"BZPOPMIN"}, keys...)
+		args = append(args, ftoa(popPollInterval.Seconds()))
+		if res, ok := <-SliceCommand(conn, args...); ok && len(res) == 3 {
+			if score, err := atof(res[2]); err == nil {
+				this.deliver(PoppedMember{res[0], res[1], score})
+			}
+		}
+	}
+}
+
+//waitForKeys blocks until somebody is waiting on a key, and then returns all of the keys being waited on;
+//returns nil if the manager has been closed
+func (this *BlockingPopManager) waitForKeys() []string {
+	for {
+		this.mutex.Lock()
+		keys := make([]string, 0, len(this.waiters))
+		for key := range this.waiters {
+			keys = append(keys, key)
+		}
+		wake := this.wake
+		this.mutex.Unlock()
+
+		select {
+		case <-this.stop:
+			return nil
+		default:
+		}
+		if len(keys) > 0 {
+			return keys
+		}
+
+		select {
+		case <-wake:
+		case <-this.stop:
+			return nil
+		}
+	}
+}
+
+func (this *BlockingPopManager) deliver(popped PoppedMember) {
+	this.mutex.Lock()
+	queue := this.waiters[popped.Key]
+	if len(queue) == 0 {
+		this.mutex.Unlock()
+		//nobody wants this anymore, so put it back where we found it
+		this.client.SortedSet(popped.Key).Add(popped.Member, popped.Score)
+		return
+	}
+	waiter := queue[0]
+	this.remove(waiter)
+	this.mutex.Unlock()
+
+	waiter.out <- popped
+	close(waiter.out)
+}
+
+//remove stops a waiter from waiting on any of its keys (the mutex should already be locked)
+func (this *BlockingPopManager) remove(waiter *popWaiter) {
+	for _, key := range waiter.keys {
+		queue := this.waiters[key]
+		for i, w := range queue {
+			if w == waiter {
+				queue = append(queue[:i], queue[i+1:]...)
+				break
+			}
+		}
+		if len(queue) == 0 {
+			delete(this.waiters, key)
+		} else {
+			this.waiters[key] = queue
+		}
+	}
+}
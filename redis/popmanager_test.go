@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlockingPopManager(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	a := r.SortedSet("Test_PopManager_A")
+	b := r.SortedSet("Test_PopManager_B")
+	<-a.Delete()
+	<-b.Delete()
+
+	manager := r.BlockingPopManager(2)
+
+	//more waiters than connections
+	waiters := make([]<-chan PoppedMember, 6)
+	for i := range waiters {
+		waiters[i] = manager.PopMin(a, b)
+	}
+
+	<-a.Add("A1", 1)
+	<-a.Add("A2", 2)
+	<-b.Add("B1", 1)
+	<-b.Add("B2", 2)
+	<-b.Add("B3", 3)
+	<-b.Add("B4", 4)
+
+	received := make(map[string]PoppedMember)
+	timeout := time.After(5 * time.Second)
+	for _, waiter := range waiters {
+		select {
+		case popped, ok := <-waiter:
+			if !ok {
+				t.Error("Every waiter should receive something")
+			} else {
+				received[popped.Member] = popped
+			}
+		case <-timeout:
+			t.Fatal("Not every waiter received something")
+		}
+	}
+
+	if len(received) != 6 {
+		t.Error("Every waiter should have received a different member, not", received)
+	}
+	if res := received["A2"]; res.Key != "Test_PopManager_A" || res.Score != 2 {
+		t.Error("A2 should have come from Test_PopManager_A with a score of 2, not", res)
+	}
+	if res := received["B4"]; res.Key != "Test_PopManager_B" || res.Score != 4 {
+		t.Error("B4 should have come from Test_PopManager_B with a score of 4, not", res)
+	}
+
+	leftover := manager.PopMin(a)
+	manager.Close()
+	if res, ok := <-leftover; ok {
+		t.Error("Closing the manager should not give anything to a waiter, not", res)
+	}
+	if res, ok := <-manager.PopMin(a); ok {
+		t.Error("A closed manager should not give anything, not", res)
+	}
+}
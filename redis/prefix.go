@@ -45,10 +45,22 @@ type Prefix interface {
 	//This is a lightweight function - does *not* involve network I/O
 	SortedSet(key string) SortedSet
 
+	//CappedSortedSet creates the definition for a Redis ZSet primitive that never keeps more than maxSize members.
+	//This is a lightweight function - does *not* involve network I/O
+	CappedSortedSet(key string, maxSize int) CappedSortedSet
+
+	//DecimalSortedSet creates the definition for a Redis ZSet primitive that ranks its members by exact decimal scores.
+	//This is a lightweight function - does *not* involve network I/O
+	DecimalSortedSet(key string) DecimalSortedSet
+
 	//SortedIntSet creates the definition for a Redis ZSet primitive that contains only integers.
 	//This is a lightweight function - does *not* involve network I/O
 	SortedIntSet(key string) SortedIntSet
 
+	//HyperLogLog creates the definition for a Redis HyperLogLog primitive.
+	//This is a lightweight function - does *not* involve network I/O
+	HyperLogLog(key string) HyperLogLog
+
 	//Mutex creates a Mutex within redis.
 	//Warning - this is *not* a lightweight function - there is some network I/O involved in mutex initialization
 	Mutex(key string) Mutex
@@ -119,10 +131,22 @@ func (this *prefix) SortedSet(key string) SortedSet {
 	return this.parent.SortedSet(this.root + key)
 }
 
+func (this *prefix) CappedSortedSet(key string, maxSize int) CappedSortedSet {
+	return this.parent.CappedSortedSet(this.root+key, maxSize)
+}
+
+func (this *prefix) DecimalSortedSet(key string) DecimalSortedSet {
+	return this.parent.DecimalSortedSet(this.root + key)
+}
+
 func (this *prefix) SortedIntSet(key string) SortedIntSet {
 	return this.parent.SortedIntSet(this.root + key)
 }
 
+func (this *prefix) HyperLogLog(key string) HyperLogLog {
+	return this.parent.HyperLogLog(this.root + key)
+}
+
 func (this *prefix) Mutex(key string) Mutex {
 	return this.parent.Mutex(this.root + key)
 }
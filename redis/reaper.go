@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"time"
+)
+
+const (
+	pingTimeout = time.Second
+)
+
+//a reaper periodically checks the idle connections in the pool, and replaces any that have died
+type reaper struct {
+	stop chan nothing
+	done chan nothing
+}
+
+func (this *Client) startReaper(interval time.Duration) *reaper {
+	r := &reaper{
+		stop: make(chan nothing),
+		done: make(chan nothing),
+	}
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				this.reap()
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+	return r
+}
+
+//Close stops the reaper, and waits for it to finish anything it's in the middle of
+func (this *reaper) Close() {
+	if this == nil {
+		return
+	}
+	close(this.stop)
+	<-this.done
+}
+
+//reap goes through every connection currently sitting in the pool, and checks on the ones that have been idle for too long
+func (this *Client) reap() {
+	for i := 0; i < cap(this.pool); i++ {
+		var conn *Connection
+		select {
+		case conn = <-this.pool:
+		default:
+			//everything else is in use, which means it isn't idle
+			return
+		}
+		if conn, err := this.checkIdle(conn); err == nil {
+			this.idle(conn)
+		}
+	}
+}
+
+//checkIdle pings a connection that's been idle for too long, and gives back a new connection if the old one is dead.
+//If a new one can't be opened right away, it gives back why instead, and its replacement goes into the pool later (see replaceConnection)
+func (this *Client) checkIdle(conn *Connection) (*Connection, error) {
+	if time.Since(conn.lastUsed) < this.config.IdleTimeout {
+		return conn, nil
+	}
+
+	if err := conn.ping(); err == nil {
+		conn.lastUsed = time.Now()
+		return conn, nil
+	}
+
+	return this.replaceConnection(conn)
+}
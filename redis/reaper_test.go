@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestReaper(t *testing.T) {
+	config := DefaultConfiguration()
+	config.ConnectionCount = 1
+	config.IdleTimeout = time.Millisecond
+	config.ReapInterval = time.Hour //we'll reap by hand instead
+	r, err := New(config)
+	if err != nil {
+		t.Fatal("Can't load redis - " + err.Error())
+	}
+	defer r.Close()
+	r.SetErrorCallback(func(e error, s string) {
+		t.Error(e.Error() + " - " + s)
+	})
+
+	//swap out the real connection for one that has already died
+	(<-r.pool).Close()
+	alive, dead := net.Pipe()
+	dead.Close()
+	stub := &Connection{
+		Conn:     alive,
+		id:       -1,
+		client:   r,
+		lastUsed: time.Now().Add(-time.Hour),
+	}
+	r.pool <- stub
+
+	r.reap()
+
+	conn := <-r.pool
+	if conn == stub {
+		t.Error("The dead connection should have been reaped")
+	}
+	r.pool <- conn
+
+	s := r.String("Test_Reaper")
+	<-s.Set("A")
+	if res := <-s.Get(); res != "A" {
+		t.Error("The replacement connection should work, but got", res)
+	}
+
+	//a healthy connection that has been idle should be kept
+	time.Sleep(2 * time.Millisecond)
+	r.reap()
+	if res := <-r.pool; res != conn {
+		t.Error("A healthy idle connection should not be replaced")
+	} else {
+		r.pool <- res
+	}
+}
+
+func TestPingOnBorrow(t *testing.T) {
+	config := DefaultConfiguration()
+	config.ConnectionCount = 1
+	config.PingOnBorrow = true
+	r, err := New(config)
+	if err != nil {
+		t.Fatal("Can't load redis - " + err.Error())
+	}
+	defer r.Close()
+	r.SetErrorCallback(func(e error, s string) {
+		t.Error(e.Error() + " - " + s)
+	})
+
+	//swap out the real connection for one that has died since it was last used
+	(<-r.pool).Close()
+	alive, dead := net.Pipe()
+	dead.Close()
+	stub := &Connection{
+		Conn:     alive,
+		id:       -1,
+		client:   r,
+		lastUsed: time.Now(),
+		broken:   new(bool),
+	}
+	r.pool <- stub
+
+	s := r.String("Test_PingOnBorrow")
+	defer func() { <-s.Delete() }()
+	<-s.Set("A")
+	if res := <-s.Get(); res != "A" {
+		t.Error("The dead connection should have been replaced before it was used, but got", res)
+	}
+}
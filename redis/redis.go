@@ -1,31 +1,99 @@
 package redis
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"io"
+	"math"
 	"net"
+	"strings"
 	"time"
 )
 
 //The Config details how you plan to go about communicating with Redis
 type Config struct {
-	NetType         string `json:"nettype"`
-	NetAddress      string `json:"netaddr"`
-	DBid            int    `json:"dbid"`
-	Password        string `json:"password"`
-	ConnectionCount int    `json:"conncount"`
+	NetType              string        `json:"nettype"`
+	NetAddress           string        `json:"netaddr"`
+	DBid                 int           `json:"dbid"`
+	Password             string        `json:"password"`
+	Username             string        `json:"username"`
+	ConnectionCount      int           `json:"conncount"`
+	MaxOpen              int           `json:"maxopen"`
+	MaxIdle              int           `json:"maxidle"`
+	LazyFree             bool          `json:"lazyfree"`
+	MaxCommandsPerSecond int           `json:"maxcps"`
+	IdleTimeout          time.Duration `json:"idletimeout"`
+	ReapInterval         time.Duration `json:"reapinterval"`
+	WaitTimeout          time.Duration `json:"waittimeout"`
+	MaxArgsPerCommand    int           `json:"maxargs"`
+	PingOnBorrow         bool          `json:"pingonborrow"`
+	Retry                RetryPolicy   `json:"retry"`
+	TLSConfig            *tls.Config   `json:"-"`
+}
+
+//RetryPolicy is how hard the client tries to open a new connection in place of one that died.
+//It waits BaseDelay after the first failed attempt, doubling the wait after each one after that (but never waiting longer than MaxDelay, if there is one),
+//and gives up after MaxAttempts; the zero value makes a single attempt
+type RetryPolicy struct {
+	MaxAttempts int           `json:"maxattempts"`
+	BaseDelay   time.Duration `json:"basedelay"`
+	MaxDelay    time.Duration `json:"maxdelay"`
+}
+
+//delay is how long to wait after the given (1 based) attempt has failed
+func (this RetryPolicy) delay(attempt int) time.Duration {
+	delay := this.BaseDelay
+	//(without a MaxDelay, it stops doubling before it would overflow)
+	for i := 1; i < attempt && (this.MaxDelay <= 0 || delay < this.MaxDelay) && delay < math.MaxInt64/2; i++ {
+		delay *= 2
+	}
+	if this.MaxDelay > 0 && delay > this.MaxDelay {
+		delay = this.MaxDelay
+	}
+	return delay
 }
 
 //DefaultConfiguration returns a config with the easiest method for communicating with Redis.
+//A MaxCommandsPerSecond of 0 means that commands are never throttled; otherwise commands will wait to be sent rather than exceed that rate.
+//An IdleTimeout of 0 means that idle connections are never checked; otherwise every ReapInterval (or every IdleTimeout, if no ReapInterval is given),
+//connections that have been idle for longer than the IdleTimeout are pinged, and replaced if they have died.
+//A WaitTimeout of 0 means that commands wait as long as it takes for a connection to be free; otherwise they give up with ErrPoolTimeout once they have waited that long.
+//Every new connection (including ones made to replace a connection that died) authenticates with the Password, as the Username if there is one (an ACL user, which needs redis 6 or later),
+//and then selects the DBid; if either is refused, the connection is closed rather than used, and New gives back the error.
+//ConnectionCount is how many connections are opened up front. When every connection is in use, another is opened for the command that's waiting, as long as no more than MaxOpen are open at once,
+//and when a connection is given back while MaxIdle are already sitting unused in the pool, it is closed rather than kept.
+//A MaxOpen of 0 (or less than the ConnectionCount) means the ConnectionCount is the most that can be open; a MaxIdle of 0 means every connection is kept, and it is never less than the ConnectionCount or more than the MaxOpen.
+//With PingOnBorrow set, a connection that has been idle for longer than the IdleTimeout (or any connection at all, if there is no IdleTimeout) is pinged before a command gets to use it,
+//and replaced if it has died (ie, when the server has restarted since it was last used).
+//When a connection dies (ie, redis restarted), any command that was waiting on it fails, and a new connection is dialed in its place following the Retry policy;
+//only the first attempt is made right away, and any after that happen in the background (the pool is a connection short until they're done), so nothing waits through the backoff.
+//If every attempt fails, the dead connection is kept, and tried again after the next command fails on it.
+//With a TLSConfig, every connection (including ones made to replace a connection that died) is made over TLS, as most managed redis providers require;
+//set its RootCAs to verify the server's certificate against your provider's CA (rather than the system's), or InsecureSkipVerify to skip verifying it altogether (only ever for development).
+//If it has no ServerName, the host from the NetAddress is used. A TLSConfig can't be given in a Load file, so set it on the config and use New instead.
+//With LazyFree set, every whole key delete (Delete, and the deletes within scripts like DeleteIfEquals and GlobalTop) uses UNLINK, so that large keys are freed in the background.
+//A MaxArgsPerCommand of 0 means that bulk commands (like AddMany) are always sent whole; otherwise they are split into pipelined commands of at most that many arguments each.
+//If it is too small to fit the command, the key and a single member (along with its score or value, for ZADD and HSET), bulk commands fail instead of going over it.
 //All of the fields are public, so anything that needs to be changed for your setup can be done without affecting other fields
 func DefaultConfiguration() Config {
 	return Config{
-		NetType:         "tcp",
-		NetAddress:      "127.0.0.1:6379",
-		DBid:            0,
-		Password:        "",
-		ConnectionCount: 100,
+		NetType:              "tcp",
+		NetAddress:           "127.0.0.1:6379",
+		DBid:                 0,
+		Password:             "",
+		Username:             "",
+		ConnectionCount:      100,
+		MaxOpen:              0,
+		MaxIdle:              0,
+		LazyFree:             false,
+		MaxCommandsPerSecond: 0,
+		IdleTimeout:          0,
+		ReapInterval:         0,
+		WaitTimeout:          0,
+		MaxArgsPerCommand:    0,
+		PingOnBorrow:         false,
 	}
 }
 
@@ -54,8 +122,12 @@ type Client struct {
 	nextID       int
 	isClosed     bool
 	pool         chan *Connection // 	a semaphore of connections to draw from when multiple threads want to connect
+	open         chan nothing     //	holds a token for every open connection (idle or in use), so that no more than the MaxOpen are ever open
 	config       Config           //	connection details, so we know how to connect to redis
 	fErrCallback errCallbackFunc  //	a callback function - since we operate in a separate goroutine, we can't return an error, instead we call this function sending it the error, and the command we tried to issue
+	limiter      *throttle        //	limits how quickly commands get sent, if the config asks for it
+	reaper       *reaper          //	replaces dead idle connections, if the config asks for it
+	tracer       Tracer           //	starts a span for every command, if one has been given with WithTracer
 }
 
 //New gives back a Client that communicates using the details specified in the supplied Config
@@ -73,16 +145,30 @@ func New(config Config) (r *Client, e error) {
 	this := new(Client)
 	this.config = config
 
-	this.pool = make(chan *Connection, config.ConnectionCount)
+	if config.MaxCommandsPerSecond > 0 {
+		this.limiter = newThrottle(config.MaxCommandsPerSecond)
+	}
+
+	this.pool = make(chan *Connection, config.maxIdle())
+	this.open = make(chan nothing, config.maxOpen())
 	for i := 0; i < config.ConnectionCount; i++ {
 		conn, err := this.newConnection()
 		if err != nil {
 			return nil, err
 		}
 
+		this.open <- nothing{}
 		this.pool <- conn
 	}
 
+	if config.IdleTimeout > 0 {
+		interval := config.ReapInterval
+		if interval <= 0 {
+			interval = config.IdleTimeout
+		}
+		this.reaper = this.startReaper(interval)
+	}
+
 	return this, nil
 }
 
@@ -106,12 +192,17 @@ func (this *Client) Close() error {
 		return errors.New("Redis is already closed!")
 	}
 	this.isClosed = true
+	this.limiter.Close()
+	this.reaper.Close()
 
 	timeout := time.After(1 * time.Second)
-	for numClosed := 0; numClosed < this.config.ConnectionCount; numClosed++ {
+	for len(this.open) > 0 {
 		select {
 		case conn := <-this.pool:
 			conn.Close()
+			<-this.open
+		case <-time.After(10 * time.Millisecond):
+			//a connection given back to a full pool is closed rather than put into it, so check whether any are still open
 		case <-timeout:
 			this.errCallback(errors.New("Connections are still in use"), "Closing Redis")
 			return errors.New("Could not close all connections")
@@ -124,50 +215,262 @@ func (this *Client) Close() error {
 
 //Execute allows commands to be executed directly through the Client without needing to specify a key
 func (this Client) Execute(command command) {
-	go this.useConnection(func(conn *Connection) {
-		conn.Execute(command)
-	})
+	go func() {
+		command, end := this.trace(context.Background(), command)
+		defer end()
+		this.limiter.wait(1)
+		if err := this.useConnection(func(conn *Connection) {
+			conn.Execute(command)
+		}); err != nil {
+			fail(command, err)
+			this.commandError(err, command)
+		}
+	}()
 }
 
 func (this Client) errCallback(e error, s string) {
 	this.fErrCallback.Call(e, s)
 }
 
+func (this Client) configuration() Config {
+	return this.config
+}
+
+//maxOpen is the most connections that can be open at once
+func (this Config) maxOpen() int {
+	if this.MaxOpen < this.ConnectionCount {
+		return this.ConnectionCount
+	}
+	return this.MaxOpen
+}
+
+//maxIdle is the most connections that are kept in the pool while nobody is using them
+func (this Config) maxIdle() int {
+	switch {
+	case this.MaxIdle <= 0 || this.MaxIdle > this.maxOpen():
+		return this.maxOpen()
+	case this.MaxIdle < this.ConnectionCount:
+		return this.ConnectionCount
+	}
+	return this.MaxIdle
+}
+
 //Since redis operates in a separate thread, it isn't always possible to return an error status easily.
 //SetErrorCallback allows you to react to an error when it happens
 func (this *Client) SetErrorCallback(callback func(error, string)) {
 	this.fErrCallback = errCallbackFunc(callback)
 }
 
-func (this *Client) newConnection() (*Connection, error) {
+//dial opens the network connection that a Connection talks over, wrapped in TLS if the config has a TLSConfig
+func (this *Client) dial() (net.Conn, error) {
 	conn, err := net.Dial(this.config.NetType, this.config.NetAddress)
+	if err != nil || this.config.TLSConfig == nil {
+		return conn, err
+	}
+
+	config := this.config.TLSConfig
+	if config.ServerName == "" && !config.InsecureSkipVerify {
+		config = config.Clone()
+		config.ServerName = this.config.NetAddress
+		if host, _, err := net.SplitHostPort(this.config.NetAddress); err == nil {
+			config.ServerName = host
+		}
+	}
+	secure := tls.Client(conn, config)
+	if err := secure.Handshake(); err != nil {
+		conn.Close()
+		return nil, errors.New("Couldn't make a TLS connection to redis: " + err.Error())
+	}
+	return secure, nil
+}
+
+func (this *Client) newConnection() (*Connection, error) {
+	conn, err := this.dial()
 	if err != nil {
 		return nil, err
 	}
 
-	c := &Connection{conn, this.nextID, this}
+	c := &Connection{
+		Conn:     conn,
+		id:       this.nextID,
+		client:   this,
+		lastUsed: time.Now(),
+		db:       this.config.DBid,
+		broken:   new(bool),
+	}
 
 	if this.config.Password != "" {
-		<-NilCommand(c, "AUTH", this.config.Password)
+		auth := []string{"AUTH", this.config.Password}
+		if this.config.Username != "" {
+			auth = []string{"AUTH", this.config.Username, this.config.Password}
+		}
+		if err := <-ErrorCommand(c, auth...); err != nil {
+			conn.Close()
+			return nil, errors.New("Couldn't authenticate with redis: " + err.Error())
+		}
 	}
 	if this.config.DBid != 0 {
-		<-NilCommand(c, "SELECT", itoa(this.config.DBid))
+		if err := <-ErrorCommand(c, "SELECT", itoa(this.config.DBid)); err != nil {
+			conn.Close()
+			return nil, errors.New("Couldn't select database " + itoa(this.config.DBid) + ": " + err.Error())
+		}
 	}
 	this.nextID++
 	return c, nil
 }
 
-func (this *Client) useConnection(callback func(*Connection)) {
-	if this.isClosed {
-		return
+//replaceConnection closes a connection that can't be used anymore, and tries to open a new one to take its place.
+//If the first attempt works, the new connection is given back for the caller to use.
+//Otherwise it gives back the error, and the pool is taken care of instead (so the caller mustn't put anything back into it):
+//the rest of the attempts the Retry policy allows happen in the background, so that nobody is left waiting through the backoff (the pool is one connection short in the meantime),
+//and whatever comes of them goes into the pool - the new connection, or the old one if every attempt failed, so that the pool stays the same size
+func (this *Client) replaceConnection(conn *Connection) (*Connection, error) {
+	conn.Close()
+	replacement, err := this.newConnection()
+	if err == nil {
+		return replacement, nil
 	}
 
-	conn := <-this.pool
-	defer func() {
-		this.pool <- conn
+	finish := func(replacement *Connection, err error) {
+		if err != nil {
+			this.errCallback(err, "replacing a connection")
+			replacement = conn
+		}
+		this.idle(replacement)
+	}
+	if this.config.Retry.MaxAttempts <= 1 || this.isClosed {
+		finish(nil, err)
+		return nil, err
+	}
+	go func() {
+		time.Sleep(this.config.Retry.delay(1))
+		finish(this.redialFrom(2))
 	}()
+	return nil, err
+}
+
+//redial opens a new connection, trying again as many times as the Retry policy allows
+func (this *Client) redial() (*Connection, error) {
+	return this.redialFrom(1)
+}
+
+//redialFrom carries on redialing from the given (1 based) attempt
+func (this *Client) redialFrom(attempt int) (*Connection, error) {
+	for ; ; attempt++ {
+		conn, err := this.newConnection()
+		if err == nil || attempt >= this.config.Retry.MaxAttempts || this.isClosed {
+			return conn, err
+		}
+		time.Sleep(this.config.Retry.delay(attempt))
+	}
+}
+
+//commandError reports an error caused by a command to the error callback,
+//unless the command has already been given the error directly
+func (this *Client) commandError(e error, c command) {
+	if w, ok := c.(errorWrapper); ok {
+		e = w.wrapError(e)
+	}
+	if r, ok := c.(errorRecorder); ok {
+		r.recordError(e)
+	}
+	if _, ok := innerCommand(c).(errorHandler); ok {
+		return
+	}
+	this.errCallback(e, strings.Join(c.arguments(), " "))
+}
+
+//borrow takes a connection out of the pool once one is free (checking on it first, if the config asks for PingOnBorrow),
+//or opens a new one if none are free and fewer than the MaxOpen are open.
+//It gives up with ErrPoolTimeout if it has to wait longer than the WaitTimeout, or with errCanceled if done is closed first
+func (this *Client) borrow(done <-chan struct{}) (*Connection, error) {
+	var conn *Connection
+	select {
+	case <-done:
+		//(a free connection shouldn't win out over done, which select would otherwise pick between at random)
+		return nil, errCanceled
+	default:
+	}
+	select {
+	case conn = <-this.pool:
+	default:
+		var timeout <-chan time.Time
+		if this.config.WaitTimeout > 0 {
+			timer := time.NewTimer(this.config.WaitTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		select {
+		case conn = <-this.pool:
+		case this.open <- nothing{}:
+			conn, err := this.newConnection()
+			if err != nil {
+				<-this.open
+			}
+			return conn, err
+		case <-timeout:
+			return nil, ErrPoolTimeout
+		case <-done:
+			return nil, errCanceled
+		}
+	}
+
+	if this.config.PingOnBorrow {
+		return this.checkIdle(conn)
+	}
+	return conn, nil
+}
+
+func (this *Client) useConnection(callback func(*Connection)) error {
+	if this.isClosed {
+		return nil
+	}
+
+	conn, err := this.borrow(nil)
+	if err != nil {
+		return err
+	}
+	defer this.giveBack(conn)
 
 	callback(conn)
+	return nil
+}
+
+//giveBack puts a borrowed connection back into the pool (or a new one in its place, if it broke while it was out)
+func (this *Client) giveBack(conn *Connection) {
+	if conn.isBroken() {
+		var err error
+		if conn, err = this.replaceConnection(conn); err != nil {
+			return
+		}
+	}
+	conn.lastUsed = time.Now()
+	this.idle(conn)
+}
+
+//idle puts a connection that nobody is using into the pool, unless the pool already has MaxIdle connections in it, in which case it is closed instead
+func (this *Client) idle(conn *Connection) {
+	select {
+	case this.pool <- conn:
+	default:
+		conn.Close()
+		<-this.open
+	}
+}
+
+//ConnectionInfo returns the server address and database of the next connection in the pool, waiting for one to be free the same way a command would.
+//If the client is closed (or no connection frees up in time), it describes what the client was configured to connect to instead
+func (this *Client) ConnectionInfo() ConnInfo {
+	info := ConnInfo{
+		NetType:    this.config.NetType,
+		RemoteAddr: this.config.NetAddress,
+		DB:         this.config.DBid,
+	}
+	this.useConnection(func(c *Connection) {
+		info = c.ConnectionInfo()
+	})
+	return info
 }
 
 func (this *Client) useNewConnection(callback func(*Connection)) {
@@ -249,12 +552,30 @@ func (this *Client) SortedSet(key string) SortedSet {
 	return newSortedSet(this, key)
 }
 
+//Creates a CappedSortedSet Object, which never keeps more than maxSize members.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *Client) CappedSortedSet(key string, maxSize int) CappedSortedSet {
+	return newCappedSortedSet(this, key, maxSize)
+}
+
+//Creates a DecimalSortedSet Object, which ranks its members by exact decimal scores.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *Client) DecimalSortedSet(key string) DecimalSortedSet {
+	return newDecimalSortedSet(this, key)
+}
+
 //Creates a SortedIntSet Object.
 //(This is a lightweight function - does *not* involve network I/O)
 func (this *Client) SortedIntSet(key string) SortedIntSet {
 	return newSortedIntSet(this, key)
 }
 
+//Creates a HyperLogLog Object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *Client) HyperLogLog(key string) HyperLogLog {
+	return newHyperLogLog(this, key)
+}
+
 //Creates a Mutex Object.
 //(Warning - this is *not* a lightweight function - there is some network I/O involved in mutex initialization)
 func (this *Client) Mutex(key string) Mutex {
@@ -279,6 +600,64 @@ func (this *Client) Channel(key string) Channel {
 	return newChannel(this, key)
 }
 
+//TYPE command, followed by whichever command reads the entire key - 
+//Get looks up the type of the key, and returns all of its contents as the matching go type:
+//a string for strings, a []string for lists and sets, a map[string]string for hashes, and a []ScoredMember for zsets.
+//If the key doesn't exist, nothing is returned.
+//This is a convenience for generic tools that don't know the key's type ahead of time;
+//since it takes an extra round trip (and reads the whole key), it should not be used on hot paths
+func (this *Client) Get(key string) <-chan interface{} {
+	out := make(chan interface{}, 1)
+	go func() {
+		defer close(out)
+		var value interface{}
+		var ok bool
+		switch <-this.Key(key).Type() {
+		case "string":
+			value, ok = <-this.String(key).Get()
+		case "list":
+			value, ok = <-this.List(key).GetFromRange(0, -1)
+		case "set":
+			value, ok = <-this.Set(key).Members()
+		case "hash":
+			value, ok = <-this.Hash(key).Get()
+		case "zset":
+			zset := this.SortedSet(key)
+			value, ok = <-scoredMembersChannel(SliceCommand(zset, zset.args("zrange", "0", "-1", "WITHSCORES")...))
+		}
+		if ok {
+			out <- value
+		}
+	}()
+	return out
+}
+
+//MGET command - 
+//GetStrings returns the values of all of the keys given, keyed by key; keys that don't exist (or aren't strings) are left out
+func (this *Client) GetStrings(keys ...string) <-chan map[string]string {
+	out := make(chan map[string]string, 1)
+	if len(keys) == 0 {
+		out <- map[string]string{}
+		close(out)
+		return out
+	}
+
+	in := MaybeSliceCommand(this, append([]string{"MGET"}, keys...)...)
+	go func() {
+		defer close(out)
+		if values, ok := <-in; ok {
+			result := make(map[string]string, len(values))
+			for i, value := range values {
+				if value != nil && i < len(keys) {
+					result[keys[i]] = *value
+				}
+			}
+			out <- result
+		}
+	}()
+	return out
+}
+
 //Creates a Prefix Object, which helps namespace other Redis Objects.
 //(This is a lightweight function - does *not* involve network I/O)
 func (this *Client) Prefix(key string) Prefix {
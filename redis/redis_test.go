@@ -1,8 +1,20 @@
 package redis
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 // GetRedis is meant to provide a common way for every test function to log into redis the same way
@@ -19,6 +31,93 @@ func GetRedis(t *testing.T) *Client {
 	return r
 }
 
+//FakeRedis stands in for redis, answering every command it reads with whatever reply gives back for it (a raw RESP reply, like "+OK\r\n");
+//it gives back a client connected to it with a single connection, which the error callback doesn't fail the test for
+func FakeRedis(t *testing.T, reply func(args []string) string) (*Client, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Can't listen -", err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					args, err := readFakeCommand(reader)
+					if err != nil {
+						return
+					}
+					conn.Write([]byte(reply(args)))
+				}
+			}()
+		}
+	}()
+
+	config := DefaultConfiguration()
+	config.NetAddress = listener.Addr().String()
+	config.ConnectionCount = 1
+	r, err := New(config)
+	if err != nil {
+		listener.Close()
+		t.Fatal("Can't connect -", err)
+	}
+	r.SetErrorCallback(func(error, string) {})
+	return r, func() {
+		r.Close()
+		listener.Close()
+	}
+}
+
+//returnsRightAway fails the test if issue holds up its caller rather than handing back its channel straight away (ie, while redis hasn't replied yet);
+//returns whether it did return in time
+func returnsRightAway(t *testing.T, name string, issue func()) bool {
+	returned := make(chan struct{})
+	go func() {
+		issue()
+		close(returned)
+	}()
+	select {
+	case <-returned:
+		return true
+	case <-time.After(time.Second):
+		t.Error(name, "should give back its channel without waiting for redis to reply")
+		return false
+	}
+}
+
+//readFakeCommand reads a single command (an array of bulk strings) off of a connection to a FakeRedis
+func readFakeCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
 func TestBadCommands(t *testing.T) {
 	failed := make(chan bool)
 	r := GetRedis(t)
@@ -63,3 +162,373 @@ func TestWrongPassword(t *testing.T) {
 		t.Fatal("Should not work with wrong password")
 	}
 }
+
+func TestGenericGet(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	s := r.String("Test_GenericGet_String")
+	l := r.List("Test_GenericGet_List")
+	set := r.Set("Test_GenericGet_Set")
+	h := r.Hash("Test_GenericGet_Hash")
+	ss := r.SortedSet("Test_GenericGet_SortedSet")
+	<-s.Delete()
+	<-l.Delete()
+	<-set.Delete()
+	<-h.Delete()
+	<-ss.Delete()
+
+	if res, ok := <-r.Get("Test_GenericGet_String"); ok {
+		t.Error("Should not get anything for a missing key, not", res)
+	}
+
+	<-s.Set("A")
+	<-l.RightPush("A", "B")
+	<-set.Add("A")
+	<-h.String("A").Set("B")
+	<-ss.Add("A", 1)
+
+	if res, ok := (<-r.Get("Test_GenericGet_String")).(string); !ok || res != "A" {
+		t.Error("Should get the string A, not", res)
+	}
+	if res, ok := (<-r.Get("Test_GenericGet_List")).([]string); !ok || len(res) != 2 || res[0] != "A" || res[1] != "B" {
+		t.Error("Should get the list [A B], not", res)
+	}
+	if res, ok := (<-r.Get("Test_GenericGet_Set")).([]string); !ok || len(res) != 1 || res[0] != "A" {
+		t.Error("Should get the set [A], not", res)
+	}
+	if res, ok := (<-r.Get("Test_GenericGet_Hash")).(map[string]string); !ok || len(res) != 1 || res["A"] != "B" {
+		t.Error("Should get the hash map[A:B], not", res)
+	}
+	if res, ok := (<-r.Get("Test_GenericGet_SortedSet")).([]ScoredMember); !ok || len(res) != 1 || res[0] != (ScoredMember{"A", 1}) {
+		t.Error("Should get the zset [{A 1}], not", res)
+	}
+}
+
+func TestWaitTimeout(t *testing.T) {
+	config := DefaultConfiguration()
+	config.ConnectionCount = 1
+	config.WaitTimeout = 50 * time.Millisecond
+	r, err := New(config)
+	if err != nil {
+		t.Fatal("Can't load redis - " + err.Error())
+	}
+	defer r.Close()
+	failures := make(chan error, 1)
+	r.SetErrorCallback(func(e error, s string) {
+		failures <- e
+	})
+
+	//tie up the only connection for a second
+	blocked := NilCommand(r, "BLPOP", "Test_WaitTimeout", "1")
+	time.Sleep(10 * time.Millisecond)
+
+	if err := <-ErrorCommand(r, "PING"); err != ErrPoolTimeout {
+		t.Error("Should have timed out waiting for a connection, not", err)
+	}
+	if _, ok := <-NilCommand(r, "PING"); ok {
+		t.Error("Should not get anything back from a command that never got a connection")
+	}
+	select {
+	case e := <-failures:
+		if e != ErrPoolTimeout {
+			t.Error("Should have reported a pool timeout, not", e)
+		}
+	case <-time.After(time.Second):
+		t.Error("The pool timeout should have been reported")
+	}
+
+	<-blocked
+	if err := <-ErrorCommand(r, "PING"); err != nil {
+		t.Error("Should be able to use the connection once it is free -", err)
+	}
+}
+
+func TestConnectionInfo(t *testing.T) {
+	config := DefaultConfiguration()
+	config.DBid = 1
+	r, err := New(config)
+	if err != nil {
+		t.Fatal("Can't load redis - " + err.Error())
+	}
+
+	if res := r.ConnectionInfo(); res.NetType != "tcp" || res.RemoteAddr != config.NetAddress || res.DB != 1 {
+		t.Error("Should be connected to database 1 at", config.NetAddress, "not", res)
+	}
+
+	r.Close()
+	if res := r.ConnectionInfo(); res != (ConnInfo{config.NetType, config.NetAddress, 1}) {
+		t.Error("A closed client should describe its configuration, not", res)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	expected := []time.Duration{10, 20, 40, 50, 50}
+	for i, delay := range expected {
+		if res := policy.delay(i + 1); res != delay*time.Millisecond {
+			t.Error("After attempt", i+1, "should wait", delay*time.Millisecond, "not", res)
+		}
+	}
+	if res := (RetryPolicy{BaseDelay: time.Second}).delay(10); res != 512*time.Second {
+		t.Error("Without a MaxDelay the wait should keep doubling, not", res)
+	}
+	if res := (RetryPolicy{BaseDelay: time.Second}).delay(100); res <= 0 {
+		t.Error("The wait should never overflow, but got", res)
+	}
+}
+
+func TestMaxOpenAndMaxIdle(t *testing.T) {
+	held := make(chan bool, 2)
+	release := make(chan struct{})
+	fake, done := FakeRedis(t, func(args []string) string {
+		if args[0] == "HOLD" {
+			held <- true
+			<-release
+		}
+		return "+OK\r\n"
+	})
+	defer done()
+
+	config := fake.configuration()
+	config.ConnectionCount = 1
+	config.MaxOpen = 2
+	config.MaxIdle = 1
+	config.WaitTimeout = 50 * time.Millisecond
+	r, err := New(config)
+	if err != nil {
+		t.Fatal("Can't connect -", err)
+	}
+	defer r.Close()
+	r.SetErrorCallback(func(error, string) {})
+
+	//the second command gets a connection of its own, since there's room for one more
+	first, second := NilCommand(r, "HOLD"), NilCommand(r, "HOLD")
+	for i := 0; i < 2; i++ {
+		select {
+		case <-held:
+		case <-time.After(time.Second):
+			close(release)
+			t.Fatal("Both commands should have gotten a connection")
+		}
+	}
+	if err := <-ErrorCommand(r, "PING"); err != ErrPoolTimeout {
+		t.Error("No more than 2 connections should be opened, but the third command got", err)
+	}
+
+	close(release)
+	<-first
+	<-second
+	if len(r.pool) != 1 || len(r.open) != 1 {
+		t.Error("Only 1 connection should have been kept, not", len(r.pool), "idle and", len(r.open), "open")
+	}
+	if err := <-ErrorCommand(r, "PING"); err != nil {
+		t.Error("Should be able to use the connection that was kept -", err)
+	}
+}
+
+func TestRedial(t *testing.T) {
+	//find a port that nothing is listening on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Can't find a free port -", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	r := &Client{config: Config{
+		NetType:    "tcp",
+		NetAddress: address,
+		Retry:      RetryPolicy{MaxAttempts: 3, BaseDelay: 5 * time.Millisecond},
+	}}
+	start := time.Now()
+	if _, err := r.redial(); err == nil {
+		t.Fatal("Nothing is listening, so every attempt should fail")
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Error("Should have waited 5ms and then 10ms between the 3 attempts, but only took", elapsed)
+	}
+}
+
+func TestReplaceConnectionInBackground(t *testing.T) {
+	//find a port that nothing is listening on yet
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Can't find a free port -", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	r := &Client{
+		config: Config{
+			NetType:    "tcp",
+			NetAddress: address,
+			Retry:      RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond},
+		},
+		pool:         make(chan *Connection, 1),
+		fErrCallback: func(error, string) {},
+	}
+	dead, other := net.Pipe()
+	defer other.Close()
+	broken := true
+	start := time.Now()
+	r.giveBack(&Connection{Conn: dead, client: r, broken: &broken})
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Error("Giving back a dead connection shouldn't wait through the backoff, but took", elapsed)
+	}
+	select {
+	case <-r.pool:
+		t.Fatal("Nothing is listening yet, so there shouldn't be anything in the pool")
+	default:
+	}
+
+	//once redis is back, the retries in the background should fill the pool again
+	listener, err = net.Listen("tcp", address)
+	if err != nil {
+		t.Skip("Can't listen on the same port again -", err)
+	}
+	defer listener.Close()
+	select {
+	case conn := <-r.pool:
+		if conn.isBroken() || conn.Conn == dead {
+			t.Error("The pool should have gotten a new connection, not the dead one")
+		}
+		conn.Close()
+	case <-time.After(2 * time.Second):
+		t.Error("The replacement never made it into the pool")
+	}
+}
+
+func TestWrongACLPassword(t *testing.T) {
+	//a stand in for redis that refuses every password
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Can't listen -", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buffer := make([]byte, 256)
+				conn.Read(buffer)
+				if !bytes.Contains(buffer, []byte("AUTH")) || !bytes.Contains(buffer, []byte("admin")) {
+					conn.Write([]byte("-ERR expected AUTH with a username\r\n"))
+					return
+				}
+				conn.Write([]byte("-WRONGPASS invalid username-password pair or user is disabled.\r\n"))
+			}()
+		}
+	}()
+
+	config := DefaultConfiguration()
+	config.NetAddress = listener.Addr().String()
+	config.ConnectionCount = 1
+	config.Username = "admin"
+	config.Password = "wrong"
+	r, err := New(config)
+	if err == nil {
+		r.Close()
+		t.Fatal("A wrong password should stop the client from being made")
+	}
+	if !bytes.Contains([]byte(err.Error()), []byte("WRONGPASS")) {
+		t.Error("The error should say that the password was wrong, not", err)
+	}
+}
+
+//selfSignedCertificate makes a certificate for 127.0.0.1, along with a pool that trusts it
+func selfSignedCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal("Can't make a key -", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal("Can't make a certificate -", err)
+	}
+	parsed, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal("Can't read the certificate back -", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(parsed)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+func TestTLS(t *testing.T) {
+	certificate, pool := selfSignedCertificate(t)
+
+	//a stand in for redis that only speaks TLS, and answers everything with OK
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{certificate}})
+	if err != nil {
+		t.Fatal("Can't listen -", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buffer := make([]byte, 256)
+				for {
+					if _, err := conn.Read(buffer); err != nil {
+						return
+					}
+					conn.Write([]byte("+OK\r\n"))
+				}
+			}()
+		}
+	}()
+
+	config := DefaultConfiguration()
+	config.NetAddress = listener.Addr().String()
+	config.ConnectionCount = 1
+	config.Password = "password"
+
+	config.TLSConfig = &tls.Config{RootCAs: pool}
+	r, err := New(config)
+	if err != nil {
+		t.Fatal("Should be able to connect when the certificate is trusted -", err)
+	}
+	if err := <-ErrorCommand(r, "PING"); err != nil {
+		t.Error("Commands should go over the TLS connection -", err)
+	}
+	if conn, err := r.redial(); err != nil {
+		t.Error("Redialing should use TLS as well -", err)
+	} else if _, ok := conn.Conn.(*tls.Conn); !ok {
+		t.Error("A redialed connection should be a TLS connection")
+	} else {
+		conn.Close()
+	}
+	r.Close()
+
+	config.TLSConfig = &tls.Config{}
+	if r, err := New(config); err == nil {
+		r.Close()
+		t.Error("An untrusted certificate should stop the client from being made")
+	}
+
+	config.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+	r, err = New(config)
+	if err != nil {
+		t.Fatal("Should be able to connect without verifying -", err)
+	}
+	r.Close()
+}
@@ -0,0 +1,47 @@
+package redis
+
+//a scanner walks through one of redis's cursor based commands (SCAN, SSCAN, HSCAN, ZSCAN), one batch at a time
+type scanner struct {
+	e        Executor
+	args     func(cursor string) []string
+	cursor   string
+	finished bool
+	failed   bool //whether it finished because something went wrong, rather than reaching the end
+}
+
+func newScanner(e Executor, args func(cursor string) []string) *scanner {
+	return &scanner{
+		e:      e,
+		args:   args,
+		cursor: "0",
+	}
+}
+
+//next gets the next batch of results;
+//returns false once every batch has been gotten (or if something went wrong).
+//A batch can be empty even when there are more batches to come
+func (this *scanner) next() ([]string, bool) {
+	if this.finished {
+		return nil, false
+	}
+
+	r, ok := <-rawResponse(this.e, this.args(this.cursor)...)
+	if !ok || len(r.subresponses) != 2 || r.subresponses[0] == nil || r.subresponses[1] == nil {
+		this.finished = true
+		this.failed = true
+		return nil, false
+	}
+
+	this.cursor = r.subresponses[0].val
+	if this.cursor == "0" {
+		this.finished = true
+	}
+
+	batch := make([]string, 0, len(r.subresponses[1].subresponses))
+	for _, item := range r.subresponses[1].subresponses {
+		if item != nil {
+			batch = append(batch, item.val)
+		}
+	}
+	return batch, true
+}
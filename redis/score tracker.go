@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"strings"
+	"time"
+)
+
+//A ScoreTracker is a zset that keeps a history of every score its members reach through IncrementBy,
+//so that a member's progress can be charted over time.
+//Each member's history is its own zset (at the history prefix followed by the member), scored by the time each score was reached.
+//Only IncrementBy records history; scores changed through the other SortedSet methods aren't recorded
+type ScoreTracker struct {
+	SortedSet
+	history    string
+	maxHistory int
+}
+
+//WithScoreHistory creates a ScoreTracker which records the history of each member in a zset at historyPrefix + member.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this SortedSet) WithScoreHistory(historyPrefix string) ScoreTracker {
+	return ScoreTracker{
+		SortedSet: this,
+		history:   historyPrefix,
+	}
+}
+
+//TrimHistory creates a copy of this ScoreTracker which only keeps the most recent max entries of each member's history;
+//a max of 0 (the default) keeps all of it.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this ScoreTracker) TrimHistory(max int) ScoreTracker {
+	this.maxHistory = max
+	return this
+}
+
+//historyOf gets the zset holding the history of a member
+func (this ScoreTracker) historyOf(member string) SortedSet {
+	return newSortedSet(this.client, this.history+member)
+}
+
+const trackedIncrementScript = `
+local score = redis.call('ZINCRBY', KEYS[1], ARGV[1], ARGV[2])
+redis.call('ZADD', KEYS[2], ARGV[3], ARGV[3] .. ':' .. score)
+local max = tonumber(ARGV[4])
+if max > 0 then
+	redis.call('ZREMRANGEBYRANK', KEYS[2], 0, -max - 1)
+end
+return score
+`
+
+//ZINCRBY and ZADD commands (within a lua script) - 
+//IncrementBy increments the score of a member in the zset, and records the new score in the member's history with the current time, atomically;
+//returns the new score
+func (this ScoreTracker) IncrementBy(item string, score float64) <-chan float64 {
+	now := itoa(int(time.Now().UnixNano() / int64(time.Millisecond)))
+	keys := []string{this.key, this.historyOf(item).key}
+	return FloatCommand(this, evalArgs(trackedIncrementScript, keys, ftoa(score), item, now, itoa(this.maxHistory))...)
+}
+
+//ZRANGEBYSCORE command - 
+//HistoryBetween returns the scores a member reached between two times (inclusive), oldest first.
+//The Member of each entry is the time the score was reached, in milliseconds since the epoch
+func (this ScoreTracker) HistoryBetween(member string, from, to time.Time) <-chan []ScoredMember {
+	min := itoa(int(from.UnixNano() / int64(time.Millisecond)))
+	max := itoa(int(to.UnixNano() / int64(time.Millisecond)))
+	history := this.historyOf(member)
+	in := SliceCommand(history, history.args("zrangebyscore", min, max)...)
+
+	out := make(chan []ScoredMember, 1)
+	go func() {
+		defer close(out)
+		if res, ok := <-in; ok {
+			points := make([]ScoredMember, 0, len(res))
+			for _, entry := range res {
+				i := strings.Index(entry, ":")
+				if i < 0 {
+					continue
+				}
+				score, err := atof(entry[i+1:])
+				if err != nil {
+					continue
+				}
+				points = append(points, ScoredMember{Member: entry[:i], Score: score})
+			}
+			out <- points
+		}
+	}()
+	return out
+}
+
+//Use allows you to use this zset on a different executor
+func (this ScoreTracker) Use(e SafeExecutor) ScoreTracker {
+	this.SortedSet = this.SortedSet.Use(e)
+	return this
+}
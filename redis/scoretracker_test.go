@@ -0,0 +1,34 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScoreTracker(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	board := r.SortedSet("Test_ScoreTracker").WithScoreHistory("Test_ScoreTracker:history:").TrimHistory(2)
+	defer func() {
+		<-board.Delete()
+		<-r.Key("Test_ScoreTracker:history:A").Delete()
+	}()
+
+	start := time.Now()
+	for _, increment := range []float64{1, 2, 3} {
+		<-board.IncrementBy("A", increment)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	history := <-board.HistoryBetween("A", start, time.Now())
+	if len(history) != 2 || history[0].Score != 3 || history[1].Score != 6 {
+		t.Error("Should have kept the last two scores, not", history)
+	}
+	if res := <-board.HistoryBetween("A", start.Add(-time.Hour), start.Add(-time.Minute)); len(res) != 0 {
+		t.Error("Should have no history from before the scores were set, not", res)
+	}
+	if res := <-board.ScoreOf("A"); res != 6 {
+		t.Error("The zset itself should have the score 6, not", res)
+	}
+}
@@ -0,0 +1,195 @@
+package redis
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+)
+
+//evalArgs builds the arguments needed to run a lua script on redis against the keys specified
+func evalArgs(script string, keys []string, args ...string) []string {
+	return scriptArgs("EVAL", script, keys, args...)
+}
+
+//scriptArgs builds the arguments for any of the commands that run a script (EVAL with its source, or EVALSHA with its hash)
+func scriptArgs(command, script string, keys []string, args ...string) []string {
+	result := make([]string, 0, 3+len(keys)+len(args))
+	result = append(result, command, script, itoa(len(keys)))
+	result = append(result, keys...)
+	return append(result, args...)
+}
+
+//A Script is a lua script that gets run on redis by its SHA1 hash (EVALSHA), so that its source only needs to be sent the first time.
+//If redis doesn't have it cached yet (NOSCRIPT), it is sent in full with EVAL instead, which caches it for every call after that
+type Script struct {
+	client SafeExecutor
+	source string
+	sha    string
+}
+
+//NewScript creates a Script from its lua source.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *Client) NewScript(source string) Script {
+	return newScript(this, source)
+}
+
+func newScript(client SafeExecutor, source string) Script {
+	hash := sha1.Sum([]byte(source))
+	return Script{
+		client: client,
+		source: source,
+		sha:    hex.EncodeToString(hash[:]),
+	}
+}
+
+//SHA1 is the hash redis knows the script by
+func (this Script) SHA1() string {
+	return this.sha
+}
+
+//SCRIPT LOAD command - 
+//Load caches the script on redis without running it.
+//Within a Batch or Transaction, commands are only sent once they're run, so EVAL can't be fallen back to in time - Load the script first there
+func (this Script) Load() <-chan error {
+	return ErrorCommand(this.client, "SCRIPT", "LOAD", this.source)
+}
+
+//scriptCommand is an EVALSHA, which needs to look at its own error to know whether to fall back to EVAL
+type scriptCommand struct {
+	args    []string
+	output  chan<- Reply
+	errs    chan error
+	failure *bool
+}
+
+func (this scriptCommand) arguments() []string {
+	return this.args
+}
+
+func (this scriptCommand) handleError(err error) {
+	select {
+	case this.errs <- err:
+	default:
+	}
+}
+
+//NOSCRIPT just means the script needs to be sent with EVAL instead, so it isn't a failure
+func (this scriptCommand) expectedError(err error) bool {
+	return strings.HasPrefix(err.Error(), "NOSCRIPT")
+}
+
+func (this scriptCommand) failed() {
+	*this.failure = true
+}
+
+func (this scriptCommand) callback() func(*response) error {
+	return func(r *response) error {
+		defer close(this.output)
+		if !*this.failure {
+			this.output <- Reply{r}
+		}
+		return nil
+	}
+}
+
+//EVALSHA command (or EVAL, if redis doesn't have it cached yet) - 
+//RunReply runs the script against the keys given, and returns its reply without converting it;
+//as with ReplyCommand, a nil reply is still sent, and the channel only closes without a value if there was an error
+func (this Script) RunReply(keys []string, args []string) <-chan Reply {
+	replies := make(chan Reply, 1)
+	errs := make(chan error, 1)
+	this.client.Execute(scriptCommand{scriptArgs("EVALSHA", this.sha, keys, args...), replies, errs, new(bool)})
+
+	out := make(chan Reply, 1)
+	go func() {
+		defer close(out)
+		if reply, ok := <-replies; ok {
+			out <- reply
+			return
+		}
+		var err error
+		select {
+		case err = <-errs:
+		default:
+		}
+		if err == nil {
+			return
+		}
+		if !strings.HasPrefix(err.Error(), "NOSCRIPT") {
+			this.client.errCallback(err, "EVALSHA "+this.sha)
+			return
+		}
+		if reply, ok := <-ReplyCommand(this.client, evalArgs(this.source, keys, args...)...); ok {
+			out <- reply
+		}
+	}()
+	return out
+}
+
+//EVALSHA command (or EVAL, if redis doesn't have it cached yet) - 
+//Run runs the script against the keys given, and returns its reply:
+//a string for a single value (redis doesn't say whether it was a number), a []interface{} for an array, or nil for a nil reply
+func (this Script) Run(keys []string, args []string) <-chan interface{} {
+	in := this.RunReply(keys, args)
+	out := make(chan interface{}, 1)
+	go func() {
+		defer close(out)
+		if reply, ok := <-in; ok {
+			out <- replyValue(reply)
+		}
+	}()
+	return out
+}
+
+func replyValue(reply Reply) interface{} {
+	if reply.IsNil() {
+		return nil
+	}
+	if reply.IsArray() {
+		array := reply.Array()
+		values := make([]interface{}, len(array))
+		for i, r := range array {
+			values[i] = replyValue(r)
+		}
+		return values
+	}
+	return reply.String()
+}
+
+//EVALSHA command (or EVAL, if redis doesn't have it cached yet) - 
+//RunInt runs the script against the keys given, and returns its reply as an int
+func (this Script) RunInt(keys []string, args []string) <-chan int {
+	in := this.RunReply(keys, args)
+	out := make(chan int, 1)
+	go func() {
+		defer close(out)
+		if reply, ok := <-in; ok && !reply.IsNil() {
+			if i, err := reply.Int(); err == nil {
+				out <- i
+			} else {
+				this.client.errCallback(err, "EVALSHA "+this.sha)
+			}
+		}
+	}()
+	return out
+}
+
+//EVALSHA command (or EVAL, if redis doesn't have it cached yet) - 
+//RunString runs the script against the keys given, and returns its reply as a string
+func (this Script) RunString(keys []string, args []string) <-chan string {
+	in := this.RunReply(keys, args)
+	out := make(chan string, 1)
+	go func() {
+		defer close(out)
+		if reply, ok := <-in; ok && !reply.IsNil() {
+			out <- reply.String()
+		}
+	}()
+	return out
+}
+
+//Use allows you to run this script on a different executor
+func (this Script) Use(e SafeExecutor) Script {
+	this.client = e
+	return this
+}
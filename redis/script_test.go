@@ -0,0 +1,101 @@
+package redis
+
+import (
+	"testing"
+)
+
+func TestScript(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	key := r.Integer("Test_Script")
+	<-key.Delete()
+	defer func() { <-key.Delete() }()
+
+	//a source nobody else has run, so that redis can't have it cached yet
+	s := r.NewScript("-- " + uniqueToken() + "\nreturn redis.call('INCRBY', KEYS[1], ARGV[1])")
+	if res := <-s.RunInt([]string{key.key}, []string{"2"}); res != 2 {
+		t.Error("The first run should fall back to EVAL and get 2, not", res)
+	}
+	if res := <-SliceCommand(r, "SCRIPT", "EXISTS", s.SHA1()); len(res) != 1 || res[0] != "1" {
+		t.Error("Redis should have the script cached after it has run, not", res)
+	}
+	if res := <-s.RunString([]string{key.key}, []string{"3"}); res != "5" {
+		t.Error("The second run should use EVALSHA and get 5, not", res)
+	}
+
+	array := r.NewScript("return {ARGV[1], {ARGV[2]}, false}")
+	res, ok := <-array.Run(nil, []string{"A", "B"})
+	values, isArray := res.([]interface{})
+	if !ok || !isArray || len(values) != 3 || values[0] != "A" || values[2] != nil {
+		t.Fatal("Should have gotten [A [B] nil], not", res)
+	}
+	if inner, isArray := values[1].([]interface{}); !isArray || len(inner) != 1 || inner[0] != "B" {
+		t.Error("Should have gotten [B] within the array, not", values[1])
+	}
+}
+
+func TestScriptError(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+	failed := false
+	r.SetErrorCallback(func(e error, s string) {
+		failed = true
+	})
+
+	s := r.NewScript("return redis.error_reply('ERR broken')")
+	if err := <-s.Load(); err != nil {
+		t.Fatal("Should be able to load the script -", err)
+	}
+	if res, ok := <-s.Run(nil, nil); ok {
+		t.Error("A script that fails should not give anything back, not", res)
+	}
+	if !failed {
+		t.Error("The script's error should go to the error callback")
+	}
+}
+
+func TestScriptCollectorIgnoresNoScript(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	//a source nobody else has run, so that redis can't have it cached yet
+	c := CollectErrors(r)
+	s := r.NewScript("-- " + uniqueToken() + "\nreturn 'ran'").Use(c)
+	if res := <-s.Run(nil, nil); res != "ran" {
+		t.Error("The run should fall back to EVAL and get ran, not", res)
+	}
+	if err := c.LastError(); err != nil {
+		t.Error("Falling back to EVAL should not count as an error, not", err)
+	}
+
+	broken := r.NewScript("-- " + uniqueToken() + "\nreturn redis.error_reply('ERR broken')").Use(c)
+	r.SetErrorCallback(func(error, string) {})
+	if res, ok := <-broken.Run(nil, nil); ok {
+		t.Error("A script that fails should not give anything back, not", res)
+	}
+	if errs := c.Errors(); len(errs) != 1 || errs[0].Error() != "ERR broken" {
+		t.Error("Only the script's own error should be collected, not", errs)
+	}
+}
+
+func TestScriptNoScriptNotRecorded(t *testing.T) {
+	r, done := FakeRedis(t, func(args []string) string {
+		switch args[0] {
+		case "EVALSHA":
+			return "-NOSCRIPT No matching script. Please use EVAL.\r\n"
+		case "EVAL":
+			return ":1\r\n"
+		}
+		return "-ERR unexpected\r\n"
+	})
+	defer done()
+
+	c := CollectErrors(r)
+	if res := <-r.NewScript("return 1").Use(c).RunInt(nil, nil); res != 1 {
+		t.Error("Should have fallen back to EVAL and gotten 1, not", res)
+	}
+	if err := c.LastError(); err != nil {
+		t.Error("NOSCRIPT should not be collected, not", err)
+	}
+}
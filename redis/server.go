@@ -0,0 +1,346 @@
+package redis
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+//CLIENT PAUSE command - 
+//Pause stops redis from processing commands from any client for the duration given.
+//If writeOnly is set, only commands that write are held back (WRITE mode), otherwise every command is (ALL mode).
+//The channel gets nil once redis has started the pause, or the error if it refused
+func (this *Client) Pause(duration time.Duration, writeOnly bool) <-chan error {
+	mode := "ALL"
+	if writeOnly {
+		mode = "WRITE"
+	}
+	return ErrorCommand(this, "CLIENT", "PAUSE", itoa(int(duration/time.Millisecond)), mode)
+}
+
+//CLIENT UNPAUSE command - 
+//Unpause ends a pause started with Pause before its duration is up
+func (this *Client) Unpause() <-chan error {
+	return ErrorCommand(this, "CLIENT", "UNPAUSE")
+}
+
+//LASTSAVE command - 
+//LastSaveTime returns when redis last successfully saved its data to disk
+func (this *Client) LastSaveTime() <-chan time.Time {
+	in := IntCommand(this, "LASTSAVE")
+	out := make(chan time.Time, 1)
+	go func() {
+		defer close(out)
+		if seconds, ok := <-in; ok {
+			out <- time.Unix(int64(seconds), 0)
+		}
+	}()
+	return out
+}
+
+//BGSAVE command - 
+//BackgroundSave has redis start saving its data to disk in the background.
+//The channel gets nil as soon as the save has started (not when it has finished), or the error if it couldn't be started (ie, if a save is already running);
+//poll LastSaveTime to find out when it is done
+func (this *Client) BackgroundSave() <-chan error {
+	return ErrorCommand(this, "BGSAVE")
+}
+
+//RoleInfo describes the part a redis server plays in replication, as reported by ROLE.
+//Role is one of "master", "slave" or "sentinel", and only the fields that apply to that role are filled in
+type RoleInfo struct {
+	Role string
+
+	//master and slave
+	ReplicationOffset int
+
+	//master only
+	Replicas []ReplicaInfo
+
+	//slave only
+	MasterHost string
+	MasterPort int
+	State      string //	ie, connect, connecting, sync or connected
+
+	//sentinel only
+	Masters []string //	the names of the masters being monitored
+}
+
+//ReplicaInfo is a single replica connected to a master
+type ReplicaInfo struct {
+	Host              string
+	Port              int
+	ReplicationOffset int
+}
+
+//ROLE command - 
+//Role returns what part the server plays in replication
+func (this *Client) Role() <-chan RoleInfo {
+	in := rawResponse(this, "ROLE")
+	out := make(chan RoleInfo, 1)
+	go func() {
+		defer close(out)
+		res, ok := <-in
+		if !ok {
+			return
+		}
+		if info, err := parseRole(res); err != nil {
+			this.errCallback(err, "ROLE")
+		} else {
+			out <- info
+		}
+	}()
+	return out
+}
+
+func parseRole(res *response) (RoleInfo, error) {
+	parts := res.subresponses
+	if len(parts) == 0 || parts[0] == nil {
+		return RoleInfo{}, errors.New("Unexpected ROLE reply")
+	}
+	info := RoleInfo{Role: parts[0].val}
+	var err error
+
+	switch info.Role {
+	case "master":
+		if len(parts) < 3 || parts[1] == nil || parts[2] == nil {
+			return info, errors.New("Unexpected ROLE reply for a master")
+		}
+		if info.ReplicationOffset, err = atoi(parts[1].val); err != nil {
+			return info, err
+		}
+		info.Replicas = make([]ReplicaInfo, 0, len(parts[2].subresponses))
+		for _, replica := range parts[2].subresponses {
+			if replica == nil || len(replica.subresponses) < 3 {
+				continue
+			}
+			fields := replica.subresponses
+			r := ReplicaInfo{Host: fields[0].val}
+			if r.Port, err = atoi(fields[1].val); err != nil {
+				return info, err
+			}
+			if r.ReplicationOffset, err = atoi(fields[2].val); err != nil {
+				return info, err
+			}
+			info.Replicas = append(info.Replicas, r)
+		}
+	case "slave":
+		if len(parts) < 5 || parts[1] == nil || parts[2] == nil || parts[3] == nil || parts[4] == nil {
+			return info, errors.New("Unexpected ROLE reply for a slave")
+		}
+		info.MasterHost = parts[1].val
+		if info.MasterPort, err = atoi(parts[2].val); err != nil {
+			return info, err
+		}
+		info.State = parts[3].val
+		if info.ReplicationOffset, err = atoi(parts[4].val); err != nil {
+			return info, err
+		}
+	case "sentinel":
+		if len(parts) > 1 && parts[1] != nil {
+			for _, master := range parts[1].subresponses {
+				if master != nil {
+					info.Masters = append(info.Masters, master.val)
+				}
+			}
+		}
+	}
+	return info, nil
+}
+
+//FailoverOptions are the options for Failover; the zero value lets redis pick the replica, and waits as long as it takes
+type FailoverOptions struct {
+	//the replica to promote (TO host port); leave both empty to let redis pick one
+	Host string
+	Port int
+	//give up waiting for the replica to catch up after this long (TIMEOUT)
+	Timeout time.Duration
+	//promote the replica once the Timeout is up, even if it hasn't caught up (FORCE); needs a Host and a Timeout
+	Force bool
+	//stop a failover that is already going on (ABORT); can't be used with any of the other options
+	Abort bool
+}
+
+func (this FailoverOptions) args() ([]string, error) {
+	if this.Abort {
+		if this.Host != "" || this.Port != 0 || this.Timeout != 0 || this.Force {
+			return nil, errors.New("FAILOVER ABORT can't be given any other options")
+		}
+		return []string{"FAILOVER", "ABORT"}, nil
+	}
+	args := []string{"FAILOVER"}
+	if (this.Host == "") != (this.Port == 0) {
+		return nil, errors.New("FAILOVER TO needs both a host and a port")
+	}
+	if this.Host != "" {
+		args = append(args, "TO", this.Host, itoa(this.Port))
+	}
+	if this.Force {
+		if this.Host == "" || this.Timeout <= 0 {
+			return nil, errors.New("FAILOVER FORCE needs both a target and a timeout")
+		}
+		args = append(args, "FORCE")
+	}
+	if this.Timeout < 0 {
+		return nil, errors.New("FAILOVER TIMEOUT can't be negative")
+	}
+	if this.Timeout > 0 {
+		args = append(args, "TIMEOUT", itoa(int(this.Timeout/time.Millisecond)))
+	}
+	return args, nil
+}
+
+//FAILOVER command - 
+//Failover has this master hand its role over to one of its replicas, once that replica has caught up, without losing any writes.
+//The channel gets nil once redis has started the failover (not when it has finished; poll Role to find out when it is done),
+//or the error if it refused, or if the options can't be used together (in which case nothing is sent).
+//This needs redis 6.2 or later
+func (this *Client) Failover(opts FailoverOptions) <-chan error {
+	args, err := opts.args()
+	if err != nil {
+		return ErrorCommand(failingExecutor{this, err}, "FAILOVER")
+	}
+	return ErrorCommand(this, args...)
+}
+
+//SlowLogEntry is a single command that redis logged for taking longer than its slowlog-log-slower-than setting
+type SlowLogEntry struct {
+	ID         int
+	Time       time.Time     //	when the command was run
+	Duration   time.Duration //	how long redis spent running it (not counting network I/O)
+	Args       []string      //	the command and its arguments (redis shortens these if there are a lot, or they are long)
+	ClientAddr string        //	only reported by redis 4.0 or later
+	ClientName string        //	only reported by redis 4.0 or later, and only if the client has set a name
+}
+
+//SLOWLOG GET command - 
+//SlowLogGet returns up to count of the most recent entries in the slowlog, newest first;
+//a negative count returns all of them (the whole slowlog needs redis 7.0 or later)
+func (this *Client) SlowLogGet(count int) <-chan []SlowLogEntry {
+	in := rawResponse(this, "SLOWLOG", "GET", itoa(count))
+	out := make(chan []SlowLogEntry, 1)
+	go func() {
+		defer close(out)
+		res, ok := <-in
+		if !ok {
+			return
+		}
+		if entries, err := parseSlowLog(res); err != nil {
+			this.errCallback(err, "SLOWLOG GET")
+		} else {
+			out <- entries
+		}
+	}()
+	return out
+}
+
+func parseSlowLog(res *response) ([]SlowLogEntry, error) {
+	entries := make([]SlowLogEntry, 0, len(res.subresponses))
+	for _, entry := range res.subresponses {
+		if entry == nil || len(entry.subresponses) < 4 {
+			return entries, errors.New("Unexpected SLOWLOG entry")
+		}
+		fields := entry.subresponses
+		for _, field := range fields[:4] {
+			if field == nil {
+				return entries, errors.New("Unexpected SLOWLOG entry")
+			}
+		}
+
+		var e SlowLogEntry
+		var err error
+		if e.ID, err = atoi(fields[0].val); err != nil {
+			return entries, err
+		}
+		seconds, err := atoi(fields[1].val)
+		if err != nil {
+			return entries, err
+		}
+		e.Time = time.Unix(int64(seconds), 0)
+		micros, err := atoi(fields[2].val)
+		if err != nil {
+			return entries, err
+		}
+		e.Duration = time.Duration(micros) * time.Microsecond
+		e.Args = make([]string, 0, len(fields[3].subresponses))
+		for _, arg := range fields[3].subresponses {
+			if arg != nil {
+				e.Args = append(e.Args, arg.val)
+			}
+		}
+		if len(fields) > 5 && fields[4] != nil && fields[5] != nil {
+			e.ClientAddr, e.ClientName = fields[4].val, fields[5].val
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+//SLOWLOG RESET command - 
+//SlowLogReset empties the slowlog
+func (this *Client) SlowLogReset() <-chan error {
+	return ErrorCommand(this, "SLOWLOG", "RESET")
+}
+
+//CommandStat is how much use redis has seen of a single command since its stats were last reset
+type CommandStat struct {
+	Calls         int
+	Duration      time.Duration //	the total time spent running the command
+	PerCall       time.Duration //	the average time spent running it each time
+	RejectedCalls int           //	only reported by redis 6.2 or later
+	FailedCalls   int           //	only reported by redis 6.2 or later
+}
+
+//INFO commandstats command - 
+//CommandStats returns redis's stats for every command that has been run, by command name (lower case, as redis reports them;
+//subcommands are reported separately by redis 7 and later, ie "client|list").
+//The times only count how long redis itself spent running the commands, not any time spent on the network
+func (this *Client) CommandStats() <-chan map[string]CommandStat {
+	in := StringCommand(this, "INFO", "commandstats")
+	out := make(chan map[string]CommandStat, 1)
+	go func() {
+		defer close(out)
+		if info, ok := <-in; ok {
+			out <- parseCommandStats(info)
+		}
+	}()
+	return out
+}
+
+func parseCommandStats(info string) map[string]CommandStat {
+	stats := make(map[string]CommandStat)
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "cmdstat_") {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimPrefix(line, "cmdstat_"), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		var stat CommandStat
+		for _, field := range strings.Split(parts[1], ",") {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			switch kv[0] {
+			case "calls":
+				stat.Calls, _ = atoi(kv[1])
+			case "usec":
+				usec, _ := atoi(kv[1])
+				stat.Duration = time.Duration(usec) * time.Microsecond
+			case "usec_per_call":
+				usec, _ := atof(kv[1])
+				stat.PerCall = time.Duration(usec * float64(time.Microsecond))
+			case "rejected_calls":
+				stat.RejectedCalls, _ = atoi(kv[1])
+			case "failed_calls":
+				stat.FailedCalls, _ = atoi(kv[1])
+			}
+		}
+		stats[parts[0]] = stat
+	}
+	return stats
+}
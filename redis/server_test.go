@@ -0,0 +1,199 @@
+package redis
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPause(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	if err := <-r.Pause(time.Minute, true); err != nil {
+		t.Error("Should be able to pause writes -", err)
+	}
+	if err := <-r.Unpause(); err != nil {
+		t.Error("Should be able to unpause -", err)
+	}
+
+	if err := <-r.Pause(-time.Second, false); err == nil {
+		t.Error("Should not be able to pause for a negative duration")
+	}
+}
+
+func TestRole(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	res, ok := <-r.Role()
+	if !ok {
+		t.Fatal("Should be able to get the role")
+	}
+	if res.Role != "master" && res.Role != "slave" {
+		t.Error("A normal server should be a master or a slave, not", res.Role)
+	}
+	if res.ReplicationOffset < 0 {
+		t.Error("The replication offset should not be negative, not", res.ReplicationOffset)
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	bulk := func(s string) *response { return &response{val: s} }
+	multi := func(rs ...*response) *response { return &response{subresponses: rs} }
+
+	master, err := parseRole(multi(bulk("master"), bulk("3129659"), multi(multi(bulk("127.0.0.1"), bulk("9001"), bulk("3129242")))))
+	if err != nil {
+		t.Fatal("Should be able to parse a master -", err)
+	}
+	if master.Role != "master" || master.ReplicationOffset != 3129659 || len(master.Replicas) != 1 || master.Replicas[0] != (ReplicaInfo{"127.0.0.1", 9001, 3129242}) {
+		t.Error("Parsed the master incorrectly -", master)
+	}
+
+	slave, err := parseRole(multi(bulk("slave"), bulk("127.0.0.1"), bulk("9000"), bulk("connected"), bulk("3167038")))
+	if err != nil {
+		t.Fatal("Should be able to parse a slave -", err)
+	}
+	if slave.MasterHost != "127.0.0.1" || slave.MasterPort != 9000 || slave.State != "connected" || slave.ReplicationOffset != 3167038 {
+		t.Error("Parsed the slave incorrectly -", slave)
+	}
+
+	sentinel, err := parseRole(multi(bulk("sentinel"), multi(bulk("resque-master"), bulk("html-fragments-master"))))
+	if err != nil {
+		t.Fatal("Should be able to parse a sentinel -", err)
+	}
+	if len(sentinel.Masters) != 2 || sentinel.Masters[1] != "html-fragments-master" {
+		t.Error("Parsed the sentinel incorrectly -", sentinel)
+	}
+}
+
+func TestBackgroundSave(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	before, ok := <-r.LastSaveTime()
+	if !ok || before.IsZero() || before.After(time.Now()) {
+		t.Fatal("Should have gotten a sensible last save time, not", before)
+	}
+	//LASTSAVE only counts seconds, so a save in the same second as the last one couldn't be told apart
+	time.Sleep(time.Until(before.Add(time.Second)))
+	if err := <-r.BackgroundSave(); err != nil {
+		t.Fatal("Should be able to start a background save -", err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !(<-r.LastSaveTime()).After(before) {
+		if time.Now().After(deadline) {
+			t.Fatal("The background save never finished")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func TestFailoverOptions(t *testing.T) {
+	valid := map[string]FailoverOptions{
+		"FAILOVER":                  {},
+		"FAILOVER ABORT":            {Abort: true},
+		"FAILOVER TO 10.0.0.2 6379": {Host: "10.0.0.2", Port: 6379},
+		"FAILOVER TIMEOUT 500":      {Timeout: 500 * time.Millisecond},
+		"FAILOVER TO 10.0.0.2 6379 FORCE TIMEOUT 2000": {Host: "10.0.0.2", Port: 6379, Force: true, Timeout: 2 * time.Second},
+	}
+	for expected, opts := range valid {
+		if args, err := opts.args(); err != nil || strings.Join(args, " ") != expected {
+			t.Error("Should have gotten", expected, "not", args, err)
+		}
+	}
+
+	invalid := []FailoverOptions{
+		{Abort: true, Host: "10.0.0.2", Port: 6379},
+		{Abort: true, Force: true},
+		{Host: "10.0.0.2"},
+		{Port: 6379},
+		{Force: true, Timeout: time.Second},
+		{Host: "10.0.0.2", Port: 6379, Force: true},
+		{Timeout: -time.Second},
+	}
+	for _, opts := range invalid {
+		if args, err := opts.args(); err == nil {
+			t.Error(opts, "should not be allowed, but got", args)
+		}
+	}
+}
+
+func TestFailover(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	if err := <-r.Failover(FailoverOptions{Abort: true, Force: true}); err == nil {
+		t.Error("Invalid options should give back an error")
+	}
+	//the test server has no replicas to hand over to, so redis should refuse
+	if err := <-r.Failover(FailoverOptions{}); err == nil {
+		t.Error("A failover without any replicas should give back an error")
+	}
+}
+
+func TestParseSlowLog(t *testing.T) {
+	bulk := func(s string) *response { return &response{val: s} }
+	multi := func(rs ...*response) *response { return &response{subresponses: rs} }
+
+	entries, err := parseSlowLog(multi(
+		multi(bulk("14"), bulk("1309448221"), bulk("15"), multi(bulk("ZRANGEBYSCORE"), bulk("scores"), bulk("0"), bulk("100")), bulk("127.0.0.1:58217"), bulk("worker")),
+		multi(bulk("13"), bulk("1309448128"), bulk("30"), multi(bulk("SLOWLOG"), bulk("GET"), bulk("100"))),
+	))
+	if err != nil {
+		t.Fatal("Should be able to parse the slowlog -", err)
+	}
+	if len(entries) != 2 {
+		t.Fatal("Should have gotten 2 entries, not", entries)
+	}
+	first := entries[0]
+	if first.ID != 14 || first.Time.Unix() != 1309448221 || first.Duration != 15*time.Microsecond || strings.Join(first.Args, " ") != "ZRANGEBYSCORE scores 0 100" {
+		t.Error("Parsed the first entry incorrectly -", first)
+	}
+	if first.ClientAddr != "127.0.0.1:58217" || first.ClientName != "worker" {
+		t.Error("Should have parsed the client info -", first)
+	}
+	if entries[1].ID != 13 || entries[1].ClientAddr != "" {
+		t.Error("An entry from an older redis shouldn't have client info -", entries[1])
+	}
+
+	if _, err := parseSlowLog(multi(multi(bulk("14"), bulk("1309448221")))); err == nil {
+		t.Error("A short entry should not parse")
+	}
+}
+
+func TestSlowLog(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	if err := <-r.SlowLogReset(); err != nil {
+		t.Fatal("Should be able to reset the slowlog -", err)
+	}
+	if entries, ok := <-r.SlowLogGet(10); !ok || len(entries) > 1 {
+		t.Error("The slowlog should have just been emptied, not", entries)
+	}
+}
+
+func TestParseCommandStats(t *testing.T) {
+	stats := parseCommandStats("# Commandstats\r\ncmdstat_zadd:calls=12,usec=90,usec_per_call=7.50,rejected_calls=1,failed_calls=2\r\ncmdstat_client|list:calls=1,usec=20,usec_per_call=20.00\r\n")
+	if len(stats) != 2 {
+		t.Fatal("Should have gotten 2 commands, not", stats)
+	}
+	if stats["zadd"] != (CommandStat{12, 90 * time.Microsecond, 7500 * time.Nanosecond, 1, 2}) {
+		t.Error("Parsed zadd incorrectly -", stats["zadd"])
+	}
+	if stats["client|list"].Calls != 1 || stats["client|list"].PerCall != 20*time.Microsecond {
+		t.Error("Parsed client|list incorrectly -", stats["client|list"])
+	}
+}
+
+func TestCommandStats(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	<-r.Key("Test_CommandStats").Exists()
+	if stats := <-r.CommandStats(); stats["exists"].Calls < 1 {
+		t.Error("Should have counted the EXISTS that was just run, not", stats["exists"])
+	}
+}
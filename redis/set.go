@@ -4,14 +4,27 @@ package redis
 //see http://redis.io/commands#set for more information on redis sets
 type Set struct {
 	SortableKey
+	scanThreshold int
 }
 
 func newSet(client SafeExecutor, key string) Set {
 	return Set{
-		newSortableKey(client, key),
+		SortableKey: newSortableKey(client, key, KeyTypeSet),
 	}
 }
 
+const (
+	//DefaultScanThreshold is the largest set MembersSafe will get with a single SMEMBERS, unless WithScanThreshold says otherwise
+	DefaultScanThreshold = 1000
+)
+
+//WithScanThreshold gives back the same set, but with MembersSafe switching over to SSCAN for any set with more than threshold members.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this Set) WithScanThreshold(threshold int) Set {
+	this.scanThreshold = threshold
+	return this
+}
+
 //IsValid returns whether the underlying redis object can use the commands in this object
 func (this Set) IsValid() <-chan bool {
 	c := make(chan bool, 1)
@@ -29,6 +42,13 @@ func (this Set) Add(item string) <-chan bool {
 	return BoolCommand(this, this.args("sadd", item)...)
 }
 
+//SADD command - 
+//AddMany adds all of the strings to the set, splitting them up into several SADDs if there are more than the MaxArgsPerCommand;
+//returns how many of them weren't already in the set
+func (this Set) AddMany(items ...string) <-chan int {
+	return this.bulk("sadd", 1, 0, items)
+}
+
 //SREM command - 
 //Remove removes a string from the set if it exists;
 //returns whether or not the string existed in the set
@@ -42,6 +62,62 @@ func (this Set) Members() <-chan []string {
 	return SliceCommand(this, this.args("smembers")...)
 }
 
+//SMEMBERS command - 
+//MembersWithPresence returns all of the strings in the set, along with whether the set exists at all.
+//Redis deletes a set once its last member is removed, so an empty set is always one that doesn't exist;
+//to cache an empty result, store a placeholder member instead.
+//If something goes wrong, both channels close without a value
+func (this Set) MembersWithPresence() (<-chan []string, <-chan bool) {
+	return slicePresence(this.Members())
+}
+
+//SCARD, and then SMEMBERS or SSCAN commands - 
+//MembersSafe returns all of the strings in the set, the same as Members,
+//but a set with more members than the threshold (DefaultScanThreshold, or the one given to WithScanThreshold) is read with SSCAN a batch at a time,
+//so that redis isn't blocked sending one huge reply.
+//The batches aren't read atomically, so strings added or removed while a large set is being read may or may not be included
+func (this Set) MembersSafe() <-chan []string {
+	threshold := this.scanThreshold
+	if threshold <= 0 {
+		threshold = DefaultScanThreshold
+	}
+
+	out := make(chan []string, 1)
+	size := this.Size()
+	go func() {
+		defer close(out)
+		n, ok := <-size
+		if !ok {
+			return
+		}
+		if n <= threshold {
+			if res, ok := <-this.Members(); ok {
+				out <- res
+			}
+			return
+		}
+
+		members := newScanner(this, func(cursor string) []string {
+			return this.args("sscan", cursor, "COUNT", itoa(threshold))
+		})
+		seen := make(map[string]struct{}, n)
+		res := make([]string, 0, n)
+		for batch, ok := members.next(); ok; batch, ok = members.next() {
+			for _, member := range batch {
+				//SSCAN can give back the same string more than once
+				if _, found := seen[member]; !found {
+					seen[member] = struct{}{}
+					res = append(res, member)
+				}
+			}
+		}
+		if !members.failed {
+			out <- res
+		}
+	}()
+	return out
+}
+
 //SISMEMBER - 
 //IsMember returns whether or not the string is a member of the set
 func (this Set) IsMember(item string) <-chan bool {
@@ -54,12 +130,26 @@ func (this Set) Size() <-chan int {
 	return IntCommand(this, this.args("scard")...)
 }
 
+//SCARD command - 
+//IsEmpty returns whether or not there are no strings in this set
+func (this Set) IsEmpty() <-chan bool {
+	return isZeroChannel(this.Size())
+}
+
 //SRANDMEMBER command - 
 //RandomMember returns a random string from the set
 func (this Set) RandomMember() <-chan string {
 	return StringCommand(this, this.args("srandmember")...)
 }
 
+//SRANDMEMBER command - 
+//RandomMembers returns random strings from the set.
+//A positive count returns up to count distinct strings (the whole set if it is smaller than that);
+//a negative count returns exactly -count strings, which may include the same string more than once
+func (this Set) RandomMembers(count int) <-chan []string {
+	return SliceCommand(this, this.args("srandmember", itoa(count))...)
+}
+
 //SPOP command - 
 //Pop removes a random string from the set and returns it
 func (this Set) Pop() <-chan string {
@@ -69,71 +159,65 @@ func (this Set) Pop() <-chan string {
 //SINTER command - 
 //Intersection returns all of the strings that are in both this set and another
 func (this Set) Intersection(otherSets ...Set) <-chan []string {
-	args := this.args("sinter")
-	for _, set := range otherSets {
-		args = append(args, set.key)
-	}
-	return SliceCommand(this, args...)
+	e, args := this.combine("sinter", otherSets)
+	return SliceCommand(e, args...)
 }
 
 //SUNION - 
 //Union returns all of the strings that are either in this set or another
 func (this Set) Union(otherSets ...Set) <-chan []string {
-	args := this.args("sunion")
-	for _, set := range otherSets {
-		args = append(args, set.key)
-	}
-	return SliceCommand(this, args...)
+	e, args := this.combine("sunion", otherSets)
+	return SliceCommand(e, args...)
 }
 
 //SDIFF command - 
 //Difference returns all of the strings that are in this set, but not another
 func (this Set) Difference(otherSets ...Set) <-chan []string {
-	args := this.args("sdiff")
-	for _, set := range otherSets {
-		args = append(args, set.key)
-	}
-	return SliceCommand(this, args...)
+	e, args := this.combine("sdiff", otherSets)
+	return SliceCommand(e, args...)
 }
 
 //SINTERSTORE command - 
 //StoreIntersectionOf finds the intersection of two other sets and stores it in this set;
 //returns the size of the resulting set
 func (this Set) StoreIntersectionOf(sets ...Set) <-chan int {
-	args := this.args("sinterstore")
-	for _, set := range sets {
-		args = append(args, set.key)
-	}
-	return IntCommand(this, args...)
+	e, args := this.combine("sinterstore", sets)
+	return IntCommand(e, args...)
 }
 
 //SUNIONSTORE command - 
 //StoreUnionOf finds the union of two other sets and stores it in this set;
 //returns the size of the resulting set
 func (this Set) StoreUnionOf(sets ...Set) <-chan int {
-	args := this.args("sunionstore")
-	for _, set := range sets {
-		args = append(args, set.key)
-	}
-	return IntCommand(this, args...)
+	e, args := this.combine("sunionstore", sets)
+	return IntCommand(e, args...)
 }
 
 //SDIFFSTORE command - 
 //StoreDifferenceOf finds the difference of two other sets and stores it in this set;
 //returns the size of the resulting set
 func (this Set) StoreDifferenceOf(sets ...Set) <-chan int {
-	args := this.args("sdiffstore")
-	for _, set := range sets {
-		args = append(args, set.key)
-	}
-	return IntCommand(this, args...)
+	e, args := this.combine("sdiffstore", sets)
+	return IntCommand(e, args...)
 }
 
 //SMOVE command
 //MoveMemberTo removes a string from this set and adds it to another;
 //nothing happens if the string doesn't exist in this set
 func (this Set) MoveMemberTo(newSet Set, item string) <-chan bool {
-	return BoolCommand(this, this.args("smove", newSet.key, item)...)
+	return BoolCommand(crossShardGuard(this.Key, newSet.Key), this.args("smove", newSet.key, item)...)
+}
+
+//combine gives the executor and arguments for a command that uses this set followed by the others;
+//if any of them are on a different client, the command fails with ErrCrossShard
+func (this Set) combine(command string, others []Set) (Executor, []string) {
+	args := this.args(command)
+	keys := make([]Key, len(others))
+	for i, set := range others {
+		args = append(args, set.key)
+		keys[i] = set.Key
+	}
+	return crossShardGuard(this.Key, keys...), args
 }
 
 //Use allows you to use this key on a different executor
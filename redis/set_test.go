@@ -215,3 +215,158 @@ func TestSets(t *testing.T) {
 		t.Error("There should now be no more members in the base set")
 	}
 }
+
+func TestSetIsEmpty(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	set := r.Set("Test_SetIsEmpty")
+	<-set.Delete()
+
+	if !<-set.IsEmpty() {
+		t.Error("A set that doesn't exist should be empty")
+	}
+	<-set.Add("A")
+	if <-set.IsEmpty() {
+		t.Error("A set with a member should not be empty")
+	}
+}
+
+func TestSetMembersSafe(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	set := r.Set("Test_SetMembersSafe")
+	<-set.Delete()
+	defer func() { <-set.Delete() }()
+
+	if res := <-set.MembersSafe(); len(res) != 0 {
+		t.Error("A set that doesn't exist should have no members, not", res)
+	}
+
+	for i := 0; i < 50; i++ {
+		<-set.Add(itoa(i))
+	}
+	for _, threshold := range []int{100, 5} {
+		res := <-set.WithScanThreshold(threshold).MembersSafe()
+		seen := make(map[string]bool)
+		for _, member := range res {
+			seen[member] = true
+		}
+		if len(res) != 50 || len(seen) != 50 {
+			t.Error("Should have gotten all 50 members once each with a threshold of", threshold, "not", res)
+		}
+	}
+}
+
+func TestSetRandomMembers(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	set := r.Set("Test_SetRandomMembers")
+	<-set.Delete()
+	defer func() { <-set.Delete() }()
+
+	<-set.Add("A")
+	<-set.Add("B")
+	<-set.Add("C")
+
+	res := <-set.RandomMembers(5)
+	seen := make(map[string]bool)
+	for _, member := range res {
+		seen[member] = true
+	}
+	if len(res) != 3 || len(seen) != 3 {
+		t.Error("A positive count should give each member at most once, not", res)
+	}
+
+	res = <-set.RandomMembers(-10)
+	if len(res) != 10 {
+		t.Error("A negative count should give exactly that many members, not", res)
+	}
+	for _, member := range res {
+		if member != "A" && member != "B" && member != "C" {
+			t.Error(member, "is not a member of the set")
+		}
+	}
+}
+
+func TestSetAddManySplits(t *testing.T) {
+	batch := (&Client{config: Config{MaxArgsPerCommand: 5}}).Batch()
+	newSet(batch, "Test_SetAddManySplits").AddMany("A", "B", "C", "D", "E", "F", "G")
+	if batch.Size() != 3 {
+		t.Error("7 members at 3 per SADD should take 3 commands, not", batch.Size())
+	}
+
+	batch = (&Client{}).Batch()
+	newSet(batch, "Test_SetAddManySplits").AddMany("A", "B", "C", "D", "E", "F", "G")
+	if batch.Size() != 1 {
+		t.Error("Without a MaxArgsPerCommand everything should go in 1 command, not", batch.Size())
+	}
+}
+
+func TestSetAddManySplitSizes(t *testing.T) {
+	sizes := make(chan int, 10)
+	r, done := FakeRedis(t, func(args []string) string {
+		sizes <- len(args)
+		return ":" + itoa(len(args)-2) + "\r\n"
+	})
+	defer done()
+	r.config.MaxArgsPerCommand = 5
+
+	if res := <-r.Set("A").AddMany("1", "2", "3", "4", "5", "6", "7"); res != 7 {
+		t.Error("All 7 members should have been added, not", res)
+	}
+	for _, expected := range []int{5, 5, 3} {
+		if size := <-sizes; size != expected {
+			t.Error("Each SADD should have had", expected, "arguments, not", size)
+		}
+	}
+
+	//a ZADD needs room for the key, a score and a member
+	r.config.MaxArgsPerCommand = 3
+	if res, ok := <-r.SortedSet("A").AddMany(ScoredMember{"1", 1}); ok {
+		t.Error("A ZADD can't fit in 3 arguments, so it should have failed, not given back", res)
+	}
+	select {
+	case size := <-sizes:
+		t.Error("Nothing should have been sent, not a command with", size, "arguments")
+	default:
+	}
+}
+
+func TestSetAddMany(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	s := r.Set("Test_SetAddMany")
+	<-s.Delete()
+	defer func() { <-s.Delete() }()
+	<-s.Add("A")
+
+	if res := <-s.AddMany("A", "B", "C"); res != 2 {
+		t.Error("Only B and C should be new, but got", res)
+	}
+	if res := <-s.Size(); res != 3 {
+		t.Error("The set should have 3 members, not", res)
+	}
+}
+
+func TestSetMembersWithPresence(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	s := r.Set("Test_SetMembersWithPresence")
+	<-s.Delete()
+	defer func() { <-s.Delete() }()
+
+	members, present := s.MembersWithPresence()
+	if res := <-members; len(res) != 0 || <-present {
+		t.Error("A missing set should be empty and not present, not", res)
+	}
+	<-s.Add("A")
+	members, present = s.MembersWithPresence()
+	if res := <-members; len(res) != 1 || !<-present {
+		t.Error("Should have gotten A, not", res)
+	}
+}
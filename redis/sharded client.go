@@ -0,0 +1,335 @@
+package redis
+
+import (
+	"errors"
+	"hash/crc32"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	//how many points each shard gets on the hash ring; more points spread the keys out more evenly
+	shardReplicas = 160
+)
+
+//ErrCrossShard is given when a command needs keys that live on different shards of a ShardedClient
+var ErrCrossShard = errors.New("the keys used by this command live on different shards")
+
+//A ShardedClient spreads keys across several standalone redis servers (or databases) using consistent hashing.
+//Every key is always sent to the same shard, and adding or removing a shard only moves around the keys that have to move.
+//Like redis cluster, if a key contains a hash tag (ie, the "user1000" in "{user1000}.following"), only the hash tag is used to pick the shard,
+//so keys that need to be used together can be kept on the same shard.
+//Commands that use several keys (ie, combos) only work when all of those keys are on the same shard; otherwise they fail with ErrCrossShard.
+//The reads that can safely be split up (GetStrings, TopOfEach and GlobalTop) are sent to each shard instead, and their results combined
+type ShardedClient struct {
+	shards []*Client
+	ring   []shardPoint //	sorted by hash
+}
+
+type shardPoint struct {
+	hash  uint32
+	shard *Client
+}
+
+//Shard creates a ShardedClient which spreads keys across all of the clients given.
+//Each client is identified by its address and database, so the same keys go to the same servers no matter what order the clients are given in
+func Shard(clients ...*Client) *ShardedClient {
+	this := &ShardedClient{
+		shards: clients,
+		ring:   make([]shardPoint, 0, len(clients)*shardReplicas),
+	}
+	for _, client := range clients {
+		name := client.config.NetType + "://" + client.config.NetAddress + "/" + itoa(client.config.DBid)
+		for i := 0; i < shardReplicas; i++ {
+			this.ring = append(this.ring, shardPoint{crc32.ChecksumIEEE([]byte(name + "#" + itoa(i))), client})
+		}
+	}
+	sort.Slice(this.ring, func(i, j int) bool {
+		return this.ring[i].hash < this.ring[j].hash
+	})
+	return this
+}
+
+//hashTag gives the part of the key that is used to pick its shard
+func hashTag(key string) string {
+	if start := strings.Index(key, "{"); start >= 0 {
+		if end := strings.Index(key[start+1:], "}"); end > 0 {
+			return key[start+1 : start+1+end]
+		}
+	}
+	return key
+}
+
+//ShardFor returns the client that the key is kept on
+func (this *ShardedClient) ShardFor(key string) *Client {
+	hash := crc32.ChecksumIEEE([]byte(hashTag(key)))
+	i := sort.Search(len(this.ring), func(i int) bool {
+		return this.ring[i].hash >= hash
+	})
+	if i == len(this.ring) {
+		//the ring wraps around
+		i = 0
+	}
+	return this.ring[i].shard
+}
+
+//Shards returns every client that keys are spread across
+func (this *ShardedClient) Shards() []*Client {
+	return this.shards
+}
+
+//Close closes every shard
+func (this *ShardedClient) Close() error {
+	var err error
+	for _, shard := range this.shards {
+		if e := shard.Close(); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+//SetErrorCallback sets the error callback on every shard
+func (this *ShardedClient) SetErrorCallback(callback func(error, string)) {
+	for _, shard := range this.shards {
+		shard.SetErrorCallback(callback)
+	}
+}
+
+//Creates a basic key.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ShardedClient) Key(key string) Key {
+	return this.ShardFor(key).Key(key)
+}
+
+//Creates a String object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ShardedClient) String(key string) String {
+	return this.ShardFor(key).String(key)
+}
+
+//Creates an Integer object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ShardedClient) Integer(key string) Integer {
+	return this.ShardFor(key).Integer(key)
+}
+
+//Creates a Float object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ShardedClient) Float(key string) Float {
+	return this.ShardFor(key).Float(key)
+}
+
+//Creates a Bits object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ShardedClient) Bits(key string) Bits {
+	return this.ShardFor(key).Bits(key)
+}
+
+//Creates a Hash object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ShardedClient) Hash(key string) Hash {
+	return this.ShardFor(key).Hash(key)
+}
+
+//Creates a List object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ShardedClient) List(key string) List {
+	return this.ShardFor(key).List(key)
+}
+
+//Creates an IntList object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ShardedClient) IntList(key string) IntList {
+	return this.ShardFor(key).IntList(key)
+}
+
+//Creates a Set object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ShardedClient) Set(key string) Set {
+	return this.ShardFor(key).Set(key)
+}
+
+//Creates an IntSet object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ShardedClient) IntSet(key string) IntSet {
+	return this.ShardFor(key).IntSet(key)
+}
+
+//Creates a SortedSet object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ShardedClient) SortedSet(key string) SortedSet {
+	return this.ShardFor(key).SortedSet(key)
+}
+
+//Creates a CappedSortedSet object, which never keeps more than maxSize members.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ShardedClient) CappedSortedSet(key string, maxSize int) CappedSortedSet {
+	return this.ShardFor(key).CappedSortedSet(key, maxSize)
+}
+
+//Creates a DecimalSortedSet object, which ranks its members by exact decimal scores.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ShardedClient) DecimalSortedSet(key string) DecimalSortedSet {
+	return this.ShardFor(key).DecimalSortedSet(key)
+}
+
+//Creates a SortedIntSet object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ShardedClient) SortedIntSet(key string) SortedIntSet {
+	return this.ShardFor(key).SortedIntSet(key)
+}
+
+//Creates a HyperLogLog object.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ShardedClient) HyperLogLog(key string) HyperLogLog {
+	return this.ShardFor(key).HyperLogLog(key)
+}
+
+//Creates a Mutex Object.
+//(Warning - this is *not* a lightweight function - there is some network I/O involved in mutex initialization)
+func (this *ShardedClient) Mutex(key string) Mutex {
+	return this.ShardFor(key).Mutex(key)
+}
+
+//Creates a Semaphore Object.
+//(Warning - this is *not* a lightweight function - there is some network I/O involved in mutex initialization)
+func (this *ShardedClient) Semaphore(key string, count int) Mutex {
+	return this.ShardFor(key).Semaphore(key, count)
+}
+
+//Creates a ReadWriteMutex Object.
+//(Warning - this is *not* a lightweight function - there is some network I/O involved in mutex initialization)
+func (this *ShardedClient) ReadWriteMutex(key string, readers int) *ReadWriteMutex {
+	return this.ShardFor(key).ReadWriteMutex(key, readers)
+}
+
+//Creates a Channel Object.
+//Messages are only seen by subscribers on the same shard, so publishers and subscribers should both go through the ShardedClient.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ShardedClient) Channel(key string) Channel {
+	return this.ShardFor(key).Channel(key)
+}
+
+//Creates a Prefix Object, which helps namespace other Redis Objects.
+//Keys created through it are sharded by their full name.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *ShardedClient) Prefix(key string) Prefix {
+	return newPrefix(this, key)
+}
+
+//Get works the same way as Client.Get, on whichever shard the key is kept on
+func (this *ShardedClient) Get(key string) <-chan interface{} {
+	return this.ShardFor(key).Get(key)
+}
+
+//ExpireMatching works the same way as Client.ExpireMatching, but across every shard;
+//returns how many keys were given the TTL in total
+func (this *ShardedClient) ExpireMatching(pattern string, ttl time.Duration) <-chan int {
+	counts := make([]<-chan int, len(this.shards))
+	for i, shard := range this.shards {
+		counts[i] = shard.ExpireMatching(pattern, ttl)
+	}
+	return sumChannel(counts)
+}
+
+//ZREVRANGE command (on every shard) - 
+//GlobalTop works the same way as Client.GlobalTop, but the zsets can be on any shard.
+//The top n of each zset are read, and combined here, which gives the same result as combining the whole zsets would
+func (this *ShardedClient) GlobalTop(n int, sets ...SortedSet) <-chan []ScoredMember {
+	tops := this.TopOfEach(n, sets...)
+	out := make(chan []ScoredMember, 1)
+	go func() {
+		defer close(out)
+		best := make(map[string]float64)
+		for _, top := range <-tops {
+			for _, member := range top {
+				if score, ok := best[member.Member]; !ok || member.Score > score {
+					best[member.Member] = member.Score
+				}
+			}
+		}
+
+		combined := make([]ScoredMember, 0, len(best))
+		for member, score := range best {
+			combined = append(combined, ScoredMember{member, score})
+		}
+		//the same order ZREVRANGE uses: highest score first, and ties in reverse lexicographical order
+		sort.Slice(combined, func(i, j int) bool {
+			if combined[i].Score != combined[j].Score {
+				return combined[i].Score > combined[j].Score
+			}
+			return combined[i].Member > combined[j].Member
+		})
+		if len(combined) > n {
+			combined = combined[:n]
+		}
+		out <- combined
+	}()
+	return out
+}
+
+//ZREVRANGE command (pipelined on every shard) - 
+//TopOfEach works the same way as Client.TopOfEach, but the zsets can be on any shard;
+//there is one round trip to each shard that any of the zsets are on
+func (this *ShardedClient) TopOfEach(n int, sets ...SortedSet) <-chan map[string][]ScoredMember {
+	return topOfEachShard(n, sets)
+}
+
+//MGET command (on every shard) - 
+//GetStrings works the same way as Client.GetStrings, but the keys can be on any shard;
+//there is one MGET for each shard that any of the keys are on, and if any of them fail, nothing is returned
+func (this *ShardedClient) GetStrings(keys ...string) <-chan map[string]string {
+	var order []*Client
+	groups := make(map[*Client][]string)
+	for _, key := range keys {
+		shard := this.ShardFor(key)
+		if _, ok := groups[shard]; !ok {
+			order = append(order, shard)
+		}
+		groups[shard] = append(groups[shard], key)
+	}
+	results := make([]<-chan map[string]string, len(order))
+	for i, shard := range order {
+		results[i] = shard.GetStrings(groups[shard]...)
+	}
+
+	out := make(chan map[string]string, 1)
+	go func() {
+		defer close(out)
+		combined := make(map[string]string, len(keys))
+		failed := false
+		for _, result := range results {
+			values, ok := <-result
+			failed = failed || !ok
+			for key, value := range values {
+				combined[key] = value
+			}
+		}
+		if !failed {
+			out <- combined
+		}
+	}()
+	return out
+}
+
+//PFCOUNT command - 
+//ApproxUniqueAcross works the same way as Client.ApproxUniqueAcross, but only if every shadow is on the same shard
+//(each shadow is kept on the same shard as its zset).
+//HyperLogLogs on different shards can't be counted together, so otherwise it fails with ErrCrossShard
+func (this *ShardedClient) ApproxUniqueAcross(boards ...ShadowedSortedSet) <-chan int {
+	if len(boards) == 0 {
+		return approxUniqueAcross(nil, boards)
+	}
+	shard := boards[0].shadow.client
+	for _, board := range boards[1:] {
+		if onDifferentClients(shard, board.shadow.client) {
+			out := make(chan int)
+			close(out)
+			shard.errCallback(ErrCrossShard, "ApproxUniqueAcross")
+			return out
+		}
+	}
+	return approxUniqueAcross(shard, boards)
+}
@@ -0,0 +1,327 @@
+package redis
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShardRing(t *testing.T) {
+	shard := func(address string) *Client {
+		return &Client{config: Config{NetType: "tcp", NetAddress: address}}
+	}
+	a, b, c := shard("a:6379"), shard("b:6379"), shard("c:6379")
+	sharded := Shard(a, b, c)
+	reordered := Shard(c, a, b)
+	fewer := Shard(a, b)
+
+	counts := make(map[*Client]int)
+	for i := 0; i < 3000; i++ {
+		key := "Test_ShardRing:" + itoa(i)
+		s := sharded.ShardFor(key)
+		counts[s]++
+		if reordered.ShardFor(key) != s {
+			t.Error(key, "should go to the same shard no matter the order of the clients")
+		}
+		if s != c && fewer.ShardFor(key) != s {
+			t.Error(key, "should not move when a different shard is removed")
+		}
+	}
+	for _, s := range []*Client{a, b, c} {
+		if counts[s] < 600 {
+			t.Error("Keys should be spread fairly evenly, but", s.config.NetAddress, "only got", counts[s])
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		if sharded.ShardFor("{user1000}.following") != sharded.ShardFor("{user1000}.followers:"+itoa(i)) {
+			t.Error("Keys with the same hash tag should be on the same shard")
+		}
+	}
+	if res := hashTag("{}.key"); res != "{}.key" {
+		t.Error("An empty hash tag should not be used, but got", res)
+	}
+}
+
+func TestShardedClient(t *testing.T) {
+	first := GetRedis(t)
+	config := DefaultConfiguration()
+	config.DBid = 1
+	config.ConnectionCount = 5
+	second, err := New(config)
+	if err != nil {
+		t.Fatal("Can't load redis - " + err.Error())
+	}
+	sharded := Shard(first, second)
+	defer sharded.Close()
+	failures := make(chan error, 1)
+	sharded.SetErrorCallback(func(e error, s string) {
+		failures <- e
+	})
+
+	//find a couple of keys that land on different shards
+	a, b := "Test_Sharded:0", ""
+	for i := 1; b == ""; i++ {
+		if key := "Test_Sharded:" + itoa(i); sharded.ShardFor(key) != sharded.ShardFor(a) {
+			b = key
+		}
+	}
+	defer func() {
+		<-sharded.Key(a).Delete()
+		<-sharded.Key(b).Delete()
+	}()
+
+	<-sharded.String(a).Set("A")
+	if res, ok := <-sharded.ShardFor(a).String(a).Get(); !ok || res != "A" {
+		t.Error("The key should have been stored on its shard, but got", res)
+	}
+	if <-sharded.ShardFor(b).Key(a).Exists() {
+		t.Error("The key should not have been stored on any other shard")
+	}
+	<-sharded.String(a).Delete()
+
+	sa, sb := sharded.SortedSet(a), sharded.SortedSet(b)
+	<-sa.Add("X", 1)
+	<-sa.Add("Y", 5)
+	<-sb.Add("X", 3)
+	<-sb.Add("Z", 2)
+
+	expected := []ScoredMember{{"Y", 5}, {"X", 3}}
+	if res := <-sharded.GlobalTop(2, sa, sb); len(res) != 2 || res[0] != expected[0] || res[1] != expected[1] {
+		t.Error("The global top should be", expected, "not", res)
+	}
+	if res := <-sharded.TopOfEach(1, sa, sb); len(res[a]) != 1 || res[a][0] != expected[0] || len(res[b]) != 1 || res[b][0] != expected[1] {
+		t.Error("Should have gotten the top of each shard's zset, not", res)
+	}
+
+	if _, ok := <-sa.StoreUnion().OfSet(sa).OfSet(sb).UseCombinedScores(); ok {
+		t.Error("Should not be able to combine zsets on different shards")
+	}
+	select {
+	case e := <-failures:
+		if e != ErrCrossShard {
+			t.Error("Should have reported a cross shard error, not", e)
+		}
+	case <-time.After(time.Second):
+		t.Error("Combining zsets on different shards should cause an error")
+	}
+}
+
+//crossShardClient gives a ShardedClient (whose shards never connect to anything) along with two keys that land on different shards;
+//every error the shards report is counted
+func crossShardClient(t *testing.T) (*ShardedClient, string, string, func() []error) {
+	shard := func(address string) *Client {
+		return &Client{config: Config{NetType: "tcp", NetAddress: address}}
+	}
+	sharded := Shard(shard("a:6379"), shard("b:6379"))
+	var failures []error
+	sharded.SetErrorCallback(func(e error, s string) {
+		failures = append(failures, e)
+	})
+
+	a, b := "Test_CrossShard:0", ""
+	for i := 1; b == ""; i++ {
+		if key := "Test_CrossShard:" + itoa(i); sharded.ShardFor(key) != sharded.ShardFor(a) {
+			b = key
+		}
+	}
+	return sharded, a, b, func() []error {
+		reported := failures
+		failures = nil
+		return reported
+	}
+}
+
+func TestCrossShardSets(t *testing.T) {
+	sharded, a, b, failures := crossShardClient(t)
+	sa, sb := sharded.Set(a), sharded.Set(b)
+	ia, ib := sharded.IntSet(a), sharded.IntSet(b)
+
+	operations := map[string]func() bool{
+		"Set.Intersection":           func() bool { _, ok := <-sa.Intersection(sb); return ok },
+		"Set.Union":                  func() bool { _, ok := <-sa.Union(sb); return ok },
+		"Set.Difference":             func() bool { _, ok := <-sa.Difference(sb); return ok },
+		"Set.StoreIntersectionOf":    func() bool { _, ok := <-sa.StoreIntersectionOf(sa, sb); return ok },
+		"Set.StoreUnionOf":           func() bool { _, ok := <-sa.StoreUnionOf(sa, sb); return ok },
+		"Set.StoreDifferenceOf":      func() bool { _, ok := <-sa.StoreDifferenceOf(sa, sb); return ok },
+		"Set.MoveMemberTo":           func() bool { _, ok := <-sa.MoveMemberTo(sb, "A"); return ok },
+		"IntSet.Intersection":        func() bool { _, ok := <-ia.Intersection(ib); return ok },
+		"IntSet.Union":               func() bool { _, ok := <-ia.Union(ib); return ok },
+		"IntSet.Difference":          func() bool { _, ok := <-ia.Difference(ib); return ok },
+		"IntSet.StoreIntersectionOf": func() bool { _, ok := <-ia.StoreIntersectionOf(ia, ib); return ok },
+		"IntSet.StoreUnionOf":        func() bool { _, ok := <-ia.StoreUnionOf(ia, ib); return ok },
+		"IntSet.StoreDifferenceOf":   func() bool { _, ok := <-ia.StoreDifferenceOf(ia, ib); return ok },
+		"IntSet.MoveMemberTo":        func() bool { _, ok := <-ia.MoveMemberTo(ib, 1); return ok },
+	}
+	for name, operation := range operations {
+		if operation() {
+			t.Error(name, "should not work on sets from different shards")
+		}
+		if res := failures(); len(res) != 1 || res[0] != ErrCrossShard {
+			t.Error(name, "should have reported a cross shard error, not", res)
+		}
+	}
+}
+
+func TestCrossShardKeys(t *testing.T) {
+	sharded, a, b, failures := crossShardClient(t)
+	la, lb := sharded.List(a), sharded.List(b)
+
+	operations := map[string]func() bool{
+		"Key.MoveTo":                  func() bool { _, ok := <-sharded.Key(a).MoveTo(sharded.Key(b)); return ok },
+		"Key.MoveToIfEmpty":           func() bool { _, ok := <-sharded.Key(a).MoveToIfEmpty(sharded.Key(b)); return ok },
+		"List.MoveLastItemToList":     func() bool { _, ok := <-la.MoveLastItemToList(lb); return ok },
+		"List.BlockUntilMoveLastItem": func() bool { _, ok := <-la.BlockUntilMoveLastItemToListWithTimeout(lb, 1); return ok },
+		"IntList.MoveLastItemToList": func() bool {
+			_, ok := <-sharded.IntList(a).MoveLastItemToList(sharded.IntList(b))
+			return ok
+		},
+		"IntList.BlockUntilMoveLastItem": func() bool {
+			_, ok := <-sharded.IntList(a).BlockUntilMoveLastItemToListWithTimeout(sharded.IntList(b), 1)
+			return ok
+		},
+		"Sorter.Store": func() bool { _, ok := <-la.Sort().Store(lb); return ok },
+		"Sorter.StoreInts": func() bool {
+			_, ok := <-sharded.IntList(a).Sort().StoreInts(sharded.IntList(b))
+			return ok
+		},
+		"HyperLogLog.MergeFrom": func() bool {
+			_, ok := <-sharded.HyperLogLog(a).MergeFrom(sharded.HyperLogLog(b))
+			return ok
+		},
+	}
+	for name, operation := range operations {
+		if operation() {
+			t.Error(name, "should not work on keys from different shards")
+		}
+		if res := failures(); len(res) != 1 || res[0] != ErrCrossShard {
+			t.Error(name, "should have reported a cross shard error, not", res)
+		}
+	}
+}
+
+func TestCrossShardSortedSets(t *testing.T) {
+	sharded, a, b, failures := crossShardClient(t)
+	sa, sb := sharded.SortedSet(a), sharded.SortedSet(b)
+
+	operations := map[string]func() bool{
+		"SortedSet.MembersNotIn":            func() bool { _, ok := <-sa.MembersNotIn(sb); return ok },
+		"SortedSet.Difference":              func() bool { _, ok := <-sa.Difference(sb); return ok },
+		"SortedSet.IntersectionCardinality": func() bool { _, ok := <-sa.IntersectionCardinality(sb); return ok },
+		"SortedSet.PromoteTop":              func() bool { _, ok := <-sa.PromoteTop(sb); return ok },
+		"SortedSet.StoreRangeInto":          func() bool { _, ok := <-sa.StoreRangeInto(sb, 0, -1); return ok },
+		"SortedIntSet.StoreUnion": func() bool {
+			_, ok := <-sharded.SortedIntSet(a).StoreUnion().OfSet(sharded.SortedIntSet(a)).OfSet(sharded.SortedIntSet(b)).UseCombinedScores()
+			return ok
+		},
+		"SortedIntSet.StoreIntersection": func() bool {
+			_, ok := <-sharded.SortedIntSet(a).StoreIntersection().OfWeightedSet(sharded.SortedIntSet(b), 2).UseLowerScore()
+			return ok
+		},
+		"BlockingPopMin": func() bool { _, ok := <-BlockingPopMin(time.Second, sa, sb); return ok },
+		"BlockingLeftPop": func() bool {
+			_, ok := <-BlockingLeftPop(time.Second, sharded.List(a), sharded.List(b))
+			return ok
+		},
+	}
+	for name, operation := range operations {
+		if operation() {
+			t.Error(name, "should not work on keys from different shards")
+		}
+		if res := failures(); len(res) != 1 || res[0] != ErrCrossShard {
+			t.Error(name, "should have reported a cross shard error, not", res)
+		}
+	}
+}
+
+func TestCrossShardTops(t *testing.T) {
+	sharded, a, b, failures := crossShardClient(t)
+	sa, sb := sharded.SortedSet(a), sharded.SortedSet(b)
+
+	if _, ok := <-sharded.ShardFor(a).GlobalTop(1, sa, sb); ok {
+		t.Error("Client.GlobalTop should not work on zsets from different shards")
+	}
+	if res := failures(); len(res) != 1 || res[0] != ErrCrossShard {
+		t.Error("Client.GlobalTop should have reported a cross shard error, not", res)
+	}
+}
+
+//fakeShards gives a ShardedClient over two FakeRedis shards which both answer with reply, and records every key each shard is sent;
+//it also gives back a key that lands on each shard
+func fakeShards(t *testing.T, reply func(args []string) string) (*ShardedClient, [2]string, [2]chan []string, func()) {
+	sent := [2]chan []string{make(chan []string, 10), make(chan []string, 10)}
+	first, firstDone := FakeRedis(t, func(args []string) string {
+		sent[0] <- args[1:]
+		return reply(args)
+	})
+	second, secondDone := FakeRedis(t, func(args []string) string {
+		sent[1] <- args[1:]
+		return reply(args)
+	})
+	sharded := Shard(first, second)
+
+	var keys [2]string
+	for i := 0; keys[0] == "" || keys[1] == ""; i++ {
+		key := "Test_Fake:" + itoa(i)
+		if sharded.ShardFor(key) == first {
+			keys[0] = key
+		} else {
+			keys[1] = key
+		}
+	}
+	return sharded, keys, sent, func() {
+		firstDone()
+		secondDone()
+	}
+}
+
+func TestShardedGetStrings(t *testing.T) {
+	sharded, keys, sent, done := fakeShards(t, func(args []string) string {
+		//every key holds its own name, other than the missing ones
+		reply := "*" + itoa(len(args)-1) + "\r\n"
+		for _, key := range args[1:] {
+			if strings.HasSuffix(key, ":Missing") {
+				reply += "$-1\r\n"
+				continue
+			}
+			reply += "$" + itoa(len(key)) + "\r\n" + key + "\r\n"
+		}
+		return reply
+	})
+	defer done()
+
+	res, ok := <-sharded.GetStrings(keys[0], keys[1])
+	if !ok || len(res) != 2 || res[keys[0]] != keys[0] || res[keys[1]] != keys[1] {
+		t.Error("Should have gotten the value of each key from its own shard, not", res)
+	}
+	for i, key := range keys {
+		if got := <-sent[i]; len(got) != 1 || got[0] != key {
+			t.Error("Shard", i, "should only have been sent", key, "not", got)
+		}
+	}
+
+	//the shard is used directly, so it doesn't matter where the key would be sharded to
+	if res, ok := <-sharded.Shards()[0].GetStrings(keys[0], "Test_Fake:Missing"); !ok || len(res) != 1 || res[keys[0]] != keys[0] {
+		t.Error("A key that doesn't exist should be left out, not", res)
+	}
+	<-sent[0]
+}
+
+func TestClientTopOfEachAcrossShards(t *testing.T) {
+	sharded, keys, sent, done := fakeShards(t, func(args []string) string {
+		//every zset's only member is named after it
+		return "*2\r\n$" + itoa(len(args[1])) + "\r\n" + args[1] + "\r\n$1\r\n1\r\n"
+	})
+	defer done()
+
+	a, b := sharded.SortedSet(keys[0]), sharded.SortedSet(keys[1])
+	res := <-sharded.ShardFor(keys[0]).TopOfEach(1, a, b)
+	for i, key := range keys {
+		if top := res[key]; len(top) != 1 || top[0] != (ScoredMember{key, 1}) {
+			t.Error("Should have gotten the top of", key, "from its own shard, not", top)
+		}
+		if got := <-sent[i]; len(got) == 0 || got[0] != key {
+			t.Error("Shard", i, "should only have been sent", key, "not", got)
+		}
+	}
+}
@@ -1,5 +1,9 @@
 package redis
 
+import (
+	"errors"
+)
+
 //SortableKey is a base type used to give other types the functions within here
 //Redis's Sort function works with multiple types of keys, and in order to prevent too much code duplication, I've just created a base type
 //See http://redis.io/commands/sort for more information on Redis's sort
@@ -7,12 +11,18 @@ type SortableKey struct {
 	Key
 }
 
-func newSortableKey(client SafeExecutor, key string) SortableKey {
+func newSortableKey(client SafeExecutor, key string, kind KeyType) SortableKey {
 	return SortableKey{
-		newKey(client, key),
+		newTypedKey(client, key, kind),
 	}
 }
 
+//Sort will define a search in which redis sorts numbers, the same as SortNumerically;
+//use Alpha on the Sorter to sort strings instead
+func (this SortableKey) Sort() *Sorter {
+	return this.SortNumerically()
+}
+
 //SortAlphabetically will define a search in which redis sorts strings
 func (this SortableKey) SortAlphabetically() *Sorter {
 	return &Sorter{key: this.Key, alpha: true}
@@ -32,7 +42,7 @@ type sortBy struct {
 }
 
 type sortGet struct {
-	pattern string
+	patterns []string
 }
 
 type sortStore struct {
@@ -47,10 +57,30 @@ type Sorter struct {
 	store    *sortStore
 	alpha    bool
 	reversed bool
+	readOnly bool
 
 	key Key
 }
 
+//errReadOnlyStore is given when a Sorter is asked to both be read only and store its results
+var errReadOnlyStore = errors.New("a read only sort can't store its results")
+
+//args gives the whole command for the sort, using SORT_RO if it was asked to be read only
+func (this Sorter) args() []string {
+	if this.readOnly {
+		return this.key.args("sort_ro", this.sortargs()...)
+	}
+	return this.key.args("sort", this.sortargs()...)
+}
+
+//storer is what a storing sort gets issued on; a read only sort can't store, and neither can one whose destination is on a different client, so every command fails instead
+func (this Sorter) storer(dest Key) Executor {
+	if this.readOnly {
+		return failingExecutor{this.key.client, errReadOnlyStore}
+	}
+	return crossShardGuard(this.key, dest)
+}
+
 func (this Sorter) sortargs() []string {
 	result := make([]string, 0, 10)
 	if this.by != nil {
@@ -60,7 +90,9 @@ func (this Sorter) sortargs() []string {
 		result = append(result, "LIMIT", itoa(this.limit.offset), itoa(this.limit.count))
 	}
 	if this.get != nil {
-		result = append(result, "GET", this.get.pattern)
+		for _, pattern := range this.get.patterns {
+			result = append(result, "GET", pattern)
+		}
 	}
 	if this.reversed {
 		result = append(result, "DESC")
@@ -68,7 +100,7 @@ func (this Sorter) sortargs() []string {
 	if this.alpha {
 		result = append(result, "ALPHA")
 	}
-	if this.store != nil {
+	if this.store != nil && !this.readOnly {
 		result = append(result, "STORE", this.store.dest)
 	}
 	return result
@@ -88,7 +120,8 @@ func (this *Sorter) Limit(offset, count int) *Sorter {
 
 //By allows you to use the current key as an index into a different set of keys
 //
-//Example: If you have a Set with {1,2,3,4,5}, and you sort By("string_*"), you will sort whatever string primitives are at string_1, string_2, string_3, string_4, and string_5
+//Example: If you have a Set with {1,2,3,4,5}, and you sort By("string_*"), you will sort whatever string primitives are at string_1, string_2, string_3, string_4, and string_5.
+//With a ShardedClient, the keys a pattern names (for By and the GetFrom methods alike) are only looked up on the sorted key's shard, so give them the same hash tag
 func (this *Sorter) By(pattern string) *Sorter {
 	this.by = &sortBy{
 		pattern: pattern,
@@ -98,8 +131,21 @@ func (this *Sorter) By(pattern string) *Sorter {
 
 func (this *Sorter) getFrom(pattern string) *Sorter {
 	this.get = &sortGet{
-		pattern: pattern,
+		patterns: []string{pattern},
+	}
+	return this
+}
+
+//GetPatterns uses the results of the sort to dig into other keys, rather than returning the results themselves.
+//It can be given several patterns (and used several times), in which case each result gives back one value for every pattern, one after another.
+//The pattern "#" gives back the result itself, and "hash_*->field" digs into a field of a hash.
+//
+//Example: If you sort a Set of user ids with GetPatterns("#", "user_*->name"), Result will give back id1, name1, id2, name2, ...
+func (this *Sorter) GetPatterns(patterns ...string) *Sorter {
+	if this.get == nil {
+		this.get = &sortGet{}
 	}
+	this.get.patterns = append(this.get.patterns, patterns...)
 	return this
 }
 
@@ -118,9 +164,43 @@ func (this *Sorter) Reverse() *Sorter {
 	return this
 }
 
+//ReadOnly makes the sort use SORT_RO, which redis knows can never write anything, so it can be run on a replica.
+//A read only sort can't Store its results (or use any of the other Store methods); trying to fails the command (this needs redis 7 or later)
+func (this *Sorter) ReadOnly() *Sorter {
+	this.readOnly = true
+	return this
+}
+
+//Alpha makes the sort compare the values as strings rather than as numbers
+func (this *Sorter) Alpha() *Sorter {
+	this.alpha = true
+	return this
+}
+
+//Descending makes the sort give back the highest values first.
+//Unlike Reverse, using it more than once doesn't flip the order back
+func (this *Sorter) Descending() *Sorter {
+	this.reversed = true
+	return this
+}
+
+//Result will execute the search specified and return the result as a slice of strings.
+//If GetPatterns was used, any keys that don't exist give back an empty string
+func (this *Sorter) Result() <-chan []string {
+	return SliceCommand(this.key, this.args()...)
+}
+
+//Store will execute the sort, but instead of returning the results will store them in a list primitive (replacing whatever was there);
+//returns the number of items stored.
+//It is the equivalent of using a STORE argument
+func (this *Sorter) Store(dest List) <-chan int {
+	this.storeIn(dest.key)
+	return IntCommand(this.storer(dest.Key), this.args()...)
+}
+
 //Get will execute the search specified and return the result as a slice of strings
 func (this *Sorter) Get() <-chan []string {
-	return SliceCommand(this.key, this.key.args("sort", this.sortargs()...)...)
+	return SliceCommand(this.key, this.args()...)
 }
 
 //GetInts will execute the search specified and return the result as a slice of integers
@@ -137,7 +217,7 @@ func (this *Sorter) GetFloats() <-chan []float64 {
 //It is the equivalent of using a GET argument in the sort
 func (this *Sorter) GetFrom(pattern string) <-chan []*string {
 	this.getFrom(pattern)
-	return MaybeSliceCommand(this.key, this.key.args("sort", this.sortargs()...)...)
+	return MaybeSliceCommand(this.key, this.args()...)
 }
 
 //GetFrom will execute the search, but instead of returning the results, will use the results to dig into other string primitives containing (hopefully) integers.
@@ -155,15 +235,14 @@ func (this *Sorter) GetFloatsFrom(pattern string) <-chan []*float64 {
 //StoreStrings will execute the sort, but instead of returning the results will store them in a list primitive.
 //It is the equivalent of using a STORE argument
 func (this *Sorter) StoreStrings(dest List) <-chan int {
-	this.storeIn(dest.key)
-	return IntCommand(this.key, this.key.args("sort", this.sortargs()...)...)
+	return this.Store(dest)
 }
 
 //StoreInts will execute the sort, but instead of returning the results will store them in a list primitive.
 //It is the equivalent of using a STORE argument
 func (this *Sorter) StoreInts(dest IntList) <-chan int {
 	this.storeIn(dest.key)
-	return IntCommand(this.key, this.key.args("sort", this.sortargs()...)...)
+	return IntCommand(this.storer(dest.Key), this.args()...)
 }
 
 //GetFromAndStoreIn is like using both GetFrom and StoreStrings
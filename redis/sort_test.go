@@ -2,6 +2,7 @@ package redis
 
 import (
 	"testing"
+	"time"
 )
 
 func TestSorting(t *testing.T) {
@@ -816,3 +817,90 @@ func TestSorting(t *testing.T) {
 		t.Error("New element should not be found in lookup")
 	}
 }
+
+func TestSortBuilder(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ids := r.Set("Test_SortBuilder_Ids")
+	dest := r.List("Test_SortBuilder_Dest")
+	names := map[string]string{"1": "Carol", "2": "Alice", "3": "Bob"}
+	cleanup := func() {
+		<-ids.Delete()
+		<-dest.Delete()
+		for id := range names {
+			<-r.Key("Test_SortBuilder_User_" + id).Delete()
+		}
+	}
+	cleanup()
+	defer cleanup()
+
+	for id, name := range names {
+		<-ids.Add(id)
+		<-r.Hash("Test_SortBuilder_User_" + id).String("name").Set(name)
+	}
+
+	if res := <-ids.Sort().Descending().Result(); len(res) != 3 || res[0] != "3" || res[1] != "2" || res[2] != "1" {
+		t.Error("Should have gotten 3 2 1, not", res)
+	}
+	if res := <-ids.Sort().Descending().Descending().Result(); len(res) != 3 || res[0] != "3" {
+		t.Error("Descending twice should still be descending, not", res)
+	}
+
+	res := <-ids.Sort().By("Test_SortBuilder_User_*->name").Alpha().GetPatterns("#", "Test_SortBuilder_User_*->name").Result()
+	expected := []string{"2", "Alice", "3", "Bob", "1", "Carol"}
+	if len(res) != len(expected) {
+		t.Fatal("Should have gotten", expected, "not", res)
+	}
+	for i := range expected {
+		if res[i] != expected[i] {
+			t.Error("Should have gotten", expected, "not", res)
+			break
+		}
+	}
+
+	if res := <-ids.Sort().Limit(0, 2).GetPatterns("Test_SortBuilder_User_*->name").Store(dest); res != 2 {
+		t.Error("Should have stored 2 names, not", res)
+	}
+	if res := <-dest.GetFromRange(0, -1); len(res) != 2 || res[0] != "Carol" || res[1] != "Alice" {
+		t.Error("Should have stored Carol and Alice, not", res)
+	}
+}
+
+func TestSortReadOnly(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	list := r.List("Test_SortReadOnly")
+	dest := r.List("Test_SortReadOnly_Dest")
+	<-list.Delete()
+	<-dest.Delete()
+	defer func() {
+		<-list.Delete()
+		<-dest.Delete()
+	}()
+	<-list.RightPush("3", "1", "2")
+
+	if res := <-list.Sort().ReadOnly().Result(); len(res) != 3 || res[0] != "1" || res[1] != "2" || res[2] != "3" {
+		t.Error("Should have gotten 1 2 3, not", res)
+	}
+
+	failed := make(chan error, 1)
+	r.SetErrorCallback(func(e error, s string) {
+		failed <- e
+	})
+	if res, ok := <-list.Sort().ReadOnly().Store(dest); ok {
+		t.Error("A read only sort should not be able to store, but stored", res)
+	}
+	select {
+	case e := <-failed:
+		if e != errReadOnlyStore {
+			t.Error("Should have reported that a read only sort can't store, not", e)
+		}
+	case <-time.After(time.Second):
+		t.Error("Storing a read only sort should cause an error")
+	}
+	if <-dest.Exists() {
+		t.Error("Nothing should have been stored")
+	}
+}
@@ -10,7 +10,7 @@ type SortedIntSet struct {
 
 func newSortedIntSet(client SafeExecutor, key string) SortedIntSet {
 	return SortedIntSet{
-		newSortableKey(client, key),
+		newSortableKey(client, key, KeyTypeSortedSet),
 	}
 }
 
@@ -51,6 +51,12 @@ func (this SortedIntSet) Size() <-chan int {
 	return IntCommand(this, this.args("zcard")...)
 }
 
+//ZCARD command -
+//IsEmpty returns whether or not there are no members in this zset
+func (this SortedIntSet) IsEmpty() <-chan bool {
+	return isZeroChannel(this.Size())
+}
+
 //ZRANK command -
 //IndexOf returns the index of a member.
 //ie, the lowest ranked member would have an index of 0, and the next lowest an index of 1
@@ -106,8 +112,7 @@ func (this SortedIntSet) RemoveIndexedBetween(start, stop int) <-chan int {
 
 //SortedIntSetRange keeps track of all range arguments being used in a search
 type SortedIntSetRange struct {
-	min, max      string
-	fmin, fmax    float64
+	scoreBounds
 	limited       bool
 	offset, count int
 	reversed      bool
@@ -115,48 +120,38 @@ type SortedIntSetRange struct {
 	key Key
 }
 
-//Scores createa a SortedIntSetRange to help narrow a search to be done later
+//Scores createa a SortedIntSetRange to help narrow a search to be done later.
+//Bounds can be added in any order, and the range will always be the intersection of all of them:
+//successive lower bounds (Above, AboveOrEqualTo) keep whichever is highest, and successive upper bounds (Below, BelowOrEqualTo) keep whichever is lowest.
+//When an exclusive and inclusive bound are at the same score, the exclusive one wins
 func (this SortedIntSet) Scores() *SortedIntSetRange {
 	return &SortedIntSetRange{
-		min: "-inf",
-		max: "+inf",
-		key: this.Key,
+		scoreBounds: newScoreBounds(),
+		key:         this.Key,
 	}
 }
 
 //Above limits results to members who have a score above "min"
 func (this *SortedIntSetRange) Above(min float64) *SortedIntSetRange {
-	if this.min == "-inf" || this.fmin <= min {
-		this.fmin = min
-		this.min = "(" + ftoa(min)
-	}
+	this.raiseMin(min, true)
 	return this
 }
 
 //Below limits results to members who have a score below "max"
 func (this *SortedIntSetRange) Below(max float64) *SortedIntSetRange {
-	if this.max == "+inf" || this.fmax >= max {
-		this.fmax = max
-		this.max = "(" + ftoa(max)
-	}
+	this.lowerMax(max, true)
 	return this
 }
 
 //AboveOrEqualTo limits results to members who have a score above or equal to "min"
 func (this *SortedIntSetRange) AboveOrEqualTo(min float64) *SortedIntSetRange {
-	if this.min == "-inf" || this.fmin < min {
-		this.fmin = min
-		this.min = ftoa(min)
-	}
+	this.raiseMin(min, false)
 	return this
 }
 
 //BelowOrEqualTo limits results to members who have a score below or equal to "max"
 func (this *SortedIntSetRange) BelowOrEqualTo(max float64) *SortedIntSetRange {
-	if this.max == "+inf" || this.fmax > max {
-		this.fmax = max
-		this.max = ftoa(max)
-	}
+	this.lowerMax(max, false)
 	return this
 }
 
@@ -237,9 +232,10 @@ func (this *SortedIntSetRange) GetWithScores() <-chan map[int]float64 {
 
 //SortedIntSetCombo keeps track of how you want to be combining multiple zsets
 type SortedIntSetCombo struct {
-	weighted bool
-	op       string //either Union or Intersection
-	sets     map[string]float64
+	weighted   bool
+	op         string //either Union or Intersection
+	sets       weightedSets
+	crossShard bool //whether any of the zsets are on a different client than the key, which redis could never see
 
 	key Key
 }
@@ -264,58 +260,48 @@ func (this SortedIntSet) StoreIntersection() *SortedIntSetCombo {
 
 //OfSet adds a zset to the combo
 func (this *SortedIntSetCombo) OfSet(otherSet SortedIntSet) *SortedIntSetCombo {
-	if this.sets == nil {
-		this.sets = make(map[string]float64)
-	}
-	this.sets[otherSet.key] = 1.0
+	this.sets = this.sets.add(otherSet.key, 1.0)
+	this.crossShard = this.crossShard || onDifferentClients(this.key.client, otherSet.client)
 	return this
 }
 
 //OfWeightedSet adds a zset to the combo, and weights it to be either heavier or lighter than other zsets
 func (this *SortedIntSetCombo) OfWeightedSet(otherSet SortedIntSet, weight float64) *SortedIntSetCombo {
-	if this.sets == nil {
-		this.sets = make(map[string]float64)
-	}
 	this.weighted = true
-	this.sets[otherSet.key] = weight
+	this.sets = this.sets.add(otherSet.key, weight)
+	this.crossShard = this.crossShard || onDifferentClients(this.key.client, otherSet.client)
 	return this
 }
 
 //UseLowerScore combines the zsets, and when duplicates are found, will keep the lowest score found
 func (this *SortedIntSetCombo) UseLowerScore() <-chan int {
-	return IntCommand(this.key, this.args("MIN")...)
+	return IntCommand(this.executor(), this.args("MIN")...)
 }
 
 //UseHigherScore combines the zsets, and when duplicates are found, will keep the highest score found
 func (this *SortedIntSetCombo) UseHigherScore() <-chan int {
-	return IntCommand(this.key, this.args("MAX")...)
+	return IntCommand(this.executor(), this.args("MAX")...)
 }
 
 //UseCombinedScores combines the zsets, and when duplicates are found, will add the scores together
 func (this *SortedIntSetCombo) UseCombinedScores() <-chan int {
-	return IntCommand(this.key, this.args("SUM")...)
+	return IntCommand(this.executor(), this.args("SUM")...)
 }
 
-func (this *SortedIntSetCombo) args(mode string) []string {
-	result := make([]string, 1, 11)
-	result[0] = itoa(len(this.sets))
-
-	weights := make([]string, 1, 3)
-	weights[0] = "WEIGHTS"
-
-	for set, weight := range this.sets {
-		result = append(result, set)
-		weights = append(weights, ftoa(weight))
-	}
-
-	if this.weighted {
-		result = append(result, weights...)
+//executor is what the combo's commands are issued on.
+//If the zsets are spread across different clients the combo can't work, so its commands fail with ErrCrossShard instead
+func (this *SortedIntSetCombo) executor() Executor {
+	if this.crossShard {
+		return failingExecutor{this.key.client, ErrCrossShard}
 	}
+	return this.key
+}
 
+func (this *SortedIntSetCombo) args(mode string) []string {
+	result := this.sets.args(this.weighted)
 	if mode != "SUM" {
 		result = append(result, "AGGREGATE", mode)
 	}
-
 	return this.key.args(this.op, result...)
 }
 
@@ -0,0 +1,70 @@
+package redis
+
+//A SortedSetPager goes through the members of a zset a page at a time, in strict index order (which a SortedSetScanner doesn't keep).
+//Each page is fetched by index, so members added or removed between pages shift everything after them, and can make a member show up twice or not at all
+type SortedSetPager struct {
+	set      SortedSet
+	size     int
+	reversed bool
+	offset   int //the index the next page starts at
+	finished bool
+	page     []ScoredMember
+}
+
+//Pages creates a SortedSetPager over this zset, with pageSize members on each page (lowest score first, or highest first if reversed);
+//call Next to get to the first page.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this SortedSet) Pages(pageSize int, reversed bool) *SortedSetPager {
+	return &SortedSetPager{
+		set:      this,
+		size:     pageSize,
+		reversed: reversed,
+	}
+}
+
+//ZRANGE or ZREVRANGE command - 
+//Next fetches the next page;
+//returns false once the end of the zset has been reached (or if something went wrong)
+func (this *SortedSetPager) Next() <-chan bool {
+	out := make(chan bool, 1)
+	if this.finished || this.size <= 0 {
+		this.page = nil
+		out <- false
+		close(out)
+		return out
+	}
+
+	op := "zrange"
+	if this.reversed {
+		op = "zrevrange"
+	}
+	in := scoredMembersChannel(SliceCommand(this.set, this.set.args(op, itoa(this.offset), itoa(this.offset+this.size-1), "WITHSCORES")...))
+	go func() {
+		defer close(out)
+		page, ok := <-in
+		if !ok || len(page) == 0 {
+			this.finished = true
+			this.page = nil
+			out <- false
+			return
+		}
+		if len(page) < this.size {
+			//this was the last page, so there's no need to ask for another
+			this.finished = true
+		}
+		this.page = page
+		this.offset += len(page)
+		out <- true
+	}()
+	return out
+}
+
+//Page returns the page Next fetched
+func (this *SortedSetPager) Page() []ScoredMember {
+	return this.page
+}
+
+//Offset returns the index the next page will start at
+func (this *SortedSetPager) Offset() int {
+	return this.offset
+}
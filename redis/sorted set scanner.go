@@ -0,0 +1,84 @@
+package redis
+
+//A SortedSetScanner goes through the members of a zset a batch at a time with ZSCAN, so that even a huge zset never blocks redis.
+//Like every SCAN, members added or removed while scanning may or may not be seen, and a member can occasionally be seen more than once
+type SortedSetScanner struct {
+	set     SortedSet
+	match   string
+	count   int
+	keys    *scanner
+	batch   []ScoredMember
+	current ScoredMember
+}
+
+//Scan creates a SortedSetScanner over this zset; call Next to get to the first member.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this SortedSet) Scan() *SortedSetScanner {
+	return &SortedSetScanner{set: this}
+}
+
+//Match limits the scan to members that match the glob-style pattern.
+//It should be called before the first Next
+func (this *SortedSetScanner) Match(pattern string) *SortedSetScanner {
+	this.match = pattern
+	return this
+}
+
+//Count hints to redis how many members to look at for each batch.
+//It should be called before the first Next
+func (this *SortedSetScanner) Count(hint int) *SortedSetScanner {
+	this.count = hint
+	return this
+}
+
+//ZSCAN command - 
+//Next moves on to the next member, fetching another batch from redis when needed;
+//returns false once every member has been gone through (or if something went wrong)
+func (this *SortedSetScanner) Next() <-chan bool {
+	out := make(chan bool, 1)
+	go func() {
+		defer close(out)
+		if this.keys == nil {
+			this.keys = newScanner(this.set, this.args)
+		}
+
+		for len(this.batch) == 0 {
+			batch, ok := this.keys.next()
+			if !ok {
+				out <- false
+				return
+			}
+			//the reply interleaves each member with its score
+			for i := 0; i+1 < len(batch); i += 2 {
+				if score, err := atof(batch[i+1]); err == nil {
+					this.batch = append(this.batch, ScoredMember{batch[i], score})
+				}
+			}
+		}
+
+		this.current, this.batch = this.batch[0], this.batch[1:]
+		out <- true
+	}()
+	return out
+}
+
+//Member returns the member Next moved on to
+func (this *SortedSetScanner) Member() string {
+	return this.current.Member
+}
+
+//Score returns the score of the member Next moved on to
+func (this *SortedSetScanner) Score() float64 {
+	return this.current.Score
+}
+
+func (this *SortedSetScanner) args(cursor string) []string {
+	args := []string{cursor}
+	if this.match != "" {
+		args = append(args, "MATCH", this.match)
+	}
+	if this.count > 0 {
+		args = append(args, "COUNT", itoa(this.count))
+	}
+	return this.set.args("zscan", args...)
+}
@@ -0,0 +1,69 @@
+package redis
+
+//A ShadowedSortedSet is a zset that also records every member added to it in a HyperLogLog (its shadow).
+//The shadows of several zsets can be counted together to get an estimate of how many unique members they have between them,
+//without having to union the zsets themselves.
+//Only Add keeps the shadow up to date; anything added through the other SortedSet methods isn't counted.
+//Members are never taken out of the shadow, so it counts every member that has ever been added, even after they are removed
+type ShadowedSortedSet struct {
+	SortedSet
+	shadow Key
+}
+
+//WithHLLShadow creates a ShadowedSortedSet which records members in the HyperLogLog at hllKey as they are added.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this SortedSet) WithHLLShadow(hllKey string) ShadowedSortedSet {
+	return ShadowedSortedSet{
+		SortedSet: this,
+		shadow:    newKey(this.client, hllKey),
+	}
+}
+
+const shadowedAddScript = `
+local added = redis.call('ZADD', KEYS[1], ARGV[1], ARGV[2])
+redis.call('PFADD', KEYS[2], ARGV[2])
+return added
+`
+
+//ZADD and PFADD commands (within a lua script) - 
+//Add adds a member to the zset (or updates its score if it already exists) and records it in the shadow, atomically;
+//returns true when adding, false when updating
+func (this ShadowedSortedSet) Add(item string, score float64) <-chan bool {
+	return BoolCommand(this, evalArgs(shadowedAddScript, []string{this.key, this.shadow.key}, ftoa(score), item)...)
+}
+
+//PFCOUNT command - 
+//ApproxUnique returns an estimate of how many unique members have ever been added to this zset
+func (this ShadowedSortedSet) ApproxUnique() <-chan int {
+	return IntCommand(this.shadow, this.shadow.args("pfcount")...)
+}
+
+//Use allows you to use this zset on a different executor
+func (this ShadowedSortedSet) Use(e SafeExecutor) ShadowedSortedSet {
+	this.SortedSet = this.SortedSet.Use(e)
+	this.shadow = this.shadow.Use(e)
+	return this
+}
+
+//PFCOUNT command - 
+//ApproxUniqueAcross returns an estimate of how many unique members have been added across all of the zsets given.
+//HyperLogLogs have a standard error of 0.81%, so this shouldn't be used where an exact answer is needed
+func (this *Client) ApproxUniqueAcross(boards ...ShadowedSortedSet) <-chan int {
+	return approxUniqueAcross(this, boards)
+}
+
+func approxUniqueAcross(e SafeExecutor, boards []ShadowedSortedSet) <-chan int {
+	if len(boards) == 0 {
+		out := make(chan int, 1)
+		out <- 0
+		close(out)
+		return out
+	}
+
+	args := make([]string, 0, len(boards)+1)
+	args = append(args, "PFCOUNT")
+	for _, board := range boards {
+		args = append(args, board.shadow.key)
+	}
+	return IntCommand(e, args...)
+}
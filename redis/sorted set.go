@@ -1,12 +1,24 @@
 package redis
 
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
 type SortedSet struct {
 	SortableKey
 }
 
 func newSortedSet(client SafeExecutor, key string) SortedSet {
 	return SortedSet{
-		newSortableKey(client, key),
+		SortableKey: newSortableKey(client, key, KeyTypeSortedSet),
 	}
 }
 
@@ -27,6 +39,131 @@ func (this SortedSet) Add(item string, score float64) <-chan bool {
 	return BoolCommand(this, this.args("zadd", ftoa(score), item)...)
 }
 
+const setScoreIfChangedScript = `
+local current = redis.call('ZSCORE', KEYS[1], ARGV[2])
+if current and tonumber(current) == tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[1], ARGV[2])
+return 1
+`
+
+//ZSCORE and ZADD commands (within a lua script) - 
+//SetScoreIfChanged sets the score of a member (adding it if it isn't a member yet), but only issues the ZADD when the score is actually different,
+//so that jobs which rewrite mostly unchanged scores don't send those no-op writes on to replicas and the AOF.
+//The scores are compared as exact floats, so one that differs only by float drift still counts as changed (see AddRounded to avoid that);
+//returns whether or not anything was written
+func (this SortedSet) SetScoreIfChanged(member string, score float64) <-chan bool {
+	return BoolCommand(this, evalArgs(setScoreIfChangedScript, []string{this.key}, ftoa(score), member)...)
+}
+
+//ZADD command - 
+//AddRounded adds a member to a zset or updates its score the same way as Add, but rounds the score to a number of decimal places first.
+//The score is rounded to the nearest multiple of 10^-decimals, with halfway values rounded away from zero (so 2.5 becomes 3 and -2.5 becomes -3 when decimals is 0).
+//A negative number of decimals rounds to the left of the decimal point instead, so -2 rounds to the nearest hundred.
+//This keeps float drift (ie, 19.999999998 instead of 20) from ever reaching redis
+func (this SortedSet) AddRounded(item string, score float64, decimals int) <-chan bool {
+	return this.Add(item, roundTo(score, decimals))
+}
+
+//An AddFlag changes when ZADD adds or updates a member; see AddWith
+type AddFlag string
+
+const (
+	AddOnlyNew      AddFlag = "NX" //only add new members, never update existing ones
+	AddOnlyExisting AddFlag = "XX" //only update existing members, never add new ones
+	AddOnlyGreater  AddFlag = "GT" //only update a score when the new one is greater (new members are still added)
+	AddOnlyLess     AddFlag = "LT" //only update a score when the new one is lower (new members are still added)
+	AddChanged      AddFlag = "CH" //count updated members as well as added ones
+)
+
+//errAddFlags is given when AddWith is given flags that can't be used together
+var errAddFlags = errors.New("ZADD flags NX, XX, GT and LT can't be combined that way")
+
+//ZADD command - 
+//AddWith adds a member to a zset or updates its score, the same as Add, but only in the cases the flags allow.
+//NX can't be used with XX, GT or LT, and GT can't be used with LT; the command isn't sent with flags like those, and the error goes to the error callback instead.
+//Returns true when the member was added (or with CH, when it was added or its score changed)
+func (this SortedSet) AddWith(item string, score float64, flags ...AddFlag) <-chan bool {
+	used := make(map[AddFlag]bool, len(flags))
+	args := make([]string, 0, len(flags)+2)
+	for _, flag := range flags {
+		if !used[flag] {
+			used[flag] = true
+			args = append(args, string(flag))
+		}
+	}
+	if (used[AddOnlyNew] && (used[AddOnlyExisting] || used[AddOnlyGreater] || used[AddOnlyLess])) || (used[AddOnlyGreater] && used[AddOnlyLess]) {
+		return BoolCommand(failingExecutor{this.client, errAddFlags}, this.args("zadd", append(args, ftoa(score), item)...)...)
+	}
+	return BoolCommand(this, this.args("zadd", append(args, ftoa(score), item)...)...)
+}
+
+//ZADD NX command - 
+//AddIfAbsent adds a member to a zset, but leaves its score alone if it is already a member;
+//returns whether or not it was added
+func (this SortedSet) AddIfAbsent(item string, score float64) <-chan bool {
+	return this.AddWith(item, score, AddOnlyNew)
+}
+
+//ZADD XX CH command - 
+//AddIfPresent updates the score of a member of a zset, but never adds it if it isn't already a member;
+//returns whether or not its score changed
+func (this SortedSet) AddIfPresent(item string, score float64) <-chan bool {
+	return this.AddWith(item, score, AddOnlyExisting, AddChanged)
+}
+
+//ZADD GT CH command - 
+//AddIfGreater adds a member to a zset, or updates its score only if the new score is greater, so scores only ever climb;
+//returns whether or not it was added or its score changed
+func (this SortedSet) AddIfGreater(item string, score float64) <-chan bool {
+	return this.AddWith(item, score, AddOnlyGreater, AddChanged)
+}
+
+//ZADD LT CH command - 
+//AddIfLess adds a member to a zset, or updates its score only if the new score is lower;
+//returns whether or not it was added or its score changed
+func (this SortedSet) AddIfLess(item string, score float64) <-chan bool {
+	return this.AddWith(item, score, AddOnlyLess, AddChanged)
+}
+
+//AddResult describes what happened when a member was added to a zset
+type AddResult struct {
+	WasNew        bool    //whether the member was added, rather than updated
+	PreviousScore float64 //the score the member had before being updated
+	HadPrevious   bool    //whether or not there is a PreviousScore
+}
+
+const addReportingScript = `
+local previous = redis.call('ZSCORE', KEYS[1], ARGV[2])
+redis.call('ZADD', KEYS[1], ARGV[1], ARGV[2])
+if previous then
+	return {0, previous}
+end
+return {1}
+`
+
+//ZSCORE and ZADD commands (within a lua script) - 
+//AddReporting adds a member to a zset or updates its score if it already exists;
+//returns whether it was added, and if it was updated instead, what its previous score was
+func (this SortedSet) AddReporting(item string, score float64) <-chan AddResult {
+	out := make(chan AddResult, 1)
+	in := SliceCommand(this, evalArgs(addReportingScript, []string{this.key}, ftoa(score), item)...)
+	go func() {
+		defer close(out)
+		if res, ok := <-in; ok && len(res) > 0 {
+			if res[0] == "1" {
+				out <- AddResult{WasNew: true}
+			} else if len(res) == 2 {
+				if previous, err := atof(res[1]); err == nil {
+					out <- AddResult{PreviousScore: previous, HadPrevious: true}
+				}
+			}
+		}
+	}()
+	return out
+}
+
 //ZINCRBY command - 
 //IncrementBy adjusts the score of the member within the zset;
 //returns the new score
@@ -34,6 +171,265 @@ func (this SortedSet) IncrementBy(item string, score float64) <-chan float64 {
 	return FloatCommand(this, this.args("zincrby", ftoa(score), item)...)
 }
 
+//IncrResult is the outcome of IncrementByChecked
+type IncrResult struct {
+	Score      float64 //	the new score, or the unchanged score if the increment overflowed
+	Overflowed bool
+}
+
+//ARGV[1] is the ceiling (empty for none), followed by pairs of increments and members;
+//an empty increment is one that can't be handed to lua (ie, infinite), which would always overflow anyway
+const incrementCheckedScript = `
+local ceiling = tonumber(ARGV[1])
+local results = {}
+for i = 2, #ARGV, 2 do
+	local current = redis.call('ZSCORE', KEYS[1], ARGV[i+1]) or '0'
+	local result = ARGV[i] ~= '' and tonumber(current) + tonumber(ARGV[i])
+	if not result or result ~= result or result == math.huge or result == -math.huge or (ceiling and result > ceiling) then
+		table.insert(results, current)
+		table.insert(results, 1)
+	else
+		table.insert(results, redis.call('ZINCRBY', KEYS[1], ARGV[i], ARGV[i+1]))
+		table.insert(results, 0)
+	end
+end
+return results
+`
+
+//ZINCRBY command (within a lua script) - 
+//IncrementByChecked adjusts the score of the member within the zset, unless the new score would be infinite;
+//returns the new score, or if the increment was refused, the score it still has along with Overflowed set.
+//Once a score reaches infinity it can't be ranked against other infinite scores, so this lets the overflow be dealt with instead
+func (this SortedSet) IncrementByChecked(item string, score float64) <-chan IncrResult {
+	return this.IncrementByCheckedUpTo(item, score, math.Inf(1))
+}
+
+//ZINCRBY command (within a lua script) - 
+//IncrementByCheckedUpTo works the same way as IncrementByChecked, but also refuses the increment (with Overflowed set) if the new score would be above the ceiling;
+//a score can be incremented right up to the ceiling.
+//Unlike IncrementCapped, which stores the ceiling in place of anything above it, nothing is stored, so the app can decide what to do
+func (this SortedSet) IncrementByCheckedUpTo(item string, score, ceiling float64) <-chan IncrResult {
+	in := this.IncrementManyCheckedUpTo(map[string]float64{item: score}, ceiling)
+	out := make(chan IncrResult, 1)
+	go func() {
+		defer close(out)
+		if res, ok := <-in; ok {
+			out <- res[item]
+		}
+	}()
+	return out
+}
+
+//ZINCRBY commands (within a lua script) - 
+//IncrementManyChecked works the same way as IncrementByChecked, but adjusts the scores of every member given, all at once;
+//returns the outcome for each member, so the ones that overflowed are refused without stopping the rest
+func (this SortedSet) IncrementManyChecked(deltas map[string]float64) <-chan map[string]IncrResult {
+	return this.IncrementManyCheckedUpTo(deltas, math.Inf(1))
+}
+
+//ZINCRBY commands (within a lua script) - 
+//IncrementManyCheckedUpTo works the same way as IncrementManyChecked, but also refuses any increment that would take a score above the ceiling (see IncrementByCheckedUpTo)
+func (this SortedSet) IncrementManyCheckedUpTo(deltas map[string]float64, ceiling float64) <-chan map[string]IncrResult {
+	members := make([]string, 0, len(deltas))
+	for member := range deltas {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+
+	args := make([]string, 0, 2*len(members)+1)
+	if math.IsInf(ceiling, 1) || math.IsNaN(ceiling) {
+		args = append(args, "")
+	} else {
+		args = append(args, ftoa(ceiling))
+	}
+	for _, member := range members {
+		delta := ""
+		if !math.IsInf(deltas[member], 0) && !math.IsNaN(deltas[member]) {
+			delta = ftoa(deltas[member])
+		}
+		args = append(args, delta, member)
+	}
+
+	in := SliceCommand(this, evalArgs(incrementCheckedScript, []string{this.key}, args...)...)
+	out := make(chan map[string]IncrResult, 1)
+	go func() {
+		defer close(out)
+		res, ok := <-in
+		if !ok || len(res) != 2*len(members) {
+			return
+		}
+		results := make(map[string]IncrResult, len(members))
+		for i, member := range members {
+			score, err := atof(res[2*i])
+			if err != nil {
+				return
+			}
+			results[member] = IncrResult{score, res[2*i+1] == "1"}
+		}
+		out <- results
+	}()
+	return out
+}
+
+//ZADD GT INCR command - 
+//IncrementIfGreater adjusts the score of the member within the zset, but only if that would make the score greater (or the member is new);
+//returns the new score, and whether or not the increment was applied.
+//When the increment is rejected, the score channel closes without a value
+func (this SortedSet) IncrementIfGreater(item string, score float64) (<-chan float64, <-chan bool) {
+	return appliedFloatChannels(FloatCommand(this, this.args("zadd", "GT", "INCR", ftoa(score), item)...))
+}
+
+//ZADD LT INCR command - 
+//IncrementIfLess adjusts the score of the member within the zset, but only if that would make the score lower (or the member is new);
+//returns the new score, and whether or not the increment was applied.
+//When the increment is rejected, the score channel closes without a value
+func (this SortedSet) IncrementIfLess(item string, score float64) (<-chan float64, <-chan bool) {
+	return appliedFloatChannels(FloatCommand(this, this.args("zadd", "LT", "INCR", ftoa(score), item)...))
+}
+
+const incrementCappedScript = `
+local score = redis.call('ZINCRBY', KEYS[1], ARGV[1], ARGV[3])
+if tonumber(score) > tonumber(ARGV[2]) then
+	redis.call('ZADD', KEYS[1], ARGV[2], ARGV[3])
+	return ARGV[2]
+end
+return score
+`
+
+//ZADD XX INCR command - 
+//IncrementIfExists adjusts the score of the member within the zset, but only if it is already a member, so it never creates one;
+//returns the new score, or closes the channel without a value if it wasn't a member (the same as if the command failed - use IncrementIfPresent to tell the two apart)
+func (this SortedSet) IncrementIfExists(item string, score float64) <-chan float64 {
+	in := this.IncrementIfPresent(item, score)
+	out := make(chan float64, 1)
+	go func() {
+		defer close(out)
+		if res, ok := <-in; ok && res != nil {
+			out <- *res
+		}
+	}()
+	return out
+}
+
+//ZADD XX INCR command - 
+//IncrementIfPresent works the same way as IncrementIfExists, but sends nil instead of closing the channel when the member doesn't exist,
+//so the channel only closes without a value when the command failed
+func (this SortedSet) IncrementIfPresent(item string, delta float64) <-chan *float64 {
+	return maybeFloatChannel(ReplyCommand(this, this.args("zadd", "XX", "INCR", ftoa(delta), item)...))
+}
+
+//ZADD NX INCR command - 
+//IncrementIfAbsent adds the member with a score of delta, but only if it isn't already a member, so it never changes an existing score;
+//returns the new score, or nil if it was already a member
+func (this SortedSet) IncrementIfAbsent(item string, delta float64) <-chan *float64 {
+	return maybeFloatChannel(ReplyCommand(this, this.args("zadd", "NX", "INCR", ftoa(delta), item)...))
+}
+
+//ZINCRBY command (within a lua script) - 
+//IncrementCapped adjusts the score of the member within the zset, but never lets the score go above "ceiling";
+//returns the new score, which will be "ceiling" if the increment would have gone over it.
+//This all happens atomically within redis, so it is safe to use from multiple clients at once
+func (this SortedSet) IncrementCapped(item string, score, ceiling float64) <-chan float64 {
+	return FloatCommand(this, evalArgs(incrementCappedScript, []string{this.key}, ftoa(score), ftoa(ceiling), item)...)
+}
+
+const recordBestScript = `
+local before = redis.call('ZREVRANGE', KEYS[1], 0, -1)
+local ranks = {}
+for rank, member in ipairs(before) do
+	ranks[member] = rank
+end
+for i = 1, #ARGV, 2 do
+	redis.call('ZADD', KEYS[1], 'GT', 'CH', ARGV[i], ARGV[i+1])
+end
+local after = redis.call('ZREVRANGE', KEYS[1], 0, -1)
+local moved = {}
+for rank, member in ipairs(after) do
+	if ranks[member] ~= rank then
+		table.insert(moved, member)
+	end
+end
+return moved
+`
+
+//ZADD GT CH command (within a lua script) - 
+//RecordBest sets the scores of all of the members, but only where that would raise their score (or they are new);
+//returns every member whose reverse index changed as a result (including new members), in reverse index order.
+//ie, if you are displaying the zset as a leaderboard, these are the rows that need to be updated.
+//This compares the entire zset before and after the update, so it will be slow on very large zsets
+func (this SortedSet) RecordBest(members map[string]float64) <-chan []string {
+	args := make([]string, 0, 2*len(members))
+	for member, score := range members {
+		args = append(args, ftoa(score), member)
+	}
+	return SliceCommand(this, evalArgs(recordBestScript, []string{this.key}, args...)...)
+}
+
+const resetScoresScript = `
+local members = redis.call('ZRANGE', KEYS[1], 0, -1)
+local changed = 0
+local batch = {}
+for _, member in ipairs(members) do
+	batch[#batch + 1] = ARGV[1]
+	batch[#batch + 1] = member
+	if #batch >= 2000 then
+		changed = changed + redis.call('ZADD', KEYS[1], 'XX', 'CH', unpack(batch))
+		batch = {}
+	end
+end
+if #batch > 0 then
+	changed = changed + redis.call('ZADD', KEYS[1], 'XX', 'CH', unpack(batch))
+end
+return changed
+`
+
+//ZRANGE and ZADD XX CH commands (within a lua script) - 
+//ResetScores sets the score of every member of the zset to the score given, keeping all of the members (ie, for the start of a new season);
+//returns how many members had their score changed.
+//This happens atomically, so it blocks redis while it goes through every member - fine for small to medium zsets, but slow on huge ones
+func (this SortedSet) ResetScores(to float64) <-chan int {
+	return IntCommand(this, evalArgs(resetScoresScript, []string{this.key}, ftoa(to))...)
+}
+
+const initializeIfAbsentScript = `
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+local batch = {}
+for i = 1, #ARGV, 2 do
+	batch[#batch + 1] = ARGV[i]
+	batch[#batch + 1] = ARGV[i+1]
+	if #batch >= 2000 then
+		redis.call('ZADD', KEYS[1], unpack(batch))
+		batch = {}
+	end
+end
+if #batch > 0 then
+	redis.call('ZADD', KEYS[1], unpack(batch))
+end
+return 1
+`
+
+//EXISTS and ZADD commands (within a lua script) - 
+//InitializeIfAbsent seeds the zset with the members and scores given, but only if the key doesn't exist yet;
+//returns whether or not it seeded the zset.
+//The check and the seeding happen atomically, so when several clients try to initialize the same zset, only one of them will
+func (this SortedSet) InitializeIfAbsent(members map[string]float64) <-chan bool {
+	if len(members) == 0 {
+		//redis doesn't keep empty zsets, so there's nothing that could be seeded
+		out := make(chan bool, 1)
+		out <- false
+		close(out)
+		return out
+	}
+
+	args := make([]string, 0, 2*len(members))
+	for member, score := range members {
+		args = append(args, ftoa(score), member)
+	}
+	return BoolCommand(this, evalArgs(initializeIfAbsentScript, []string{this.key}, args...)...)
+}
+
 //ZREM command - 
 //Remove removes a member from the zset if it is part of the set;
 //returns whether or not it was part of the set
@@ -41,12 +437,80 @@ func (this SortedSet) Remove(item string) <-chan bool {
 	return BoolCommand(this, this.args("zrem", item)...)
 }
 
+//ZREM command - 
+//RemoveMany removes all of the members from the zset, splitting them up into several ZREMs if there are more than the MaxArgsPerCommand;
+//returns how many of them were part of the set
+func (this SortedSet) RemoveMany(items ...string) <-chan int {
+	return this.bulk("zrem", 1, 0, items)
+}
+
+//ZPOPMIN command - 
+//PopMin removes and returns up to count of the lowest scoring members of the zset, lowest first
+func (this SortedSet) PopMin(count int) <-chan []ScoredMember {
+	return scoredMembersChannel(SliceCommand(this, this.args("zpopmin", itoa(count))...))
+}
+
+//ZPOPMAX command - 
+//PopMax removes and returns up to count of the highest scoring members of the zset, highest first
+func (this SortedSet) PopMax(count int) <-chan []ScoredMember {
+	return scoredMembersChannel(SliceCommand(this, this.args("zpopmax", itoa(count))...))
+}
+
+//BZPOPMIN command - 
+//BlockingPopMin pops the lowest scoring member from the first of the zsets that has any members, waiting up to timeout for one of them to get some (0 waits forever).
+//The timeout is sent in whole seconds (redis before 6.0 doesn't accept anything else), so it is rounded up to the next second.
+//The popped member says which zset it came from; if the timeout passes first, the channel closes without a value.
+//The command is issued on the first zset's executor, so all the zsets must be on the same client
+func BlockingPopMin(timeout time.Duration, sets ...SortedSet) <-chan PoppedMember {
+	return blockingPop("BZPOPMIN", timeout, sets)
+}
+
+//BZPOPMAX command - 
+//BlockingPopMax works the same way as BlockingPopMin, but pops the highest scoring member instead
+func BlockingPopMax(timeout time.Duration, sets ...SortedSet) <-chan PoppedMember {
+	return blockingPop("BZPOPMAX", timeout, sets)
+}
+
+func blockingPop(op string, timeout time.Duration, sets []SortedSet) <-chan PoppedMember {
+	out := make(chan PoppedMember, 1)
+	if len(sets) == 0 {
+		close(out)
+		return out
+	}
+
+	args := make([]string, 0, len(sets)+2)
+	args = append(args, op)
+	keys := make([]Key, len(sets))
+	for i, set := range sets {
+		args = append(args, set.key)
+		keys[i] = set.Key
+	}
+	args = append(args, wholeSeconds(timeout))
+
+	in := SliceCommand(crossShardGuard(keys[0], keys[1:]...), args...)
+	go func() {
+		defer close(out)
+		if res, ok := <-in; ok && len(res) == 3 {
+			if score, err := atof(res[2]); err == nil {
+				out <- PoppedMember{res[0], res[1], score}
+			}
+		}
+	}()
+	return out
+}
+
 //ZCARD command - 
 //Size returns the number of members of the zset
 func (this SortedSet) Size() <-chan int {
 	return IntCommand(this, this.args("zcard")...)
 }
 
+//ZCARD command - 
+//IsEmpty returns whether or not there are no members in this zset
+func (this SortedSet) IsEmpty() <-chan bool {
+	return isZeroChannel(this.Size())
+}
+
 //ZRANK command - 
 //IndexOf returns the index of a member.
 //ie, the lowest ranked member would have an index of 0, and the next lowest an index of 1
@@ -67,6 +531,53 @@ func (this SortedSet) ScoreOf(item string) <-chan float64 {
 	return FloatCommand(this, this.args("zscore", item)...)
 }
 
+//ZRANDMEMBER command - 
+//RandomMember returns a random member of the zset
+func (this SortedSet) RandomMember() <-chan string {
+	return StringCommand(this, this.args("zrandmember")...)
+}
+
+//ZRANDMEMBER command - 
+//RandomMembers returns random members of the zset.
+//A positive count returns up to count distinct members (the whole zset if it is smaller than that);
+//a negative count returns exactly -count members, which may include the same member more than once
+func (this SortedSet) RandomMembers(count int) <-chan []string {
+	return SliceCommand(this, this.args("zrandmember", itoa(count))...)
+}
+
+//ZRANDMEMBER command - 
+//RandomMembersWithScores returns random members of the zset along with their scores, picked the same way as RandomMembers.
+//A member picked more than once with a negative count only shows up once in the map
+func (this SortedSet) RandomMembersWithScores(count int) <-chan map[string]float64 {
+	return stringfloatMapChannel(MapCommand(this, this.args("zrandmember", itoa(count), "WITHSCORES")...))
+}
+
+//ZCOUNT command - 
+//WouldRankAt returns the index a new member with the given score would have, without adding anything (ie, to preview where a score would land).
+//Members already at that exact score are ordered by name, so the index is the first the new member could get - the number of members with a lower score
+func (this SortedSet) WouldRankAt(score float64) <-chan int {
+	return IntCommand(this, this.args("zcount", "-inf", scoreBound(score, true))...)
+}
+
+//ZCOUNT command - 
+//WouldReverseRankAt is the same as WouldRankAt, but gives the reverse index instead - the number of members with a higher score
+func (this SortedSet) WouldReverseRankAt(score float64) <-chan int {
+	return IntCommand(this, this.args("zcount", scoreBound(score, true), "+inf")...)
+}
+
+//ZMSCORE command - 
+//ScoresOf looks up the score of every member given, all in a single round trip;
+//the scores are in the same order as the members, with nil for any member that isn't in the zset
+func (this SortedSet) ScoresOf(members ...string) <-chan []*float64 {
+	if len(members) == 0 {
+		out := make(chan []*float64, 1)
+		out <- []*float64{}
+		close(out)
+		return out
+	}
+	return maybeFloatsChannel(MaybeSliceCommand(this, this.args("zmscore", members...)...))
+}
+
 //ZRANGE command - 
 //IndexedBetween returns a slice of all members between the indices
 func (this SortedSet) IndexedBetween(start, stop int) <-chan []string {
@@ -93,6 +604,193 @@ func (this SortedSet) ReverseIndexedBetweenWithScores(start, stop int) <-chan ma
 	return stringfloatMapChannel(MapCommand(this, this.args("zrevrange", itoa(start), itoa(stop), "WITHSCORES")...))
 }
 
+//RankedMember is a member of a zset along with its score and its absolute rank within the zset
+type RankedMember struct {
+	Rank   int
+	Member string
+	Score  float64
+}
+
+//ZREVRANK and ZREVRANGE commands - 
+//NeighborsOf returns the member along with the members up to radius places above and below it in reverse index order, with their scores and absolute ranks;
+//ie, "you and the players just above and below you" on a leaderboard.
+//The window is clipped at the top and bottom of the zset, so it may hold fewer than 2*radius+1 members.
+//If the member isn't in the zset, the channel closes without a value.
+//The rank and the range are looked up separately, so if the zset changes in between the window may be slightly off-center
+func (this SortedSet) NeighborsOf(member string, radius int) <-chan []RankedMember {
+	if radius < 0 {
+		radius = 0
+	}
+	rank := this.ReverseIndexOf(member)
+	out := make(chan []RankedMember, 1)
+	go func() {
+		defer close(out)
+		r, ok := <-rank
+		if !ok {
+			return
+		}
+		start := r - radius
+		if start < 0 {
+			start = 0
+		}
+		if neighbors, ok := <-this.RankedSlice(start, r+radius, true); ok {
+			out <- neighbors
+		}
+	}()
+	return out
+}
+
+//ZRANK WITHSCORE command (pipelined) - 
+//RanksAndScoresOf looks up the index and score of every member given, all in a single round trip;
+//returns them in the same order as the members were given.
+//Members that aren't part of the zset get a Rank of -1 (and a Score of 0).
+//This needs redis 7.2 or later
+func (this SortedSet) RanksAndScoresOf(members ...string) <-chan []RankedMember {
+	return this.ranksAndScoresOf("zrank", members)
+}
+
+//ZREVRANK WITHSCORE command (pipelined) - 
+//ReverseRanksAndScoresOf is the same as RanksAndScoresOf, but gives each member's reverse index instead;
+//ie, where they would be on a leaderboard
+func (this SortedSet) ReverseRanksAndScoresOf(members ...string) <-chan []RankedMember {
+	return this.ranksAndScoresOf("zrevrank", members)
+}
+
+func (this SortedSet) ranksAndScoresOf(op string, members []string) <-chan []RankedMember {
+	responses := make([]<-chan *response, len(members))
+	out := make(chan []RankedMember, 1)
+	pipelinedInBackground(this.client, func(e SafeExecutor) {
+		for i, member := range members {
+			responses[i] = rawResponse(e, this.args(op, member, "WITHSCORE")...)
+		}
+	}, func() {
+		defer close(out)
+		ranked := make([]RankedMember, len(members))
+		for i, member := range members {
+			ranked[i] = RankedMember{Rank: -1, Member: member}
+			res, ok := <-responses[i]
+			if !ok || len(res.subresponses) != 2 || res.subresponses[0] == nil || res.subresponses[1] == nil {
+				continue
+			}
+			rank, err := atoi(res.subresponses[0].val)
+			if err != nil {
+				continue
+			}
+			score, err := atof(res.subresponses[1].val)
+			if err != nil {
+				continue
+			}
+			ranked[i].Rank, ranked[i].Score = rank, score
+		}
+		out <- ranked
+	})
+	return out
+}
+
+//MemberDetail is everything DetailsOf knows about a member: its score, and its reverse rank (where it is on a leaderboard).
+//If the member isn't in the zset, Present is false and the Score and Rank are 0
+type MemberDetail struct {
+	Member  string
+	Score   float64
+	Rank    int
+	Present bool
+}
+
+const detailsOfScript = `
+local details = {}
+for i, member in ipairs(ARGV) do
+	details[2*i-1] = redis.call('ZSCORE', KEYS[1], member)
+	details[2*i] = redis.call('ZREVRANK', KEYS[1], member)
+end
+return details
+`
+
+//ZSCORE and ZREVRANK commands (within a lua script) - 
+//DetailsOf looks up the score and reverse rank of every member given, in the same order as they were given.
+//Unlike ReverseRanksAndScoresOf, it all happens in one script, so every rank comes from the same snapshot of the zset
+//(nothing can change it part way through); that keeps a multi-row view from showing two members at the same rank
+func (this SortedSet) DetailsOf(members ...string) <-chan []MemberDetail {
+	if len(members) == 0 {
+		out := make(chan []MemberDetail, 1)
+		out <- []MemberDetail{}
+		close(out)
+		return out
+	}
+	replies := MaybeSliceCommand(this, evalArgs(detailsOfScript, []string{this.key}, members...)...)
+	out := make(chan []MemberDetail, 1)
+	go func() {
+		defer close(out)
+		res, ok := <-replies
+		if !ok {
+			return
+		}
+		details := make([]MemberDetail, len(members))
+		for i, member := range members {
+			details[i].Member = member
+			if 2*i+1 >= len(res) || res[2*i] == nil || res[2*i+1] == nil {
+				continue
+			}
+			score, err := atof(*res[2*i])
+			if err != nil {
+				continue
+			}
+			rank, err := atoi(*res[2*i+1])
+			if err != nil {
+				continue
+			}
+			details[i].Score, details[i].Rank, details[i].Present = score, rank, true
+		}
+		out <- details
+	}()
+	return out
+}
+
+//ZRANGE or ZREVRANGE command - 
+//RankedSlice returns all members between the indices along with their scores and absolute ranks.
+//If reversed, the indices (and ranks) count from the highest score down, which is what you would want for a leaderboard.
+//Negative indices work as they do for IndexedBetween, but need an extra round trip to work out the ranks
+func (this SortedSet) RankedSlice(start, stop int, reversed bool) <-chan []RankedMember {
+	op := "zrange"
+	if reversed {
+		op = "zrevrange"
+	}
+	members := scoredMembersChannel(SliceCommand(this, this.args(op, itoa(start), itoa(stop), "WITHSCORES")...))
+
+	first := make(chan int, 1)
+	if start >= 0 {
+		first <- start
+		close(first)
+		return rankedMembersChannel(members, first)
+	}
+
+	//negative indices count back from the end, so the size is needed to know the actual rank of the first member
+	size := this.Size()
+	go func() {
+		defer close(first)
+		if n, ok := <-size; ok {
+			if n+start < 0 {
+				first <- 0
+			} else {
+				first <- n + start
+			}
+		}
+	}()
+	return rankedMembersChannel(members, first)
+}
+
+//ZRANGESTORE command - 
+//StoreRangeInto copies the members between the indices start and stop (inclusive, negative indices count from the end) into dest, replacing whatever dest had;
+//returns the number of members stored.
+//This needs redis 6.2 or later
+func (this SortedSet) StoreRangeInto(dest SortedSet, start, stop int) <-chan int {
+	return rangeStore(this.Key, dest, itoa(start), itoa(stop))
+}
+
+//rangeStore issues a ZRANGESTORE from the source into dest - unlike most commands, the destination comes first
+func rangeStore(source Key, dest SortedSet, args ...string) <-chan int {
+	return IntCommand(crossShardGuard(source, dest.Key), append([]string{"ZRANGESTORE", dest.key, source.key}, args...)...)
+}
+
 //ZREMRANGEBYRANK command - 
 //RemoveIndexedBetween removes all members between the indices;
 //returns the number of members removed
@@ -100,10 +798,182 @@ func (this SortedSet) RemoveIndexedBetween(start, stop int) <-chan int {
 	return IntCommand(this, this.args("zremrangebyrank", itoa(start), itoa(stop))...)
 }
 
+//scoreBounds keeps track of the lowest and highest scores a range can include
+type scoreBounds struct {
+	min, max       string
+	fmin, fmax     float64
+	hasMin, hasMax bool //whether or not fmin and fmax have been set yet; until then, the range is unbounded
+}
+
+func newScoreBounds() scoreBounds {
+	return scoreBounds{
+		min: "-inf",
+		max: "+inf",
+	}
+}
+
+func scoreBound(score float64, exclusive bool) string {
+	var bound string
+	switch {
+	case math.IsInf(score, 1):
+		bound = "+inf"
+	case math.IsInf(score, -1):
+		bound = "-inf"
+	default:
+		bound = ftoa(score)
+	}
+	if exclusive {
+		return "(" + bound
+	}
+	return bound
+}
+
+//the lower bound only ever gets tighter - when two are the same, the exclusive one is tighter
+func (this *scoreBounds) raiseMin(min float64, exclusive bool) {
+	if !this.hasMin || min > this.fmin || (min == this.fmin && exclusive) {
+		this.hasMin = true
+		this.fmin = min
+		this.min = scoreBound(min, exclusive)
+	}
+}
+
+//the upper bound only ever gets tighter - when two are the same, the exclusive one is tighter
+func (this *scoreBounds) lowerMax(max float64, exclusive bool) {
+	if !this.hasMax || max < this.fmax || (max == this.fmax && exclusive) {
+		this.hasMax = true
+		this.fmax = max
+		this.max = scoreBound(max, exclusive)
+	}
+}
+
+//ZRANGE command - 
+//SnapshotJSON returns the entire zset as a JSON array of {"member":...,"score":...} objects, in index order.
+//This is meant for archiving zsets outside of redis
+func (this SortedSet) SnapshotJSON() <-chan []byte {
+	out := make(chan []byte, 1)
+	members := scoredMembersChannel(SliceCommand(this, this.args("zrange", "0", "-1", "WITHSCORES")...))
+	go func() {
+		defer close(out)
+		if m, ok := <-members; ok {
+			data, err := json.Marshal(m)
+			if err != nil {
+				this.client.errCallback(err, "SnapshotJSON "+this.key)
+				return
+			}
+			out <- data
+		}
+	}()
+	return out
+}
+
+//the most members that get added by a single ZADD when loading lots of them at once
+const loadBatchSize = 1000
+
+//addScored adds all of the members in batches, without waiting for one batch to finish before sending the next;
+//returns the total number of members that were added rather than updated
+func (this SortedSet) addScored(members []ScoredMember) <-chan int {
+	args := make([]string, 0, 2*len(members))
+	for _, member := range members {
+		args = append(args, ftoa(member.Score), member.Member)
+	}
+	return this.bulk("zadd", 2, loadBatchSize, args)
+}
+
+//ZADD command - 
+//AddMany adds all of the members to the zset (or updates their scores), splitting them up into several ZADDs if there are more than the MaxArgsPerCommand
+//(or more than 1000 members);
+//returns the number of members that were added rather than updated
+func (this SortedSet) AddMany(members ...ScoredMember) <-chan int {
+	return this.addScored(members)
+}
+
+//ZADD and RENAME commands - 
+//ReplaceAll replaces everything in the zset with the members given, all at once:
+//the new members are added to a temporary key first, which is then renamed over this one, so nobody ever sees the zset half replaced (or empty, in between).
+//Returns the new size of the zset. If anything goes wrong while building the new members, the temporary key is deleted and this zset is left alone.
+//Like any RENAME, the zset loses its old TTL
+func (this SortedSet) ReplaceAll(members map[string]float64) <-chan int {
+	out := make(chan int, 1)
+	if len(members) == 0 {
+		deleted := this.Delete()
+		go func() {
+			defer close(out)
+			if _, ok := <-deleted; ok {
+				out <- 0
+			}
+		}()
+		return out
+	}
+
+	scored := make([]ScoredMember, 0, len(members))
+	for member, score := range members {
+		scored = append(scored, ScoredMember{member, score})
+	}
+	temp := newSortedSet(this.client, this.key+":replacing:"+uniqueToken())
+	go func() {
+		defer close(out)
+		//the temporary key is brand new, so anything short of every member being added means a ZADD failed
+		if added := <-temp.addScored(scored); added != len(scored) {
+			<-temp.Delete()
+			return
+		}
+		if _, ok := <-temp.MoveTo(this.Key); !ok {
+			<-temp.Delete()
+			return
+		}
+		out <- len(scored)
+	}()
+	return out
+}
+
+//ZADD command - 
+//LoadJSON adds all of the members from a JSON array of {"member":...,"score":...} objects (such as one created by SnapshotJSON);
+//returns the number of members that were added rather than updated.
+//If the JSON is malformed, nothing is added, and the error is sent to the error callback
+func (this SortedSet) LoadJSON(data []byte) <-chan int {
+	var members []ScoredMember
+	if err := json.Unmarshal(data, &members); err != nil {
+		this.client.errCallback(errors.New("Malformed JSON: "+err.Error()), "LoadJSON "+this.key)
+		c := make(chan int)
+		close(c)
+		return c
+	}
+	return this.addScored(members)
+}
+
+//ZADD command - 
+//LoadCSV adds all of the members from CSV rows of the form "member,score";
+//returns the number of members that were added rather than updated.
+//If any row is malformed, nothing is added, and the error (including which row was malformed) is sent to the error callback
+func (this SortedSet) LoadCSV(r io.Reader) <-chan int {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+
+	members := make([]ScoredMember, 0)
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			var score float64
+			if score, err = atof(strings.TrimSpace(record[1])); err == nil {
+				members = append(members, ScoredMember{record[0], score})
+				continue
+			}
+		}
+
+		this.client.errCallback(errors.New("Malformed CSV row "+itoa(row)+": "+err.Error()), "LoadCSV "+this.key)
+		c := make(chan int)
+		close(c)
+		return c
+	}
+	return this.addScored(members)
+}
+
 //SortedSetRange keeps track of all range arguments being used in a search
 type SortedSetRange struct {
-	min, max      string
-	fmin, fmax    float64
+	scoreBounds
 	limited       bool
 	offset, count int
 	reversed      bool
@@ -111,48 +981,38 @@ type SortedSetRange struct {
 	key Key
 }
 
-//Scores createa a SortedSetRange to help narrow a search to be done later
+//Scores createa a SortedSetRange to help narrow a search to be done later.
+//Bounds can be added in any order, and the range will always be the intersection of all of them:
+//successive lower bounds (Above, AboveOrEqualTo) keep whichever is highest, and successive upper bounds (Below, BelowOrEqualTo) keep whichever is lowest.
+//When an exclusive and inclusive bound are at the same score, the exclusive one wins
 func (this SortedSet) Scores() *SortedSetRange {
 	return &SortedSetRange{
-		min: "-inf",
-		max: "+inf",
-		key: this.Key,
+		scoreBounds: newScoreBounds(),
+		key:         this.Key,
 	}
 }
 
 //Above limits results to members who have a score above "min"
 func (this *SortedSetRange) Above(min float64) *SortedSetRange {
-	if this.min == "-inf" || this.fmin <= min {
-		this.fmin = min
-		this.min = "(" + ftoa(min)
-	}
+	this.raiseMin(min, true)
 	return this
 }
 
 //Below limits results to members who have a score below "max"
 func (this *SortedSetRange) Below(max float64) *SortedSetRange {
-	if this.max == "+inf" || this.fmax >= max {
-		this.fmax = max
-		this.max = "(" + ftoa(max)
-	}
+	this.lowerMax(max, true)
 	return this
 }
 
 //AboveOrEqualTo limits results to members who have a score above or equal to "min"
 func (this *SortedSetRange) AboveOrEqualTo(min float64) *SortedSetRange {
-	if this.min == "-inf" || this.fmin < min {
-		this.fmin = min
-		this.min = ftoa(min)
-	}
+	this.raiseMin(min, false)
 	return this
 }
 
 //BelowOrEqualTo limits results to members who have a score below or equal to "max"
 func (this *SortedSetRange) BelowOrEqualTo(max float64) *SortedSetRange {
-	if this.max == "+inf" || this.fmax > max {
-		this.fmax = max
-		this.max = ftoa(max)
-	}
+	this.lowerMax(max, false)
 	return this
 }
 
@@ -207,6 +1067,21 @@ func (this *SortedSetRange) Get() <-chan []string {
 	return SliceCommand(this.key, this.key.args(op, args...)...)
 }
 
+//ZRANGESTORE BYSCORE command - 
+//StoreInto copies every member fitting the search criteria (including any Limit) into dest, replacing whatever dest had;
+//returns the number of members stored.
+//This needs redis 6.2 or later
+func (this *SortedSetRange) StoreInto(dest SortedSet) <-chan int {
+	args := []string{this.min, this.max, "BYSCORE"}
+	if this.reversed {
+		args = []string{this.max, this.min, "BYSCORE", "REV"}
+	}
+	if this.limited {
+		args = append(args, "LIMIT", itoa(this.offset), itoa(this.count))
+	}
+	return rangeStore(this.key, dest, args...)
+}
+
 //ZRANGEBYSCORE or ZREVRANGEBYSCORE command - 
 //GetWithScores returns a map with all members fitting the search criteria and their associated scores
 func (this *SortedSetRange) GetWithScores() <-chan map[string]float64 {
@@ -231,11 +1106,212 @@ func (this *SortedSetRange) GetWithScores() <-chan map[string]float64 {
 	return stringfloatMapChannel(MapCommand(this.key, this.key.args(op, args...)...))
 }
 
+//SortedSetLexRange keeps track of all range arguments being used in a search by member name.
+//These searches only make sense when every member of the zset has the same score
+type SortedSetLexRange struct {
+	min, max       string //the bounds as redis expects them, ie "[a", "(b", "-" or "+"
+	rawMin, rawMax string
+	limited        bool
+	offset, count  int
+	reversed       bool
+
+	key Key
+}
+
+//Lex creates a SortedSetLexRange to help narrow a search by member name, to be done later.
+//Like Scores, bounds can be added in any order, and the range will always be the intersection of all of them;
+//without any bounds, it covers every member
+func (this SortedSet) Lex() *SortedSetLexRange {
+	return &SortedSetLexRange{
+		min: "-",
+		max: "+",
+		key: this.Key,
+	}
+}
+
+//the lower bound only ever gets tighter - when two are the same, the exclusive one is tighter
+func (this *SortedSetLexRange) raiseMin(min string, exclusive bool) {
+	if this.min == "-" || min > this.rawMin || (min == this.rawMin && exclusive) {
+		this.rawMin = min
+		this.min = lexBound(min, exclusive)
+	}
+}
+
+//the upper bound only ever gets tighter - when two are the same, the exclusive one is tighter
+func (this *SortedSetLexRange) lowerMax(max string, exclusive bool) {
+	if this.max == "+" || max < this.rawMax || (max == this.rawMax && exclusive) {
+		this.rawMax = max
+		this.max = lexBound(max, exclusive)
+	}
+}
+
+func lexBound(member string, exclusive bool) string {
+	if exclusive {
+		return "(" + member
+	}
+	return "[" + member
+}
+
+//After limits results to members that come after "min"
+func (this *SortedSetLexRange) After(min string) *SortedSetLexRange {
+	this.raiseMin(min, true)
+	return this
+}
+
+//Before limits results to members that come before "max"
+func (this *SortedSetLexRange) Before(max string) *SortedSetLexRange {
+	this.lowerMax(max, true)
+	return this
+}
+
+//AfterOrEqualTo limits results to members that are "min" or come after it
+func (this *SortedSetLexRange) AfterOrEqualTo(min string) *SortedSetLexRange {
+	this.raiseMin(min, false)
+	return this
+}
+
+//BeforeOrEqualTo limits results to members that are "max" or come before it
+func (this *SortedSetLexRange) BeforeOrEqualTo(max string) *SortedSetLexRange {
+	this.lowerMax(max, false)
+	return this
+}
+
+//Reversed returns the results in reverse order.
+//This is only useful if getting, not useful for counting or removing
+func (this *SortedSetLexRange) Reversed() *SortedSetLexRange {
+	this.reversed = !this.reversed
+	return this
+}
+
+//Limit limits the results you get back - it skips the first "offset" results, and then only returns the next "count".
+//This is only useful if getting, not useful for counting or removing
+func (this *SortedSetLexRange) Limit(offset, count int) *SortedSetLexRange {
+	this.limited = true
+	this.offset = offset
+	this.count = count
+	return this
+}
+
+//ZLEXCOUNT command - 
+//Count returns the number of members that fit in the search criteria
+func (this *SortedSetLexRange) Count() <-chan int {
+	return IntCommand(this.key, this.key.args("zlexcount", this.min, this.max)...)
+}
+
+//ZREMRANGEBYLEX command - 
+//Remove removes all members that fit the search criteria from the zset;
+//returns the number of members removed
+func (this *SortedSetLexRange) Remove() <-chan int {
+	return IntCommand(this.key, this.key.args("zremrangebylex", this.min, this.max)...)
+}
+
+//ZRANGEBYLEX or ZREVRANGEBYLEX command - 
+//Get returns a list of all members fitting the search criteria
+func (this *SortedSetLexRange) Get() <-chan []string {
+	op := "zrangebylex"
+	args := make([]string, 2, 5)
+
+	if this.reversed {
+		op = "zrevrangebylex"
+		args[0] = this.max
+		args[1] = this.min
+	} else {
+		args[0] = this.min
+		args[1] = this.max
+	}
+
+	if this.limited {
+		args = append(args, "LIMIT", itoa(this.offset), itoa(this.count))
+	}
+
+	return SliceCommand(this.key, this.key.args(op, args...)...)
+}
+
+//ZRANGESTORE BYLEX command - 
+//StoreInto copies every member fitting the search criteria (including any Limit) into dest, replacing whatever dest had;
+//returns the number of members stored.
+//This needs redis 6.2 or later
+func (this *SortedSetLexRange) StoreInto(dest SortedSet) <-chan int {
+	args := []string{this.min, this.max, "BYLEX"}
+	if this.reversed {
+		args = []string{this.max, this.min, "BYLEX", "REV"}
+	}
+	if this.limited {
+		args = append(args, "LIMIT", itoa(this.offset), itoa(this.count))
+	}
+	return rangeStore(this.key, dest, args...)
+}
+
+//ScoredMember is a single member of a zset along with its score
+type ScoredMember struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+}
+
+const promoteTopScript = `
+local popped = redis.call('ZPOPMAX', KEYS[1])
+if #popped == 0 then
+	return false
+end
+redis.call('ZADD', KEYS[2], popped[2], popped[1])
+return popped[1]
+`
+
+//ZPOPMAX and ZADD commands (within a lua script) - 
+//PromoteTop removes the highest scoring member of this zset and adds it, with the same score, to dest;
+//returns the member that was promoted, or closes the channel without a value if this zset is empty.
+//This happens atomically, so no two clients can ever promote the same member
+func (this SortedSet) PromoteTop(dest SortedSet) <-chan string {
+	return StringCommand(crossShardGuard(this.Key, dest.Key), evalArgs(promoteTopScript, []string{this.key, dest.key})...)
+}
+
+//ZDIFF command - 
+//MembersNotIn returns the members of this zset that aren't members of the other one, in this zset's order (lowest score first);
+//the same as Difference with just the one other zset.
+//This needs redis 6.2 or later
+func (this SortedSet) MembersNotIn(other SortedSet) <-chan []string {
+	return this.Difference(other)
+}
+
+//ZDIFF command - 
+//Difference returns the members of this zset that aren't members of any of the others, in this zset's order (lowest score first).
+//This needs redis 6.2 or later
+func (this SortedSet) Difference(others ...SortedSet) <-chan []string {
+	e, args := this.differenceArgs(others)
+	return SliceCommand(e, args...)
+}
+
+//ZDIFF command - 
+//DifferenceWithScores returns the same members as Difference, along with their scores in this zset
+func (this SortedSet) DifferenceWithScores(others ...SortedSet) <-chan []ScoredMember {
+	e, args := this.differenceArgs(others)
+	return scoredMembersChannel(SliceCommand(e, append(args, "WITHSCORES")...))
+}
+
+func (this SortedSet) differenceArgs(others []SortedSet) (Executor, []string) {
+	return this.withOthers("ZDIFF", others)
+}
+
+//withOthers gives the executor and arguments for a command (like ZDIFF) that takes how many zsets there are, then this zset's key followed by the others';
+//if any of them are on a different client, the command fails with ErrCrossShard
+func (this SortedSet) withOthers(command string, others []SortedSet) (Executor, []string) {
+	args := make([]string, 0, len(others)+5)
+	args = append(args, command, itoa(len(others)+1), this.key)
+	keys := make([]Key, len(others))
+	for i, other := range others {
+		args = append(args, other.key)
+		keys[i] = other.Key
+	}
+	return crossShardGuard(this.Key, keys...), args
+}
+
 //SortedSetCombo keeps track of how you want to be combining multiple zsets
 type SortedSetCombo struct {
-	weighted bool
-	op       string //either Union or Intersection
-	sets     map[string]float64
+	weighted   bool
+	op         string //either Union, Intersection or Difference
+	sets       weightedSets
+	aggregate  string //how to combine duplicate scores when getting a Result
+	crossShard bool   //whether any of the zsets are on a different client than the key, which redis could never see
 
 	key Key
 }
@@ -244,8 +1320,9 @@ type SortedSetCombo struct {
 //StoreUnion sets up a combo that will be a union of other zsets
 func (this SortedSet) StoreUnion() *SortedSetCombo {
 	return &SortedSetCombo{
-		op:  "zunionstore",
-		key: this.Key,
+		op:        "zunionstore",
+		aggregate: "SUM",
+		key:       this.Key,
 	}
 }
 
@@ -253,66 +1330,246 @@ func (this SortedSet) StoreUnion() *SortedSetCombo {
 //StoreIntersection sets up a combo that will be an intersection of other zsets
 func (this SortedSet) StoreIntersection() *SortedSetCombo {
 	return &SortedSetCombo{
-		op:  "zinterstore",
-		key: this.Key,
+		op:        "zinterstore",
+		aggregate: "SUM",
+		key:       this.Key,
+	}
+}
+
+//ZDIFFSTORE command - 
+//StoreDifference sets up a combo that will be the members of the first zset added that aren't in any of the others, keeping their scores from the first zset.
+//Differences can't be weighted or aggregated, so any weights given to OfWeightedSet and scores chosen by the Use and Keep methods are ignored.
+//This needs redis 6.2 or later
+func (this SortedSet) StoreDifference() *SortedSetCombo {
+	return &SortedSetCombo{
+		op:        "zdiffstore",
+		aggregate: "SUM",
+		key:       this.Key,
 	}
 }
 
+//ZUNION command - 
+//Union returns the members that are in this zset or any of the others, lowest combined score first, without storing anything.
+//Duplicate scores are added together; for weights or a different way of combining them, use StoreUnion along with the combo's Result methods.
+//This needs redis 6.2 or later
+func (this SortedSet) Union(others ...SortedSet) <-chan []string {
+	return this.combination(this.StoreUnion(), others).Result()
+}
+
+//ZUNION command - 
+//UnionWithScores returns the same members as Union, along with their combined scores
+func (this SortedSet) UnionWithScores(others ...SortedSet) <-chan []ScoredMember {
+	return this.combination(this.StoreUnion(), others).ResultWithScores()
+}
+
+//ZINTER command - 
+//Intersection returns the members that are in this zset and all of the others, lowest combined score first, without storing anything.
+//Duplicate scores are added together; for weights or a different way of combining them, use StoreIntersection along with the combo's Result methods.
+//This needs redis 6.2 or later
+func (this SortedSet) Intersection(others ...SortedSet) <-chan []string {
+	return this.combination(this.StoreIntersection(), others).Result()
+}
+
+//ZINTER command - 
+//IntersectionWithScores returns the same members as Intersection, along with their combined scores
+func (this SortedSet) IntersectionWithScores(others ...SortedSet) <-chan []ScoredMember {
+	return this.combination(this.StoreIntersection(), others).ResultWithScores()
+}
+
+//ZINTERCARD command - 
+//IntersectionCardinality returns how many members are in this zset and all of the others, without building the intersection.
+//This needs redis 7.0 or later
+func (this SortedSet) IntersectionCardinality(others ...SortedSet) <-chan int {
+	return this.IntersectionCardinalityLimit(0, others...)
+}
+
+//ZINTERCARD command - 
+//IntersectionCardinalityLimit works the same way as IntersectionCardinality, but stops counting once it reaches the limit (0 means no limit);
+//useful when all that matters is whether the intersection is at least that big
+func (this SortedSet) IntersectionCardinalityLimit(limit int, others ...SortedSet) <-chan int {
+	e, args := this.withOthers("ZINTERCARD", others)
+	if limit > 0 {
+		args = append(args, "LIMIT", itoa(limit))
+	}
+	return IntCommand(e, args...)
+}
+
+//combination adds this zset and then the others to a combo; only its Result methods are used, so the combo's own key is never written to
+func (this SortedSet) combination(combo *SortedSetCombo, others []SortedSet) *SortedSetCombo {
+	combo.OfSet(this)
+	for _, other := range others {
+		combo.OfSet(other)
+	}
+	return combo
+}
+
 //OfSet adds a zset to the combo
 func (this *SortedSetCombo) OfSet(otherSet SortedSet) *SortedSetCombo {
-	if this.sets == nil {
-		this.sets = make(map[string]float64)
-	}
-	this.sets[otherSet.key] = 1.0
+	this.sets = this.sets.add(otherSet.key, 1.0)
+	this.crossShard = this.crossShard || onDifferentClients(this.key.client, otherSet.client)
 	return this
 }
 
 //OfWeightedSet adds a zset to the combo, and weights it to be either heavier or lighter than other zsets
 func (this *SortedSetCombo) OfWeightedSet(otherSet SortedSet, weight float64) *SortedSetCombo {
-	if this.sets == nil {
-		this.sets = make(map[string]float64)
-	}
 	this.weighted = true
-	this.sets[otherSet.key] = weight
+	this.sets = this.sets.add(otherSet.key, weight)
+	this.crossShard = this.crossShard || onDifferentClients(this.key.client, otherSet.client)
 	return this
 }
 
 //UseLowerScore combines the zsets, and when duplicates are found, will keep the lowest score found
 func (this *SortedSetCombo) UseLowerScore() <-chan int {
-	return IntCommand(this.key, this.args("MIN")...)
+	return IntCommand(this.executor(), this.args("MIN")...)
 }
 
 //UseHigherScore combines the zsets, and when duplicates are found, will keep the highest score found
 func (this *SortedSetCombo) UseHigherScore() <-chan int {
-	return IntCommand(this.key, this.args("MAX")...)
+	return IntCommand(this.executor(), this.args("MAX")...)
 }
 
 //UseCombinedScores combines the zsets, and when duplicates are found, will add the scores together
 func (this *SortedSetCombo) UseCombinedScores() <-chan int {
-	return IntCommand(this.key, this.args("SUM")...)
+	return IntCommand(this.executor(), this.args("SUM")...)
 }
 
-func (this *SortedSetCombo) args(mode string) []string {
-	result := make([]string, 1, 11)
-	result[0] = itoa(len(this.sets))
+//KeepLowerScore sets up the combo so that when duplicates are found, it will keep the lowest score found.
+//This is only useful if using Result or ResultWithScores
+func (this *SortedSetCombo) KeepLowerScore() *SortedSetCombo {
+	this.aggregate = "MIN"
+	return this
+}
+
+//KeepHigherScore sets up the combo so that when duplicates are found, it will keep the highest score found.
+//This is only useful if using Result or ResultWithScores
+func (this *SortedSetCombo) KeepHigherScore() *SortedSetCombo {
+	this.aggregate = "MAX"
+	return this
+}
+
+//KeepCombinedScores sets up the combo so that when duplicates are found, it will add the scores together (this is the default).
+//This is only useful if using Result or ResultWithScores
+func (this *SortedSetCombo) KeepCombinedScores() *SortedSetCombo {
+	this.aggregate = "SUM"
+	return this
+}
+
+//ZUNION, ZINTER or ZDIFF command - 
+//Result combines the zsets, but instead of storing the combination, returns its members in order
+func (this *SortedSetCombo) Result() <-chan []string {
+	return SliceCommand(this.executor(), this.resultArgs()...)
+}
+
+//ZUNION, ZINTER or ZDIFF command - 
+//ResultWithScores combines the zsets, but instead of storing the combination, returns its members in order along with their combined scores
+func (this *SortedSetCombo) ResultWithScores() <-chan []ScoredMember {
+	return scoredMembersChannel(SliceCommand(this.executor(), append(this.resultArgs(), "WITHSCORES")...))
+}
+
+const comboStoreWithTTLScript = `
+local command = {ARGV[2], KEYS[1], #KEYS - 1}
+for i = 2, #KEYS do
+	command[#command + 1] = KEYS[i]
+end
+for i = 3, #ARGV do
+	command[#command + 1] = ARGV[i]
+end
+local stored = redis.call(unpack(command))
+if stored > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return stored
+`
+
+//ZUNIONSTORE or ZINTERSTORE, and PEXPIRE commands (within a lua script) - 
+//StoreWithTTL combines the zsets and stores the combination, the same as UseCombinedScores (or whichever of the Keep methods was used),
+//and gives the stored zset a TTL at the same time, so that nobody ever sees it without one.
+//The TTL is rounded down to the millisecond (but is always at least one millisecond);
+//returns the number of members in the stored zset
+func (this *SortedSetCombo) StoreWithTTL(ttl time.Duration) <-chan int {
+	ms := int(ttl / time.Millisecond)
+	if ms < 1 {
+		ms = 1
+	}
+
+	keys := make([]string, 0, len(this.sets)+1)
+	keys = append(keys, this.key.key)
+	for _, set := range this.sets {
+		keys = append(keys, set.key)
+	}
+	//everything but the numkeys and keys at the front
+	options := this.comboArgs(this.aggregate)[len(this.sets)+1:]
 
-	weights := make([]string, 1, 3)
-	weights[0] = "WEIGHTS"
+	args := append([]string{itoa(ms), strings.ToUpper(this.op)}, options...)
+	return IntCommand(this.executor(), evalArgs(comboStoreWithTTLScript, keys, args...)...)
+}
 
-	for set, weight := range this.sets {
-		result = append(result, set)
-		weights = append(weights, ftoa(weight))
+//executor is what the combo's commands are issued on.
+//If the zsets are spread across different clients the combo can't work, so its commands fail with ErrCrossShard instead
+func (this *SortedSetCombo) executor() Executor {
+	if this.crossShard {
+		return failingExecutor{this.key.client, ErrCrossShard}
 	}
+	return this.key
+}
+
+func (this *SortedSetCombo) resultArgs() []string {
+	op := strings.ToUpper(strings.TrimSuffix(this.op, "store"))
+	return append([]string{op}, this.comboArgs(this.aggregate)...)
+}
+
+func (this *SortedSetCombo) args(mode string) []string {
+	return this.key.args(this.op, this.comboArgs(mode)...)
+}
 
-	if this.weighted {
-		result = append(result, weights...)
+func (this *SortedSetCombo) comboArgs(mode string) []string {
+	if this.op == "zdiffstore" {
+		//ZDIFF and ZDIFFSTORE don't take WEIGHTS or AGGREGATE
+		return this.sets.args(false)
 	}
 
+	result := this.sets.args(this.weighted)
 	if mode != "SUM" {
 		result = append(result, "AGGREGATE", mode)
 	}
+	return result
+}
+
+//weightedSet is one of the zsets in a combo, along with the weight its scores are multiplied by
+type weightedSet struct {
+	key    string
+	weight float64
+}
+
+//weightedSets keeps the zsets of a combo in the order they were added, so that WEIGHTS always lines up with the keys
+type weightedSets []weightedSet
+
+//add gives back the sets with another one added to the end, or with the weight replaced if the zset is already there
+func (this weightedSets) add(key string, weight float64) weightedSets {
+	for i, set := range this {
+		if set.key == key {
+			this[i].weight = weight
+			return this
+		}
+	}
+	return append(this, weightedSet{key, weight})
+}
+
+//args gives the arguments ZUNIONSTORE and friends take for the zsets: numkeys, the keys, and then (if weighted) the WEIGHTS in the same order
+func (this weightedSets) args(weighted bool) []string {
+	result := make([]string, 0, 2*len(this)+2)
+	result = append(result, itoa(len(this)))
+	for _, set := range this {
+		result = append(result, set.key)
+	}
 
-	return this.key.args(this.op, result...)
+	if weighted {
+		result = append(result, "WEIGHTS")
+		for _, set := range this {
+			result = append(result, ftoa(set.weight))
+		}
+	}
+	return result
 }
 
 //Use allows you to use this key on a different executor
@@ -320,3 +1577,140 @@ func (this SortedSet) Use(e SafeExecutor) SortedSet {
 	this.client = e
 	return this
 }
+
+//WithContext gives back the same zset, but with its commands tied to the context (see ContextExecutor).
+//(This is a lightweight function - does *not* involve network I/O)
+func (this SortedSet) WithContext(ctx context.Context) SortedSet {
+	return this.Use(ContextExecutor(ctx, this.client))
+}
+
+//globalTopKey is where GlobalTop builds its union.
+//It only ever exists while the script is running, and scripts run atomically, so there's no need for it to be unique
+const globalTopKey = "SimpleRedis:GlobalTop"
+
+//ARGV[2] is the command to delete the union with (DEL or UNLINK)
+const globalTopScript = `
+local temp = KEYS[#KEYS]
+if redis.call('EXISTS', temp) == 1 then
+	return redis.error_reply('ERR ' .. temp .. ' is needed by GlobalTop, but it is already in use')
+end
+local union = {'ZUNIONSTORE', temp, #KEYS - 1}
+for i = 1, #KEYS - 1 do
+	union[#union + 1] = KEYS[i]
+end
+union[#union + 1] = 'AGGREGATE'
+union[#union + 1] = 'MAX'
+local stored = redis.pcall(unpack(union))
+if type(stored) == 'table' and stored.err then
+	redis.call(ARGV[2], temp)
+	return stored
+end
+local top = redis.call('ZREVRANGE', temp, 0, tonumber(ARGV[1]) - 1, 'WITHSCORES')
+redis.call(ARGV[2], temp)
+return top
+`
+
+//ZUNIONSTORE and ZREVRANGE commands (within a lua script) - 
+//GlobalTop returns the n highest scoring members across all of the zsets given, highest first.
+//A member that is in several of the zsets is only counted once, using its highest score.
+//The union is built in a temporary key that is always removed before the script finishes, even if one of the zsets is the wrong type.
+//All of the zsets need to be on this client (use ShardedClient.GlobalTop for ones that are spread across shards), otherwise it fails with ErrCrossShard
+func (this *Client) GlobalTop(n int, sets ...SortedSet) <-chan []ScoredMember {
+	if n <= 0 || len(sets) == 0 {
+		out := make(chan []ScoredMember, 1)
+		out <- []ScoredMember{}
+		close(out)
+		return out
+	}
+
+	var e Executor = this
+	keys := make([]string, 0, len(sets)+1)
+	for _, set := range sets {
+		keys = append(keys, set.key)
+		if onDifferentClients(this, set.client) {
+			e = failingExecutor{this, ErrCrossShard}
+		}
+	}
+	keys = append(keys, globalTopKey)
+	return scoredMembersChannel(SliceCommand(e, evalArgs(globalTopScript, keys, itoa(n), deleteCommand(this))...))
+}
+
+//ZREVRANGE command (pipelined) - 
+//TopOfEach returns the n highest scoring members of each of the zsets given, all in a single round trip;
+//the map is keyed by each zset's key, and each slice is highest first.
+//A zset that doesn't exist gets an empty slice.
+//Each zset is read on its own client, so zsets from different clients (ie, from a ShardedClient) take a round trip to each of them
+func (this *Client) TopOfEach(n int, sets ...SortedSet) <-chan map[string][]ScoredMember {
+	return topOfEachShard(n, sets)
+}
+
+//topOfEachShard pipelines the ZREVRANGEs on each client that any of the zsets are on, and combines the results
+func topOfEachShard(n int, sets []SortedSet) <-chan map[string][]ScoredMember {
+	groups := byShard(sets)
+	results := make([]<-chan map[string][]ScoredMember, len(groups))
+	for i, group := range groups {
+		results[i] = topOfEach(group[0].client, n, group)
+	}
+
+	out := make(chan map[string][]ScoredMember, 1)
+	go func() {
+		defer close(out)
+		combined := make(map[string][]ScoredMember, len(sets))
+		for _, result := range results {
+			for key, top := range <-result {
+				combined[key] = top
+			}
+		}
+		out <- combined
+	}()
+	return out
+}
+
+//byShard groups the zsets by which client they are on, keeping their order within each group.
+//A zset used on anything other than a client (ie, a Batch) gets a group of its own, since it is already batching its commands
+func byShard(sets []SortedSet) [][]SortedSet {
+	var groups [][]SortedSet
+	index := make(map[*Client]int)
+	for _, set := range sets {
+		client, ok := set.client.(*Client)
+		if i, seen := index[client]; ok && seen {
+			groups[i] = append(groups[i], set)
+			continue
+		}
+		if ok {
+			index[client] = len(groups)
+		}
+		groups = append(groups, []SortedSet{set})
+	}
+	return groups
+}
+
+func topOfEach(e SafeExecutor, n int, sets []SortedSet) <-chan map[string][]ScoredMember {
+	tops := make([]<-chan []ScoredMember, len(sets))
+	out := make(chan map[string][]ScoredMember, 1)
+	gather := func() {
+		defer close(out)
+		result := make(map[string][]ScoredMember, len(sets))
+		for i, set := range sets {
+			result[set.key] = []ScoredMember{}
+			if tops[i] == nil {
+				continue
+			}
+			if top, ok := <-tops[i]; ok {
+				result[set.key] = top
+			}
+		}
+		out <- result
+	}
+	if n <= 0 {
+		go gather()
+		return out
+	}
+
+	pipelinedInBackground(e, func(p SafeExecutor) {
+		for i, set := range sets {
+			tops[i] = scoredMembersChannel(SliceCommand(p, set.args("zrevrange", "0", itoa(n-1), "WITHSCORES")...))
+		}
+	}, gather)
+	return out
+}
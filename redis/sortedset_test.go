@@ -1,7 +1,10 @@
 package redis
 
 import (
+	"math"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSortedSets(t *testing.T) {
@@ -402,8 +405,1512 @@ func TestSortedSets(t *testing.T) {
 		done <- true
 	}()
 
-	for i := 0; i < 8; i++ {
+	go func() {
+		resultset := r.SortedSet("InterResult_Test")
+		if res := <-resultset.StoreIntersection().OfSet(ss).OfSet(otherss).Result(); len(res) != 2 || res[0] != "A" || res[1] != "B" {
+			t.Error("Should get [A B], not", res)
+		}
+		if res := <-resultset.StoreIntersection().OfSet(ss).OfSet(otherss).ResultWithScores(); len(res) != 2 ||
+			res[0] != (ScoredMember{"A", 6}) ||
+			res[1] != (ScoredMember{"B", 15}) {
+			t.Error("Should get [{A 6} {B 15}], not", res)
+		}
+		if <-resultset.Exists() {
+			t.Error("Getting a result should not store anything")
+		}
+		done <- true
+	}()
+
+	go func() {
+		resultset := r.SortedSet("UnionResult_Test")
+		if res := <-resultset.StoreUnion().OfSet(ss).OfSet(otherss).KeepHigherScore().ResultWithScores(); len(res) != 6 ||
+			res[0] != (ScoredMember{"H", 2}) ||
+			res[1] != (ScoredMember{"C", 3}) ||
+			res[2] != (ScoredMember{"D", 4}) ||
+			res[3] != (ScoredMember{"A", 5}) ||
+			res[4] != (ScoredMember{"B", 9}) ||
+			res[5] != (ScoredMember{"G", 10}) {
+			t.Error("Should get [{H 2} {C 3} {D 4} {A 5} {B 9} {G 10}], not", res)
+		}
+		if res := <-resultset.StoreUnion().OfSet(ss).OfWeightedSet(otherss, -1).ResultWithScores(); len(res) != 6 ||
+			res[0] != (ScoredMember{"A", -4}) ||
+			res[1] != (ScoredMember{"D", -4}) ||
+			res[2] != (ScoredMember{"C", -3}) ||
+			res[3] != (ScoredMember{"H", 2}) ||
+			res[4] != (ScoredMember{"B", 3}) ||
+			res[5] != (ScoredMember{"G", 10}) {
+			t.Error("Should get [{A -4} {D -4} {C -3} {H 2} {B 3} {G 10}], not", res)
+		}
+		done <- true
+	}()
+
+	for i := 0; i < 10; i++ {
+		<-done
+	}
+
+}
+
+func TestSortedSetConditionalIncrements(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetConditionalIncrements")
+	<-ss.Delete()
+
+	score, applied := ss.IncrementIfGreater("A", 5)
+	if res, ok := <-score; !ok || res != 5 {
+		t.Error("A new member should be added with a score of 5, not", res)
+	}
+	if !<-applied {
+		t.Error("Adding a new member should count as applied")
+	}
+
+	score, applied = ss.IncrementIfGreater("A", 3)
+	if res, ok := <-score; !ok || res != 8 {
+		t.Error("Raising the score should make it 8, not", res)
+	}
+	if !<-applied {
+		t.Error("Raising the score should be applied")
+	}
+
+	score, applied = ss.IncrementIfGreater("A", -2)
+	if res, ok := <-score; ok {
+		t.Error("Lowering the score should be rejected, but got", res)
+	}
+	if <-applied {
+		t.Error("Lowering the score should not be applied")
+	}
+	if res := <-ss.ScoreOf("A"); res != 8 {
+		t.Error("A rejected increment should leave the score at 8, not", res)
+	}
+
+	score, applied = ss.IncrementIfLess("A", 2)
+	if res, ok := <-score; ok {
+		t.Error("Raising the score should be rejected, but got", res)
+	}
+	if <-applied {
+		t.Error("Raising the score should not be applied")
+	}
+
+	score, applied = ss.IncrementIfLess("A", -3)
+	if res, ok := <-score; !ok || res != 5 {
+		t.Error("Lowering the score should make it 5, not", res)
+	}
+	if !<-applied {
+		t.Error("Lowering the score should be applied")
+	}
+}
+
+func TestSortedSetRankedSlice(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetRankedSlice")
+	<-ss.Delete()
+
+	ss.Add("A", 1)
+	ss.Add("B", 2)
+	ss.Add("C", 3)
+	<-ss.Add("D", 4)
+
+	if res := <-ss.RankedSlice(1, 2, true); len(res) != 2 ||
+		res[0] != (RankedMember{1, "C", 3}) ||
+		res[1] != (RankedMember{2, "B", 2}) {
+		t.Error("Should get [{1 C 3} {2 B 2}], not", res)
+	}
+	if res := <-ss.RankedSlice(0, 1, false); len(res) != 2 ||
+		res[0] != (RankedMember{0, "A", 1}) ||
+		res[1] != (RankedMember{1, "B", 2}) {
+		t.Error("Should get [{0 A 1} {1 B 2}], not", res)
+	}
+	if res := <-ss.RankedSlice(-2, -1, true); len(res) != 2 ||
+		res[0] != (RankedMember{2, "B", 2}) ||
+		res[1] != (RankedMember{3, "A", 1}) {
+		t.Error("Should get [{2 B 2} {3 A 1}], not", res)
+	}
+}
+
+func TestSortedSetIncrementCapped(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetIncrementCapped")
+	<-ss.Delete()
+
+	if res := <-ss.IncrementCapped("A", 5, 10); res != 5 {
+		t.Error("Should increment a new member to 5, not", res)
+	}
+	if res := <-ss.IncrementCapped("A", 4, 10); res != 9 {
+		t.Error("Should increment to 9, not", res)
+	}
+	if res := <-ss.IncrementCapped("A", 4, 10); res != 10 {
+		t.Error("Should be capped at 10, not", res)
+	}
+	if res := <-ss.ScoreOf("A"); res != 10 {
+		t.Error("Stored score should be capped at 10, not", res)
+	}
+	if res := <-ss.IncrementCapped("A", -2.5, 10); res != 7.5 {
+		t.Error("Should be able to decrement below the cap to 7.5, not", res)
+	}
+
+	done := make(chan bool)
+	for i := 0; i < 10; i++ {
+		go func() {
+			<-ss.IncrementCapped("B", 1, 5)
+			done <- true
+		}()
+	}
+	for i := 0; i < 10; i++ {
 		<-done
 	}
+	if res := <-ss.ScoreOf("B"); res != 5 {
+		t.Error("Concurrent increments should still be capped at 5, not", res)
+	}
+}
+
+func TestSortedSetNegativeRanges(t *testing.T) {
+	ss := newSortedSet(nil, "Test_SortedSetNegativeRanges")
+
+	if res := ss.Scores().Above(-5); res.min != "(-5" || res.max != "+inf" {
+		t.Error("Above(-5) should give a range of (-5 to +inf, not", res.min, "to", res.max)
+	}
+	if res := ss.Scores().Below(-5); res.min != "-inf" || res.max != "(-5" {
+		t.Error("Below(-5) should give a range of -inf to (-5, not", res.min, "to", res.max)
+	}
+	if res := ss.Scores().AboveOrEqualTo(-10).Above(-12); res.min != "-10" {
+		t.Error("A looser negative lower bound should not replace -10, but got", res.min)
+	}
+	if res := ss.Scores().BelowOrEqualTo(-10).Below(0); res.max != "-10" {
+		t.Error("A looser upper bound of 0 should not replace -10, but got", res.max)
+	}
+	if res := ss.Scores().Below(0).BelowOrEqualTo(-1); res.max != "-1" {
+		t.Error("A tighter negative upper bound should replace (0, but got", res.max)
+	}
+	if res := ss.Scores().Above(5).Below(-5); res.min != "(5" || res.max != "(-5" {
+		t.Error("Out of order bounds should be kept as they are (and match nothing), not", res.min, "to", res.max)
+	}
+
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss = r.SortedSet("Test_SortedSetNegativeRanges")
+	<-ss.Delete()
+	ss.Add("A", -10)
+	ss.Add("B", -5)
+	ss.Add("C", 0)
+	<-ss.Add("D", 5)
+
+	if res := <-ss.Scores().Above(-5).Get(); len(res) != 2 || res[0] != "C" || res[1] != "D" {
+		t.Error("Above -5 should be [C D], not", res)
+	}
+	if res := <-ss.Scores().AboveOrEqualTo(-5).Get(); len(res) != 3 || res[0] != "B" || res[1] != "C" || res[2] != "D" {
+		t.Error("Above or equal to -5 should be [B C D], not", res)
+	}
+	if res := <-ss.Scores().Below(-5).Get(); len(res) != 1 || res[0] != "A" {
+		t.Error("Below -5 should be [A], not", res)
+	}
+	if res := <-ss.Scores().Above(-10).BelowOrEqualTo(0).Get(); len(res) != 2 || res[0] != "B" || res[1] != "C" {
+		t.Error("Between -10 and 0 should be [B C], not", res)
+	}
+	if res := <-ss.Scores().Above(5).Below(-5).Count(); res != 0 {
+		t.Error("Out of order bounds should not match anything, not", res)
+	}
+}
+
+func TestSortedSetRangeOrdering(t *testing.T) {
+	ss := newSortedSet(nil, "Test_SortedSetRangeOrdering")
+
+	type bound func(*SortedSetRange) *SortedSetRange
+	bounds := []bound{
+		func(r *SortedSetRange) *SortedSetRange { return r.Above(5) },
+		func(r *SortedSetRange) *SortedSetRange { return r.Above(10) },
+		func(r *SortedSetRange) *SortedSetRange { return r.AboveOrEqualTo(10) },
+		func(r *SortedSetRange) *SortedSetRange { return r.AboveOrEqualTo(-3) },
+		func(r *SortedSetRange) *SortedSetRange { return r.Below(50) },
+		func(r *SortedSetRange) *SortedSetRange { return r.BelowOrEqualTo(20) },
+		func(r *SortedSetRange) *SortedSetRange { return r.Below(20) },
+		func(r *SortedSetRange) *SortedSetRange { return r.BelowOrEqualTo(100) },
+	}
+
+	//try every rotation of the bounds, both forwards and backwards
+	for start := range bounds {
+		for _, step := range []int{1, len(bounds) - 1} {
+			res := ss.Scores()
+			for i := 0; i < len(bounds); i++ {
+				res = bounds[(start+i*step)%len(bounds)](res)
+			}
+			if res.min != "(10" || res.max != "(20" {
+				t.Error("Should always end up with a range of (10 to (20, not", res.min, "to", res.max)
+			}
+		}
+	}
+
+	if res := ss.Scores().Above(10).Above(5); res.min != "(10" {
+		t.Error("Above(10).Above(5) should keep the tighter bound of (10, not", res.min)
+	}
+	if res := ss.Scores().Above(5).Above(10); res.min != "(10" {
+		t.Error("Above(5).Above(10) should keep the tighter bound of (10, not", res.min)
+	}
+	if res := ss.Scores().Below(5).Below(10); res.max != "(5" {
+		t.Error("Below(5).Below(10) should keep the tighter bound of (5, not", res.max)
+	}
+	if res := ss.Scores().Below(10).Below(5); res.max != "(5" {
+		t.Error("Below(10).Below(5) should keep the tighter bound of (5, not", res.max)
+	}
+}
+
+func TestSortedSetRecordBest(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetRecordBest")
+	<-ss.Delete()
+
+	if res := <-ss.RecordBest(map[string]float64{"A": 4, "B": 3, "C": 2, "D": 1}); len(res) != 4 ||
+		res[0] != "A" || res[1] != "B" || res[2] != "C" || res[3] != "D" {
+		t.Error("Every new member should have moved, [A B C D], not", res)
+	}
+
+	//C overtakes B, A's lower score is ignored, and D's position doesn't change
+	if res := <-ss.RecordBest(map[string]float64{"A": 1, "C": 3.5, "D": 1.5}); len(res) != 2 || res[0] != "C" || res[1] != "B" {
+		t.Error("Only C and B should have moved, [C B], not", res)
+	}
+	if res := <-ss.ScoreOf("A"); res != 4 {
+		t.Error("A should keep its best score of 4, not", res)
+	}
+	if res := <-ss.ScoreOf("D"); res != 1.5 {
+		t.Error("D should have its improved score of 1.5, not", res)
+	}
+
+	if res, ok := <-ss.RecordBest(map[string]float64{"A": 2}); !ok || len(res) != 0 {
+		t.Error("Nobody should have moved, not", res)
+	}
+}
+
+func TestSortedSetSnapshotJSON(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetSnapshotJSON")
+	<-ss.Delete()
+
+	if res := string(<-ss.SnapshotJSON()); res != "[]" {
+		t.Error("An empty zset should give [], not", res)
+	}
+
+	ss.Add("A", 2)
+	ss.Add("B", 1.5)
+	<-ss.Add("C", 3)
+
+	if res := string(<-ss.SnapshotJSON()); res != `[{"member":"B","score":1.5},{"member":"A","score":2},{"member":"C","score":3}]` {
+		t.Error("Unexpected snapshot", res)
+	}
+}
+
+func TestSortedSetLoad(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetLoad")
+	<-ss.Delete()
+
+	if res := <-ss.LoadJSON([]byte(`[{"member":"A","score":1},{"member":"B","score":2.5}]`)); res != 2 {
+		t.Error("Should have added 2 members from JSON, not", res)
+	}
+	if res := <-ss.LoadCSV(strings.NewReader("B,3\nC, -1\n")); res != 1 {
+		t.Error("Should have added 1 new member from CSV, not", res)
+	}
+	if res := <-ss.ScoreOf("B"); res != 3 {
+		t.Error("B should have been updated to 3, not", res)
+	}
+	if res := <-ss.ScoreOf("C"); res != -1 {
+		t.Error("C should have been added with -1, not", res)
+	}
 
+	big := make([]string, 0, 2500)
+	for i := 0; i < 2500; i++ {
+		big = append(big, "Member"+itoa(i)+","+itoa(i))
+	}
+	if res := <-ss.LoadCSV(strings.NewReader(strings.Join(big, "\n"))); res != 2500 {
+		t.Error("Should have added all 2500 members across batches, not", res)
+	}
+
+	errors := make(chan string, 2)
+	r.SetErrorCallback(func(e error, s string) {
+		errors <- e.Error()
+	})
+
+	if res, ok := <-ss.LoadJSON([]byte(`[{"member":"D",`)); ok {
+		t.Error("Malformed JSON should not add anything, not", res)
+	}
+	if res := <-errors; !strings.Contains(res, "Malformed JSON") {
+		t.Error("Should report malformed JSON, not", res)
+	}
+	if res, ok := <-ss.LoadCSV(strings.NewReader("D,4\nE,five\n")); ok {
+		t.Error("A malformed CSV row should not add anything, not", res)
+	}
+	if res := <-errors; !strings.Contains(res, "row 2") {
+		t.Error("Should report which row was malformed, not", res)
+	}
+	if res, ok := <-ss.ScoreOf("D"); ok {
+		t.Error("D should not have been added from the malformed CSV, but has a score of", res)
+	}
+}
+
+func TestSortedSetIsEmpty(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetIsEmpty")
+	<-ss.Delete()
+
+	if !<-ss.IsEmpty() {
+		t.Error("A zset that doesn't exist should be empty")
+	}
+	if <-ss.Exists() {
+		t.Error("A zset that was deleted should not exist")
+	}
+	<-ss.Add("A", 1)
+	if <-ss.IsEmpty() {
+		t.Error("A zset with a member should not be empty")
+	}
+	if !<-ss.Exists() {
+		t.Error("A zset with a member should exist")
+	}
+}
+
+func TestSortedSetAddReporting(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetAddReporting")
+	<-ss.Delete()
+
+	if res := <-ss.AddReporting("A", 1.5); res != (AddResult{WasNew: true}) {
+		t.Error("A should have been new, not", res)
+	}
+	if res := <-ss.AddReporting("A", 3); res != (AddResult{PreviousScore: 1.5, HadPrevious: true}) {
+		t.Error("A should have been updated from 1.5, not", res)
+	}
+	if res := <-ss.ScoreOf("A"); res != 3 {
+		t.Error("A should now have a score of 3, not", res)
+	}
+}
+
+func TestSortedSetAddRounded(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetAddRounded")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	if !<-ss.AddRounded("A", 19.999999998, 2) {
+		t.Error("A should have been added")
+	}
+	if res := <-ss.ScoreOf("A"); res != 20 {
+		t.Error("A should have been rounded to 20, not", res)
+	}
+	<-ss.AddRounded("B", 2.5, 0)
+	if res := <-ss.ScoreOf("B"); res != 3 {
+		t.Error("B should have been rounded away from zero to 3, not", res)
+	}
+	<-ss.AddRounded("C", 1234, -2)
+	if res := <-ss.ScoreOf("C"); res != 1200 {
+		t.Error("C should have been rounded to the nearest hundred, not", res)
+	}
+}
+
+func TestSortedSetRanksAndScoresOf(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetRanksAndScoresOf")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+	<-ss.Add("A", 1)
+	<-ss.Add("B", 2)
+	<-ss.Add("C", 3)
+
+	expected := []RankedMember{{2, "C", 3}, {-1, "Missing", 0}, {0, "A", 1}}
+	res := <-ss.RanksAndScoresOf("C", "Missing", "A")
+	if len(res) != len(expected) {
+		t.Fatal("Should have gotten", expected, "not", res)
+	}
+	for i := range expected {
+		if res[i] != expected[i] {
+			t.Error("Should have gotten", expected[i], "not", res[i])
+		}
+	}
+
+	reversed := <-ss.ReverseRanksAndScoresOf("C", "A")
+	if len(reversed) != 2 || reversed[0] != (RankedMember{0, "C", 3}) || reversed[1] != (RankedMember{2, "A", 1}) {
+		t.Error("Should have gotten C first and A last, not", reversed)
+	}
+}
+
+func TestBlockingPopWholeSeconds(t *testing.T) {
+	sent := make(chan string, 3)
+	r, done := FakeRedis(t, func(args []string) string {
+		sent <- strings.Join(args, " ")
+		return "*-1\r\n"
+	})
+	defer done()
+
+	a, b := r.SortedSet("A"), r.SortedSet("B")
+	for timeout, expected := range map[time.Duration]string{
+		0:                       "BZPOPMIN A B 0",
+		100 * time.Millisecond:  "BZPOPMIN A B 1",
+		1500 * time.Millisecond: "BZPOPMIN A B 2",
+	} {
+		if res, ok := <-BlockingPopMin(timeout, a, b); ok {
+			t.Error("Nothing should be popped, not", res)
+		}
+		if command := <-sent; command != expected {
+			t.Error("A timeout of", timeout, "should have been sent as", expected, "not", command)
+		}
+	}
+	<-BlockingPopMax(time.Millisecond, a)
+	if command := <-sent; command != "BZPOPMAX A 1" {
+		t.Error("Should have sent BZPOPMAX A 1, not", command)
+	}
+}
+
+func TestTopOfEachDoesntBlock(t *testing.T) {
+	release := make(chan struct{})
+	r, done := FakeRedis(t, func(args []string) string {
+		<-release
+		return "*2\r\n$1\r\nX\r\n$1\r\n5\r\n"
+	})
+	defer done()
+
+	var res <-chan map[string][]ScoredMember
+	returned := returnsRightAway(t, "TopOfEach", func() {
+		res = r.TopOfEach(1, r.SortedSet("A"))
+	})
+	close(release)
+	if !returned {
+		return
+	}
+	if tops := <-res; len(tops["A"]) != 1 || tops["A"][0] != (ScoredMember{"X", 5}) {
+		t.Error("Should have gotten the top of A once redis replied, not", tops)
+	}
+}
+
+func TestRanksAndScoresOfDoesntBlock(t *testing.T) {
+	release := make(chan struct{})
+	r, done := FakeRedis(t, func(args []string) string {
+		<-release
+		return "*2\r\n:4\r\n$1\r\n5\r\n"
+	})
+	defer done()
+
+	var res <-chan []RankedMember
+	returned := returnsRightAway(t, "RanksAndScoresOf", func() {
+		res = r.SortedSet("A").RanksAndScoresOf("X")
+	})
+	close(release)
+	if !returned {
+		return
+	}
+	if ranked := <-res; len(ranked) != 1 || ranked[0] != (RankedMember{4, "X", 5}) {
+		t.Error("Should have gotten X's rank and score once redis replied, not", ranked)
+	}
+}
+
+func TestSortedSetDetailsOf(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetDetailsOf")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+	<-ss.Add("A", 1)
+	<-ss.Add("B", 2)
+	<-ss.Add("C", 3)
+
+	expected := []MemberDetail{{"A", 1, 2, true}, {"Missing", 0, 0, false}, {"C", 3, 0, true}}
+	res := <-ss.DetailsOf("A", "Missing", "C")
+	if len(res) != len(expected) {
+		t.Fatal("Should have gotten", expected, "not", res)
+	}
+	for i := range expected {
+		if res[i] != expected[i] {
+			t.Error("Should have gotten", expected[i], "not", res[i])
+		}
+	}
+	if res, ok := <-ss.DetailsOf(); !ok || len(res) != 0 {
+		t.Error("No members should give no details, not", res, ok)
+	}
+}
+
+func TestGlobalTop(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	us := r.SortedSet("Test_GlobalTop_US")
+	eu := r.SortedSet("Test_GlobalTop_EU")
+	<-us.Delete()
+	<-eu.Delete()
+	defer func() {
+		<-us.Delete()
+		<-eu.Delete()
+	}()
+	<-us.Add("A", 10)
+	<-us.Add("B", 5)
+	<-eu.Add("A", 3)
+	<-eu.Add("C", 7)
+	<-eu.Add("D", 1)
+
+	expected := []ScoredMember{{"A", 10}, {"C", 7}, {"B", 5}}
+	res := <-r.GlobalTop(3, us, eu)
+	if len(res) != len(expected) {
+		t.Fatal("Should have gotten", expected, "not", res)
+	}
+	for i := range expected {
+		if res[i] != expected[i] {
+			t.Error("Should have gotten", expected[i], "not", res[i])
+		}
+	}
+	if <-r.Key(globalTopKey).Exists() {
+		t.Error("The temporary key should have been cleaned up")
+	}
+
+	if res := <-r.GlobalTop(0, us, eu); len(res) != 0 {
+		t.Error("Should not get any members when asking for none, not", res)
+	}
+}
+
+func TestSortedSetNeighborsOf(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetNeighborsOf")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+	for i, member := range []string{"A", "B", "C", "D", "E"} {
+		<-ss.Add(member, float64(i))
+	}
+
+	check := func(member string, radius int, expected []RankedMember) {
+		res, ok := <-ss.NeighborsOf(member, radius)
+		if !ok || len(res) != len(expected) {
+			t.Error("Neighbors of", member, "should be", expected, "not", res)
+			return
+		}
+		for i := range expected {
+			if res[i] != expected[i] {
+				t.Error("Neighbors of", member, "should be", expected, "not", res)
+				return
+			}
+		}
+	}
+	check("C", 1, []RankedMember{{1, "D", 3}, {2, "C", 2}, {3, "B", 1}})
+	check("E", 2, []RankedMember{{0, "E", 4}, {1, "D", 3}, {2, "C", 2}})
+	check("A", 1, []RankedMember{{3, "B", 1}, {4, "A", 0}})
+
+	if _, ok := <-ss.NeighborsOf("Missing", 1); ok {
+		t.Error("Should not get any neighbors for a member that isn't in the zset")
+	}
+}
+
+func TestSortedSetIncrementIfExists(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetIncrementIfExists")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+	<-ss.Add("A", 1)
+
+	if res, ok := <-ss.IncrementIfExists("A", 2.5); !ok || res != 3.5 {
+		t.Error("A should have been incremented to 3.5, not", res)
+	}
+	if res, ok := <-ss.IncrementIfExists("B", 1); ok {
+		t.Error("B should not have been incremented, but got", res)
+	}
+	if <-ss.Size() != 1 {
+		t.Error("Incrementing a missing member should not have added it")
+	}
+}
+
+func TestTopOfEach(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	a := r.SortedSet("Test_TopOfEach_A")
+	b := r.SortedSet("Test_TopOfEach_B")
+	missing := r.SortedSet("Test_TopOfEach_Missing")
+	<-a.Delete()
+	<-b.Delete()
+	<-missing.Delete()
+	defer func() {
+		<-a.Delete()
+		<-b.Delete()
+	}()
+	<-a.Add("A1", 1)
+	<-a.Add("A2", 2)
+	<-a.Add("A3", 3)
+	<-b.Add("B1", 1)
+
+	res := <-r.TopOfEach(2, a, b, missing)
+	if len(res) != 3 {
+		t.Fatal("Should have a result for every zset, not", res)
+	}
+	if top := res["Test_TopOfEach_A"]; len(top) != 2 || top[0] != (ScoredMember{"A3", 3}) || top[1] != (ScoredMember{"A2", 2}) {
+		t.Error("The top of A should be A3 then A2, not", top)
+	}
+	if top := res["Test_TopOfEach_B"]; len(top) != 1 || top[0] != (ScoredMember{"B1", 1}) {
+		t.Error("The top of B should be B1, not", top)
+	}
+	if top, ok := res["Test_TopOfEach_Missing"]; !ok || len(top) != 0 {
+		t.Error("A missing zset should have an empty top, not", top)
+	}
+}
+
+func TestSortedSetHLLShadow(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	a := r.SortedSet("Test_Shadow_A").WithHLLShadow("Test_Shadow_A_HLL")
+	b := r.SortedSet("Test_Shadow_B").WithHLLShadow("Test_Shadow_B_HLL")
+	cleanup := func() {
+		<-a.Delete()
+		<-b.Delete()
+		<-r.Key("Test_Shadow_A_HLL").Delete()
+		<-r.Key("Test_Shadow_B_HLL").Delete()
+	}
+	cleanup()
+	defer cleanup()
+
+	if !<-a.Add("X", 1) {
+		t.Error("X should have been added to A")
+	}
+	if <-a.Add("X", 2) {
+		t.Error("X should have been updated in A, not added")
+	}
+	<-a.Add("Y", 1)
+	<-b.Add("Y", 3)
+	<-b.Add("Z", 4)
+
+	if res := <-a.ScoreOf("X"); res != 2 {
+		t.Error("X should have a score of 2 in A, not", res)
+	}
+	if res := <-a.ApproxUnique(); res != 2 {
+		t.Error("A should have about 2 unique members, not", res)
+	}
+	if res := <-r.ApproxUniqueAcross(a, b); res != 3 {
+		t.Error("A and B should have about 3 unique members between them, not", res)
+	}
+}
+
+func TestSortedSetInitializeIfAbsent(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetInitializeIfAbsent")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	if !<-ss.InitializeIfAbsent(map[string]float64{"A": 1, "B": 2}) {
+		t.Error("Should have seeded a zset that didn't exist")
+	}
+	if <-ss.InitializeIfAbsent(map[string]float64{"A": 10, "C": 3}) {
+		t.Error("Should not seed a zset that already exists")
+	}
+	if res := <-ss.Size(); res != 2 {
+		t.Error("Should still have 2 members, not", res)
+	}
+	if res := <-ss.ScoreOf("A"); res != 1 {
+		t.Error("A should still have its original score, not", res)
+	}
+}
+
+func TestSortedSetMembersNotIn(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	global := r.SortedSet("Test_MembersNotIn_Global")
+	regional := r.SortedSet("Test_MembersNotIn_Regional")
+	<-global.Delete()
+	<-regional.Delete()
+	defer func() {
+		<-global.Delete()
+		<-regional.Delete()
+	}()
+	<-global.Add("A", 3)
+	<-global.Add("B", 1)
+	<-global.Add("C", 2)
+	<-regional.Add("C", 10)
+
+	res := <-global.MembersNotIn(regional)
+	if len(res) != 2 || res[0] != "B" || res[1] != "A" {
+		t.Error("Should have gotten B and A, not", res)
+	}
+	if res := <-regional.MembersNotIn(global); len(res) != 0 {
+		t.Error("Every regional member is on the global board, but got", res)
+	}
+}
+
+func TestSortedSetPromoteTop(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	round := r.SortedSet("Test_PromoteTop_Round1")
+	next := r.SortedSet("Test_PromoteTop_Round2")
+	<-round.Delete()
+	<-next.Delete()
+	defer func() {
+		<-round.Delete()
+		<-next.Delete()
+	}()
+	<-round.Add("A", 1)
+	<-round.Add("B", 3)
+
+	if res, ok := <-round.PromoteTop(next); !ok || res != "B" {
+		t.Error("Should have promoted B, not", res)
+	}
+	if res := <-next.ScoreOf("B"); res != 3 {
+		t.Error("B should have kept its score of 3, not", res)
+	}
+	if res := <-round.Size(); res != 1 {
+		t.Error("Only A should be left, but there are", res)
+	}
+	<-round.PromoteTop(next)
+	if res, ok := <-round.PromoteTop(next); ok {
+		t.Error("Should not be able to promote from an empty zset, but got", res)
+	}
+}
+
+func TestSortedSetIncrementByChecked(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_IncrementByChecked")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	if res := <-ss.IncrementByChecked("A", 5); res != (IncrResult{5, false}) {
+		t.Error("A should have been incremented to 5, not", res)
+	}
+	<-ss.Add("B", math.MaxFloat64)
+	if res := <-ss.IncrementByChecked("B", math.MaxFloat64); res != (IncrResult{math.MaxFloat64, true}) {
+		t.Error("B should have overflowed, not", res)
+	}
+	if res := <-ss.IncrementByChecked("A", math.Inf(1)); res != (IncrResult{5, true}) {
+		t.Error("An infinite increment should overflow, not", res)
+	}
+
+	res := <-ss.IncrementManyChecked(map[string]float64{"A": 5, "B": 1, "C": math.Inf(-1), "D": 2})
+	expected := map[string]IncrResult{"A": {10, false}, "B": {math.MaxFloat64, false}, "C": {0, true}, "D": {2, false}}
+	if len(res) != len(expected) {
+		t.Fatal("Should have gotten an outcome for every member, not", res)
+	}
+	for member, outcome := range expected {
+		if res[member] != outcome {
+			t.Error(member, "should have ended up", outcome, "not", res[member])
+		}
+	}
+	if <-ss.Size() != 3 {
+		t.Error("C's infinite increment should not have added it")
+	}
+	if res := <-ss.IncrementManyChecked(map[string]float64{"B": math.MaxFloat64, "D": 1}); res["B"] != (IncrResult{math.MaxFloat64, true}) || res["D"] != (IncrResult{3, false}) {
+		t.Error("Only B should have overflowed, not", res)
+	}
+
+	//A is at 10, and D is at 3
+	if res := <-ss.IncrementByCheckedUpTo("A", 2, 12); res != (IncrResult{12, false}) {
+		t.Error("A should have been incremented right up to the ceiling, not", res)
+	}
+	if res := <-ss.IncrementByCheckedUpTo("A", 0.5, 12); res != (IncrResult{12, true}) {
+		t.Error("Going above the ceiling should overflow, not", res)
+	}
+	res = <-ss.IncrementManyCheckedUpTo(map[string]float64{"A": -1, "D": 10}, 12)
+	if res["A"] != (IncrResult{11, false}) || res["D"] != (IncrResult{3, true}) {
+		t.Error("Only D should have gone above the ceiling, not", res)
+	}
+	if score := <-ss.ScoreOf("D"); score != 3 {
+		t.Error("D's refused increment should not have been stored, but its score is", score)
+	}
+}
+
+func TestSortedSetAddWith(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_AddWith")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	if !<-ss.AddIfAbsent("A", 5) {
+		t.Error("A should have been added")
+	}
+	if <-ss.AddIfAbsent("A", 10) {
+		t.Error("A should not have been added again")
+	}
+	if <-ss.AddIfPresent("B", 1) {
+		t.Error("B should not have been added")
+	}
+	if !<-ss.AddIfGreater("A", 7) {
+		t.Error("A should have been raised to 7")
+	}
+	if <-ss.AddIfGreater("A", 6) {
+		t.Error("A should not have been lowered")
+	}
+	if !<-ss.AddIfLess("A", 3) {
+		t.Error("A should have been lowered to 3")
+	}
+	if !<-ss.AddIfPresent("A", 4) {
+		t.Error("A should have been updated to 4")
+	}
+	if res := <-ss.ScoreOf("A"); res != 4 {
+		t.Error("A should be 4, not", res)
+	}
+	if res := <-ss.Size(); res != 1 {
+		t.Error("Only A should be a member, not", res, "members")
+	}
+
+	if _, ok := <-ss.AddWith("C", 1, AddOnlyNew, AddOnlyGreater); ok {
+		t.Error("NX and GT should not be allowed together")
+	}
+	if _, ok := <-ss.AddWith("C", 1, AddOnlyGreater, AddOnlyLess); ok {
+		t.Error("GT and LT should not be allowed together")
+	}
+}
+
+func TestSortedSetComboWeightOrder(t *testing.T) {
+	combo := newSortedSet(nil, "Dest").StoreUnion()
+	for i := 0; i < 10; i++ {
+		combo.OfWeightedSet(newSortedSet(nil, "Source"+itoa(i)), float64(i))
+	}
+	combo.OfWeightedSet(newSortedSet(nil, "Source3"), 30)
+
+	args := combo.args("MAX")
+	expected := []string{"ZUNIONSTORE", "Dest", "10"}
+	for i := 0; i < 10; i++ {
+		expected = append(expected, "Source"+itoa(i))
+	}
+	expected = append(expected, "WEIGHTS", "0", "1", "2", "30", "4", "5", "6", "7", "8", "9", "AGGREGATE", "MAX")
+	if strings.Join(args, " ") != strings.Join(expected, " ") {
+		t.Error("Weights should line up with their keys, expected", expected, "not", args)
+	}
+}
+
+func TestSortedSetWeightedUnion(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	a := r.SortedSet("Test_WeightedUnion:A")
+	b := r.SortedSet("Test_WeightedUnion:B")
+	dest := r.SortedSet("Test_WeightedUnion")
+	<-a.Add("X", 1)
+	<-b.Add("X", 10)
+	<-b.Add("Y", 1)
+	defer func() {
+		<-a.Delete()
+		<-b.Delete()
+		<-dest.Delete()
+	}()
+
+	if res := <-dest.StoreUnion().OfWeightedSet(a, 2).OfWeightedSet(b, 3).UseCombinedScores(); res != 2 {
+		t.Error("The union should have 2 members, not", res)
+	}
+	if res := <-dest.ScoreOf("X"); res != 32 {
+		t.Error("X should be 1*2 + 10*3 = 32, not", res)
+	}
+	if res := <-dest.ScoreOf("Y"); res != 3 {
+		t.Error("Y should be 1*3 = 3, not", res)
+	}
+}
+
+func TestSortedSetPop(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetPop")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	for i, member := range []string{"A", "B", "C", "D", "E"} {
+		<-ss.Add(member, float64(i))
+	}
+
+	if res := <-ss.PopMin(1); len(res) != 1 || res[0] != (ScoredMember{"A", 0}) {
+		t.Error("Should have popped [{A 0}], not", res)
+	}
+	if res := <-ss.PopMax(2); len(res) != 2 || res[0] != (ScoredMember{"E", 4}) || res[1] != (ScoredMember{"D", 3}) {
+		t.Error("Should have popped [{E 4} {D 3}], not", res)
+	}
+	if res := <-ss.PopMin(5); len(res) != 2 || res[0] != (ScoredMember{"B", 1}) || res[1] != (ScoredMember{"C", 2}) {
+		t.Error("Should have popped [{B 1} {C 2}], not", res)
+	}
+	if res, ok := <-ss.PopMin(1); !ok || len(res) != 0 {
+		t.Error("Popping from an empty zset should give nothing, not", res, ok)
+	}
+}
+
+func TestBlockingPop(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	a := r.SortedSet("Test_BlockingPop_A")
+	b := r.SortedSet("Test_BlockingPop_B")
+	<-a.Delete()
+	<-b.Delete()
+	defer func() {
+		<-a.Delete()
+		<-b.Delete()
+	}()
+
+	if res, ok := <-BlockingPopMin(100*time.Millisecond, a, b); ok {
+		t.Error("Nothing should be popped from empty zsets, not", res)
+	}
+
+	popped := BlockingPopMin(5*time.Second, a, b)
+	<-b.Add("B1", 1)
+	<-b.Add("B2", 2)
+	if res := <-popped; res != (PoppedMember{"Test_BlockingPop_B", "B1", 1}) {
+		t.Error("Should have popped B1 from Test_BlockingPop_B, not", res)
+	}
+	if res := <-BlockingPopMax(time.Second, a, b); res != (PoppedMember{"Test_BlockingPop_B", "B2", 2}) {
+		t.Error("Should have popped B2 from Test_BlockingPop_B, not", res)
+	}
+}
+
+func TestSortedSetLexBounds(t *testing.T) {
+	ss := newSortedSet(nil, "Test_LexBounds")
+	if res := ss.Lex(); res.min != "-" || res.max != "+" {
+		t.Error("A range without bounds should cover everything, not", res.min, res.max)
+	}
+	if res := ss.Lex().AfterOrEqualTo("b").After("a").After("b"); res.min != "(b" || res.max != "+" {
+		t.Error("The tightest lower bound should be (b, not", res.min, res.max)
+	}
+	if res := ss.Lex().Before("y").BeforeOrEqualTo("x").BeforeOrEqualTo("z"); res.min != "-" || res.max != "[x" {
+		t.Error("The tightest upper bound should be [x, not", res.min, res.max)
+	}
+}
+
+func TestSortedSetLex(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetLex")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	for _, member := range []string{"a", "b", "c", "d", "e"} {
+		<-ss.Add(member, 0)
+	}
+
+	if res := <-ss.Lex().Get(); strings.Join(res, "") != "abcde" {
+		t.Error("Should get every member, not", res)
+	}
+	if res := <-ss.Lex().After("a").BeforeOrEqualTo("d").Get(); strings.Join(res, "") != "bcd" {
+		t.Error("Should get [b c d], not", res)
+	}
+	if res := <-ss.Lex().AfterOrEqualTo("b").Before("e").Reversed().Limit(1, 2).Get(); strings.Join(res, "") != "cb" {
+		t.Error("Should get [c b], not", res)
+	}
+	if res := <-ss.Lex().AfterOrEqualTo("c").Count(); res != 3 {
+		t.Error("Should count 3 members from c onwards, not", res)
+	}
+	if res := <-ss.Lex().Before("c").Remove(); res != 2 {
+		t.Error("Should remove the 2 members before c, not", res)
+	}
+	if res := <-ss.Size(); res != 3 {
+		t.Error("Should have 3 members left, not", res)
+	}
+}
+
+func TestSortedSetComboStoreWithTTL(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	a := r.SortedSet("Test_StoreWithTTL:A")
+	b := r.SortedSet("Test_StoreWithTTL:B")
+	dest := r.SortedSet("Test_StoreWithTTL")
+	<-dest.Delete()
+	<-a.Add("X", 1)
+	<-b.Add("X", 5)
+	<-b.Add("Y", 2)
+	defer func() {
+		<-a.Delete()
+		<-b.Delete()
+		<-dest.Delete()
+	}()
+
+	if res := <-dest.StoreUnion().OfSet(a).OfWeightedSet(b, 2).KeepHigherScore().StoreWithTTL(time.Minute); res != 2 {
+		t.Error("The union should have 2 members, not", res)
+	}
+	if res := <-dest.ScoreOf("X"); res != 10 {
+		t.Error("X should have the higher of 1 and 5*2, not", res)
+	}
+	if res := <-dest.MillisecondsToLive(); res <= 0 || res > 60000 {
+		t.Error("The union should expire within a minute, not", res)
+	}
+
+	if res := <-dest.StoreIntersection().OfSet(a).OfSet(r.SortedSet("Test_StoreWithTTL:Missing")).StoreWithTTL(time.Minute); res != 0 {
+		t.Error("The intersection should be empty, not", res)
+	}
+	if <-dest.Exists() {
+		t.Error("An empty combination should not be stored")
+	}
+}
+
+func TestSortedSetScoresOf(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_ScoresOf")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	<-ss.Add("A", 0)
+	<-ss.Add("B", 2.5)
+
+	res := <-ss.ScoresOf("B", "Missing", "A")
+	if len(res) != 3 || res[0] == nil || *res[0] != 2.5 || res[1] != nil || res[2] == nil || *res[2] != 0 {
+		t.Error("Should get [2.5 nil 0], not", res)
+	}
+	if res, ok := <-ss.ScoresOf(); !ok || len(res) != 0 {
+		t.Error("Should get no scores without any members, not", res)
+	}
+}
+
+func TestSortedSetRandomMembers(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetRandomMembers")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	scores := map[string]float64{"A": 1, "B": 2, "C": 3}
+	for member, score := range scores {
+		<-ss.Add(member, score)
+	}
+
+	if res := <-ss.RandomMember(); scores[res] == 0 {
+		t.Error(res, "is not a member of the zset")
+	}
+	res := <-ss.RandomMembers(5)
+	seen := make(map[string]bool)
+	for _, member := range res {
+		seen[member] = true
+	}
+	if len(res) != 3 || len(seen) != 3 {
+		t.Error("A positive count should give each member at most once, not", res)
+	}
+	if res := <-ss.RandomMembers(-10); len(res) != 10 {
+		t.Error("A negative count should give exactly that many members, not", res)
+	}
+	withScores := <-ss.RandomMembersWithScores(2)
+	if len(withScores) != 2 {
+		t.Error("Should get 2 members with their scores, not", withScores)
+	}
+	for member, score := range withScores {
+		if scores[member] != score {
+			t.Error(member, "should have the score", scores[member], "not", score)
+		}
+	}
+}
+
+func TestSortedSetDifferenceArgs(t *testing.T) {
+	dest := newSortedSet(nil, "Dest")
+	combo := dest.StoreDifference().OfSet(newSortedSet(nil, "A")).OfWeightedSet(newSortedSet(nil, "B"), 2).KeepHigherScore()
+	if res := strings.Join(combo.args("MAX"), " "); res != "ZDIFFSTORE Dest 2 A B" {
+		t.Error("A difference should not have weights or an aggregate, not", res)
+	}
+	if res := strings.Join(combo.resultArgs(), " "); res != "ZDIFF 2 A B" {
+		t.Error("A difference's result should use ZDIFF, not", res)
+	}
+}
+
+func TestSortedSetDifference(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	a := r.SortedSet("Test_Difference:A")
+	b := r.SortedSet("Test_Difference:B")
+	c := r.SortedSet("Test_Difference:C")
+	dest := r.SortedSet("Test_Difference")
+	for i, member := range []string{"V", "W", "X", "Y", "Z"} {
+		<-a.Add(member, float64(i))
+	}
+	<-b.Add("W", 10)
+	<-c.Add("Y", 10)
+	defer func() {
+		<-a.Delete()
+		<-b.Delete()
+		<-c.Delete()
+		<-dest.Delete()
+	}()
+
+	if res := <-a.Difference(b, c); strings.Join(res, "") != "VXZ" {
+		t.Error("Should get [V X Z], not", res)
+	}
+	if res := <-a.DifferenceWithScores(b, c); len(res) != 3 || res[1] != (ScoredMember{"X", 2}) {
+		t.Error("Should get [{V 0} {X 2} {Z 4}], not", res)
+	}
+	if res := <-dest.StoreDifference().OfSet(a).OfWeightedSet(b, 5).UseHigherScore(); res != 4 {
+		t.Error("Should have stored 4 members, not", res)
+	}
+	if res := <-dest.ScoreOf("Z"); res != 4 {
+		t.Error("Z should keep its score of 4, not", res)
+	}
+}
+
+func TestSortedSetUnionAndIntersection(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	a := r.SortedSet("Test_UnionIntersection:A")
+	b := r.SortedSet("Test_UnionIntersection:B")
+	<-a.Add("X", 1)
+	<-a.Add("Y", 2)
+	<-b.Add("Y", 3)
+	<-b.Add("Z", 4)
+	defer func() {
+		<-a.Delete()
+		<-b.Delete()
+	}()
+
+	if res := <-a.Union(b); strings.Join(res, "") != "XZY" {
+		t.Error("Should get [X Z Y], not", res)
+	}
+	if res := <-a.UnionWithScores(b); len(res) != 3 || res[2] != (ScoredMember{"Y", 5}) {
+		t.Error("Should get [{X 1} {Z 4} {Y 5}], not", res)
+	}
+	if res := <-a.Intersection(b); strings.Join(res, "") != "Y" {
+		t.Error("Should get [Y], not", res)
+	}
+	if res := <-a.IntersectionWithScores(b); len(res) != 1 || res[0] != (ScoredMember{"Y", 5}) {
+		t.Error("Should get [{Y 5}], not", res)
+	}
+	if res := <-a.Size(); res != 2 {
+		t.Error("The zset should not have been changed, but it has", res, "members")
+	}
+}
+
+func TestSortedSetResetScores(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_ResetScores")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	<-ss.Add("A", 0)
+	<-ss.Add("B", 5)
+	<-ss.Add("C", 10)
+
+	if res := <-ss.ResetScores(0); res != 2 {
+		t.Error("B and C should have been reset, not", res, "members")
+	}
+	if res := <-ss.Scores().AboveOrEqualTo(0).BelowOrEqualTo(0).Count(); res != 3 {
+		t.Error("Every member should have a score of 0, not just", res)
+	}
+	if res := <-ss.ResetScores(1); res != 3 {
+		t.Error("Every member should have been changed, not", res)
+	}
+	if res := <-r.SortedSet("Test_ResetScoresMissing").ResetScores(0); res != 0 {
+		t.Error("A missing zset has nothing to reset, not", res)
+	}
+}
+
+func TestSortedSetScan(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetScan")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	for i := 0; i < 300; i++ {
+		<-ss.Add("member:"+itoa(i), float64(i))
+	}
+	<-ss.Add("other", 1000)
+
+	seen := make(map[string]float64)
+	scan := ss.Scan().Match("member:*").Count(50)
+	for <-scan.Next() {
+		seen[scan.Member()] = scan.Score()
+	}
+	if len(seen) != 300 {
+		t.Error("Should have seen all 300 matching members, not", len(seen))
+	}
+	if res, ok := seen["member:42"]; !ok || res != 42 {
+		t.Error("member:42 should have been seen with a score of 42, not", res)
+	}
+	if _, ok := seen["other"]; ok {
+		t.Error("Members that don't match should not be seen")
+	}
+
+	if <-r.SortedSet("Test_SortedSetScanMissing").Scan().Next() {
+		t.Error("A missing zset has nothing to scan")
+	}
+}
+
+func TestSortedSetStoreRange(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_StoreRange")
+	dest := r.SortedSet("Test_StoreRange:dest")
+	lex := r.SortedSet("Test_StoreRange:lex")
+	for i, member := range []string{"a", "b", "c", "d", "e"} {
+		<-ss.Add(member, float64(i))
+		<-lex.Add(member, 0)
+	}
+	defer func() {
+		<-ss.Delete()
+		<-dest.Delete()
+		<-lex.Delete()
+	}()
+
+	if res := <-ss.StoreRangeInto(dest, -3, -1); res != 3 {
+		t.Error("Should have stored the top 3, not", res)
+	}
+	if res := <-dest.IndexedBetween(0, -1); strings.Join(res, "") != "cde" {
+		t.Error("Should have stored [c d e], not", res)
+	}
+
+	if res := <-ss.Scores().Above(0).BelowOrEqualTo(3).Reversed().Limit(0, 2).StoreInto(dest); res != 2 {
+		t.Error("Should have stored 2 members by score, not", res)
+	}
+	if res := <-dest.IndexedBetween(0, -1); strings.Join(res, "") != "cd" {
+		t.Error("Should have stored [c d], not", res)
+	}
+
+	if res := <-lex.Lex().AfterOrEqualTo("b").Before("e").StoreInto(dest); res != 3 {
+		t.Error("Should have stored 3 members by name, not", res)
+	}
+	if res := <-dest.IndexedBetween(0, -1); strings.Join(res, "") != "bcd" {
+		t.Error("Should have stored [b c d], not", res)
+	}
+}
+
+func TestSortedSetPages(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetPages")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	for i := 0; i < 7; i++ {
+		<-ss.Add("member:"+itoa(i), float64(i))
+	}
+
+	pager := ss.Pages(3, true)
+	sizes := []int{}
+	previous := 100.0
+	for <-pager.Next() {
+		sizes = append(sizes, len(pager.Page()))
+		for _, member := range pager.Page() {
+			if member.Score >= previous {
+				t.Error("Pages should be in strict reverse order, but", member, "came after", previous)
+			}
+			previous = member.Score
+		}
+	}
+	if len(sizes) != 3 || sizes[0] != 3 || sizes[1] != 3 || sizes[2] != 1 {
+		t.Error("Should have gotten pages of 3, 3 and 1, not", sizes)
+	}
+	if <-pager.Next() {
+		t.Error("A finished pager should stay finished")
+	}
+
+	pager = ss.Pages(7, false)
+	if !<-pager.Next() || len(pager.Page()) != 7 || pager.Page()[0] != (ScoredMember{"member:0", 0}) {
+		t.Error("Should get every member on the first page, not", pager.Page())
+	}
+	if <-pager.Next() {
+		t.Error("There should not be a second page, but got", pager.Page())
+	}
+}
+
+func TestSortedSetIncrementIfPresentOrAbsent(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_IncrementIfPresentOrAbsent")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	if res, ok := <-ss.IncrementIfPresent("A", 1); !ok || res != nil {
+		t.Error("A missing member should give nil, not", res, ok)
+	}
+	if res := <-ss.IncrementIfAbsent("A", 0); res == nil || *res != 0 {
+		t.Error("A should have been added with a score of 0, not", res)
+	}
+	if res, ok := <-ss.IncrementIfAbsent("A", 5); !ok || res != nil {
+		t.Error("An existing member should give nil, not", res, ok)
+	}
+	if res := <-ss.IncrementIfPresent("A", 2.5); res == nil || *res != 2.5 {
+		t.Error("A should have been incremented to 2.5, not", res)
+	}
+}
+
+func TestSortedSetSetScoreIfChanged(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SetScoreIfChanged")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	if !<-ss.SetScoreIfChanged("A", 0) {
+		t.Error("A missing member should always be written")
+	}
+	if <-ss.SetScoreIfChanged("A", 0) {
+		t.Error("An unchanged score should not be written")
+	}
+	if !<-ss.SetScoreIfChanged("A", 0.1) {
+		t.Error("A changed score should be written")
+	}
+	if <-ss.SetScoreIfChanged("A", 0.1) {
+		t.Error("An unchanged fractional score should not be written")
+	}
+	if res := <-ss.ScoreOf("A"); res != 0.1 {
+		t.Error("A should have a score of 0.1, not", res)
+	}
+}
+
+func TestSortedSetIntersectionCardinality(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	a := r.SortedSet("Test_InterCard:A")
+	b := r.SortedSet("Test_InterCard:B")
+	for i := 0; i < 10; i++ {
+		<-a.Add(itoa(i), float64(i))
+		if i%2 == 0 {
+			<-b.Add(itoa(i), float64(i))
+		}
+	}
+	defer func() {
+		<-a.Delete()
+		<-b.Delete()
+	}()
+
+	if res := <-a.IntersectionCardinality(b); res != 5 {
+		t.Error("The intersection should have 5 members, not", res)
+	}
+	if res := <-a.IntersectionCardinalityLimit(3, b); res != 3 {
+		t.Error("Counting should stop at the limit of 3, not", res)
+	}
+	if res := <-a.IntersectionCardinality(b, r.SortedSet("Test_InterCard:Missing")); res != 0 {
+		t.Error("Intersecting with a missing zset should be empty, not", res)
+	}
+}
+
+func TestSortedSetWouldRankAt(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_WouldRankAt")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	for i, member := range []string{"A", "B", "C", "D"} {
+		<-ss.Add(member, float64(i*10))
+	}
+
+	if res := <-ss.WouldRankAt(15); res != 2 {
+		t.Error("A score of 15 should land at index 2, not", res)
+	}
+	if res := <-ss.WouldRankAt(20); res != 2 {
+		t.Error("A score tied with C should land at index 2 at the earliest, not", res)
+	}
+	if res := <-ss.WouldReverseRankAt(15); res != 2 {
+		t.Error("A score of 15 should land at reverse index 2, not", res)
+	}
+	if res := <-ss.WouldReverseRankAt(100); res != 0 {
+		t.Error("A score of 100 should be at the top, not", res)
+	}
+	if res := <-ss.Size(); res != 4 {
+		t.Error("Nothing should have been added, but there are", res, "members")
+	}
+}
+
+func TestSortedSetSync(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+	r.SetErrorCallback(func(e error, s string) {})
+
+	ss := r.SortedSet("Test_SortedSetSync").Sync()
+	ss.Delete()
+	defer ss.Delete()
+
+	if added, err := ss.Add("A", 1); !added || err != nil {
+		t.Error("A should have been added, not", added, err)
+	}
+	if score, err := ss.ScoreOf("A"); score != 1 || err != nil {
+		t.Error("A should have a score of 1, not", score, err)
+	}
+	if _, err := ss.ScoreOf("Missing"); err != ErrNoValue {
+		t.Error("A missing member should give ErrNoValue, not", err)
+	}
+
+	s := r.String("Test_SortedSetSync_String")
+	<-s.Set("A")
+	defer func() { <-s.Delete() }()
+	if _, err := r.SortedSet("Test_SortedSetSync_String").Sync().Size(); err == nil {
+		t.Error("Using a string as a zset should give back an error")
+	}
+}
+
+func TestSortedSetAddMany(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+	config := r.configuration()
+	config.MaxArgsPerCommand = 4
+	small, err := New(config)
+	if err != nil {
+		t.Fatal("Can't load redis - " + err.Error())
+	}
+	defer small.Close()
+
+	ss := small.SortedSet("Test_SortedSetAddMany")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+
+	members := []ScoredMember{{"A", 1}, {"B", 2}, {"C", 3}, {"D", 4}, {"E", 5}}
+	if res := <-ss.AddMany(members...); res != 5 {
+		t.Error("All 5 members should have been added, not", res)
+	}
+	if res := <-ss.RemoveMany("A", "C", "E", "Missing"); res != 3 {
+		t.Error("3 members should have been removed, not", res)
+	}
+	if res := <-ss.IndexedBetween(0, -1); len(res) != 2 || res[0] != "B" || res[1] != "D" {
+		t.Error("Only B and D should be left, not", res)
+	}
+}
+
+func TestSortedSetReplaceAll(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Test_SortedSetReplaceAll")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+	<-ss.Add("Old", 100)
+
+	if res := <-ss.ReplaceAll(map[string]float64{"A": 1, "B": 2}); res != 2 {
+		t.Error("The new size should be 2, not", res)
+	}
+	if res := <-ss.IndexedBetween(0, -1); len(res) != 2 || res[0] != "A" || res[1] != "B" {
+		t.Error("Only the new members should be left, not", res)
+	}
+	if res := <-SliceCommand(r, "KEYS", "Test_SortedSetReplaceAll:replacing:*"); len(res) != 0 {
+		t.Error("The temporary key should be gone, not", res)
+	}
+
+	if res, ok := <-ss.ReplaceAll(nil); !ok || res != 0 {
+		t.Error("Replacing with nothing should empty the zset, not", res, ok)
+	}
+	if res := <-ss.Exists(); res {
+		t.Error("Replacing with nothing should leave no zset behind")
+	}
 }
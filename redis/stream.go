@@ -0,0 +1,148 @@
+package redis
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+//a streamer is a command that reads its own reply straight from the connection, rather than having it read into a response first
+type streamer interface {
+	stream(conn io.Reader) error
+}
+
+//asStreamer finds the streamer within a command, if there is one
+func asStreamer(c command) (streamer, bool) {
+	s, ok := innerCommand(c).(streamer)
+	return s, ok
+}
+
+/*
+
+streamCommand - the command type used when a bulk reply is too large to want to hold in memory all at once
+
+*/
+
+type streamCommand struct {
+	args   []string
+	output chan<- io.ReadCloser
+}
+
+//StreamCommand executes the command specified by the arguments specified.
+//It returns a reader which reads the bulk reply directly from the connection.
+//The connection can't be used for anything else until the reader is closed (or read to the end), so it must always be closed.
+//Inside a Pipeline or Transaction, the reply is read in full like any other reply, and the reader just reads from that
+func StreamCommand(e Executor, args ...string) <-chan io.ReadCloser {
+	c := make(chan io.ReadCloser, 1)
+	e.Execute(streamCommand{args, c})
+	return c
+}
+
+func (this streamCommand) arguments() []string {
+	return this.args
+}
+
+func (this streamCommand) callback() func(*response) error {
+	return func(r *response) error {
+		defer close(this.output)
+		if r != nil {
+			this.output <- io.NopCloser(strings.NewReader(r.val))
+		}
+		return nil
+	}
+}
+
+//stream hands out a reader for the reply, and waits for it to be finished with before returning,
+//so that the connection isn't given to anything else in the meantime
+func (this streamCommand) stream(conn io.Reader) error {
+	defer close(this.output)
+
+	var buffer [1]byte
+	if _, err := conn.Read(buffer[:]); err != nil {
+		return err
+	}
+	switch buffer[0] {
+	case isBulk:
+	case isError:
+		errString, err := getString(conn)
+		if err != nil {
+			return err
+		}
+		return replyError(errString)
+	default:
+		//not a bulk reply, so it isn't big enough to be worth streaming
+		res, err := getRest(buffer[0], conn)
+		if err == nil && res != nil {
+			this.output <- io.NopCloser(strings.NewReader(res.val))
+		}
+		return err
+	}
+
+	line, err := getString(conn)
+	if err != nil {
+		return err
+	}
+	strlen, err := atoi(line)
+	if err != nil {
+		return err
+	}
+	if strlen == -1 {
+		return nil
+	}
+
+	reader := &bulkReader{
+		body: io.LimitReader(conn, int64(strlen)),
+		conn: conn,
+		done: make(chan error, 1),
+	}
+	this.output <- reader
+	return <-reader.done
+}
+
+//getRest reads the rest of a response, once its type has already been read
+func getRest(kind byte, conn io.Reader) (*response, error) {
+	return getResponse(io.MultiReader(bytes.NewReader([]byte{kind}), conn))
+}
+
+//bulkReader reads the body of a bulk reply, and lets the command know once all of it (and the crlf after it) has been read
+type bulkReader struct {
+	body   io.Reader
+	conn   io.Reader
+	done   chan error
+	finish sync.Once
+}
+
+func (this *bulkReader) Read(p []byte) (int, error) {
+	n, err := this.body.Read(p)
+	if err == io.EOF {
+		this.finished()
+	} else if err != nil {
+		this.finish.Do(func() { this.done <- err })
+	}
+	return n, err
+}
+
+//Close skips over whatever hasn't been read yet, so that the connection can be used again
+func (this *bulkReader) Close() error {
+	if _, err := io.Copy(io.Discard, this.body); err != nil {
+		this.finish.Do(func() { this.done <- err })
+		return err
+	}
+	this.finished()
+	return nil
+}
+
+func (this *bulkReader) finished() {
+	this.finish.Do(func() {
+		b := make([]byte, len(delimiter))
+		if _, err := io.ReadFull(this.conn, b); err != nil {
+			this.done <- err
+		} else if !bytes.Equal(b, delimiter) {
+			this.done <- errors.New("Incorrect Redis bulk length")
+		} else {
+			this.done <- nil
+		}
+	})
+}
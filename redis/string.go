@@ -1,5 +1,9 @@
 package redis
 
+import (
+	"io"
+)
+
 //String is an object which implements a basic Redis String primitive
 type String struct {
 	Key
@@ -7,7 +11,7 @@ type String struct {
 
 func newString(client SafeExecutor, key string) String {
 	return String{
-		newKey(client, key),
+		newTypedKey(client, key, KeyTypeString),
 	}
 }
 
@@ -41,6 +45,15 @@ func (this String) Get() <-chan string {
 	return StringCommand(this, this.args("get")...)
 }
 
+//GET command - 
+//GetStream returns a reader which reads the value straight off of the connection, rather than reading it all into memory first;
+//this is worth it for values that are several megabytes.
+//The connection is held until the reader is closed (or read to the end), so it must always be closed.
+//If the key doesn't exist, the channel closes without a value
+func (this String) GetStream() <-chan io.ReadCloser {
+	return StreamCommand(this, this.args("get")...)
+}
+
 //GETSET command - 
 //Replace sets the value of the key and returns its old value
 func (this String) Replace(val string) <-chan string {
@@ -53,6 +66,36 @@ func (this String) Append(val string) <-chan int {
 	return IntCommand(this, this.args("append", val)...)
 }
 
+//GETRANGE command - 
+//GetRange returns the part of the value between the byte offsets given (inclusive).
+//Negative offsets count back from the end, so -1 is the last byte
+func (this String) GetRange(start, end int) <-chan string {
+	return StringCommand(this, this.args("getrange", itoa(start), itoa(end))...)
+}
+
+//SETRANGE command - 
+//SetRange overwrites part of the value, starting at the byte offset given (padding the value with zero bytes if it is too short);
+//returns the new length of the value
+func (this String) SetRange(offset int, val string) <-chan int {
+	return IntCommand(this, this.args("setrange", itoa(offset), val)...)
+}
+
+//ARGV[2] is the command to delete with (DEL or UNLINK)
+const deleteIfEqualsScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call(ARGV[2], KEYS[1])
+end
+return 0
+`
+
+//GET and DEL or UNLINK commands (within a lua script) - 
+//DeleteIfEquals deletes the key, but only if it still holds the value expected, atomically;
+//so a lock (or cache entry) is only released by whoever set it, and never after somebody else has replaced it.
+//Returns whether or not it was deleted
+func (this String) DeleteIfEquals(expected string) <-chan bool {
+	return BoolCommand(this, evalArgs(deleteIfEqualsScript, []string{this.key}, expected, deleteCommand(this.client))...)
+}
+
 //STRLEN command - 
 //Length returns the number of characters in the value of the key
 func (this String) Length() <-chan int {
@@ -1,6 +1,9 @@
 package redis
 
 import (
+	"bytes"
+	"io"
+	"strings"
 	"testing"
 )
 
@@ -51,3 +54,133 @@ func TestStringFuncs(t *testing.T) {
 	}
 
 }
+
+func TestStringGetStream(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	s := r.String("Test_GetStream")
+	<-s.Delete()
+	defer func() { <-s.Delete() }()
+
+	if _, ok := <-s.GetStream(); ok {
+		t.Error("Should not get a reader for a key that doesn't exist")
+	}
+
+	value := strings.Repeat("0123456789", 500000)
+	<-s.Set(value)
+	reader, ok := <-s.GetStream()
+	if !ok {
+		t.Fatal("Should have gotten a reader")
+	}
+	b, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil || string(b) != value {
+		t.Error("Should have streamed the whole value, but got", len(b), "bytes -", err)
+	}
+
+	//a reader that's closed early should still leave the connection usable
+	reader = <-s.GetStream()
+	var partial [10]byte
+	reader.Read(partial[:])
+	reader.Close()
+	if res := <-s.Length(); res != len(value) {
+		t.Error("The connection should still work after closing a reader early, but got", res)
+	}
+}
+
+func TestStreamCommandProtocol(t *testing.T) {
+	conn := bytes.NewBufferString("$11\r\nhello world\r\n+OK\r\n")
+	output := make(chan io.ReadCloser, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- streamCommand{nil, output}.stream(conn)
+	}()
+
+	reader := <-output
+	b, err := io.ReadAll(reader)
+	if err != nil || string(b) != "hello world" {
+		t.Error("Should have read hello world, not", string(b), err)
+	}
+	if err := <-done; err != nil {
+		t.Error("The stream should have finished cleanly -", err)
+	}
+	if res, err := getResponse(conn); err != nil || res.val != "OK" {
+		t.Error("The next reply should be left for the next command, not", res, err)
+	}
+}
+
+func TestStringRanges(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	s := r.String("Test_StringRanges")
+	<-s.Delete()
+	defer func() { <-s.Delete() }()
+
+	<-s.Set("Hello World")
+	if res := <-s.GetRange(0, 4); res != "Hello" {
+		t.Error("Should have gotten Hello, not", res)
+	}
+	if res := <-s.GetRange(-5, -1); res != "World" {
+		t.Error("Should have gotten World, not", res)
+	}
+	if res := <-s.SetRange(6, "Redis"); res != 11 {
+		t.Error("The length should still be 11, not", res)
+	}
+	if res := <-s.Get(); res != "Hello Redis" {
+		t.Error("Should have gotten Hello Redis, not", res)
+	}
+}
+
+func TestAppendLog(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	log := r.String("Test_AppendLog").AsAppendLog()
+	<-log.Delete()
+	defer func() { <-log.Delete() }()
+
+	if res := <-log.ReadFrom(0); len(res) != 0 {
+		t.Error("An empty log should have nothing to read, not", res)
+	}
+	cursor := <-log.Append([]byte("first;"))
+	if cursor != 6 {
+		t.Error("The log should be 6 bytes long, not", cursor)
+	}
+	<-log.Append([]byte("second;"))
+	<-log.Append([]byte{0, 1, 2})
+
+	if res := <-log.ReadFrom(cursor); !bytes.Equal(res, []byte("second;\x00\x01\x02")) {
+		t.Error("Should have read everything after the first entry, not", res)
+	}
+	if res := <-log.ReadFrom(100); len(res) != 0 {
+		t.Error("Reading past the end should give nothing, not", res)
+	}
+}
+
+func TestStringDeleteIfEquals(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	s := r.String("Test_StringDeleteIfEquals")
+	<-s.Delete()
+	defer func() { <-s.Delete() }()
+	<-s.Set("v2")
+
+	if <-s.DeleteIfEquals("v1") {
+		t.Error("Should not delete a value that has been replaced")
+	}
+	if res := <-s.Get(); res != "v2" {
+		t.Error("The value should still be v2, not", res)
+	}
+	if !<-s.DeleteIfEquals("v2") {
+		t.Error("Should delete the value that was expected")
+	}
+	if <-s.Exists() {
+		t.Error("The key should be gone")
+	}
+	if <-s.DeleteIfEquals("v2") {
+		t.Error("A missing key should not count as deleted")
+	}
+}
@@ -0,0 +1,265 @@
+package redis
+
+import (
+	"errors"
+	"sync"
+)
+
+//Message is a single message that was published on a redis channel
+type Message struct {
+	Pattern string //	only set for messages that were received through a pattern subscription
+	Channel string
+	Payload string
+}
+
+//A Subscription listens to any number of redis channels over its own dedicated connection.
+//Unlike a Channel's subscriptions, the channels being listened to can be changed while the Subscription is running
+type Subscription struct {
+	conn     *Connection
+	client   *Client
+	messages chan Message
+	acks     chan nothing //	one for every subscribe or unsubscribe confirmation redis sends back
+	done     chan nothing //	closed when the subscription gets closed
+	writing  sync.Mutex   //	makes sure only one Add or Remove is waiting on confirmations at once
+
+	handling   sync.Mutex //	guards the callbacks below
+	onMessage  func(channel, payload string)
+	onPMessage func(pattern, channel, payload string)
+}
+
+//Subscribe creates a Subscription that is listening to all of the channels specified.
+//The Subscription uses its own connection, rather than one from the pool, so it should be closed when it isn't needed anymore
+func (this *Client) Subscribe(channels ...string) *Subscription {
+	return this.subscribe((*Subscription).Add, channels)
+}
+
+//PSubscribe creates a Subscription that is listening to every channel matching any of the glob-style patterns specified;
+//each Message says which pattern it matched.
+//It is the same as any other Subscription, so channels (and more patterns) can be added to it later
+func (this *Client) PSubscribe(patterns ...string) *Subscription {
+	return this.subscribe((*Subscription).AddPattern, patterns)
+}
+
+//PUBLISH command - 
+//Publish publishes a message on a channel; returns how many subscribers received it
+func (this *Client) Publish(channel, payload string) <-chan int {
+	return this.Channel(channel).Publish(payload)
+}
+
+//subscribe starts a Subscription, then has it start listening with add
+func (this *Client) subscribe(add func(*Subscription, ...string) error, channels []string) *Subscription {
+	sub := &Subscription{
+		client:   this,
+		messages: make(chan Message, messageBufferSize),
+		acks:     make(chan nothing, messageBufferSize),
+		done:     make(chan nothing),
+	}
+
+	conn, err := this.newConnection()
+	if err != nil {
+		this.errCallback(err, "Subscribing")
+		close(sub.messages)
+		close(sub.acks)
+		return sub
+	}
+	sub.conn = conn
+
+	go sub.readLoop()
+
+	if err := add(sub, channels...); err != nil {
+		this.errCallback(err, "Subscribing")
+	}
+	return sub
+}
+
+func (this *Subscription) readLoop() {
+	defer close(this.messages)
+	defer close(this.acks)
+	for {
+		response, err := getResponse(this.conn)
+		if err != nil {
+			select {
+			case <-this.done:
+				//we closed the connection ourselves, this is expected
+			default:
+				this.client.errCallback(err, "Subscription Message Loop")
+			}
+			return
+		}
+		if response == nil || len(response.subresponses) < 3 || response.subresponses[0] == nil {
+			continue
+		}
+
+		switch response.subresponses[0].val {
+		case "subscribe", "unsubscribe", "psubscribe", "punsubscribe":
+			this.acks <- nothing{}
+		case "message":
+			if response.subresponses[1] != nil && response.subresponses[2] != nil {
+				this.deliver(Message{
+					Channel: response.subresponses[1].val,
+					Payload: response.subresponses[2].val,
+				})
+			}
+		case "pmessage":
+			if len(response.subresponses) >= 4 && response.subresponses[1] != nil && response.subresponses[2] != nil && response.subresponses[3] != nil {
+				this.deliver(Message{
+					Pattern: response.subresponses[1].val,
+					Channel: response.subresponses[2].val,
+					Payload: response.subresponses[3].val,
+				})
+			}
+		}
+	}
+}
+
+//deliver hands a message to its callback if one has been registered, otherwise it goes to the Messages channel
+func (this *Subscription) deliver(message Message) {
+	this.handling.Lock()
+	onMessage, onPMessage := this.onMessage, this.onPMessage
+	this.handling.Unlock()
+
+	switch {
+	case message.Pattern == "" && onMessage != nil:
+		onMessage(message.Channel, message.Payload)
+	case message.Pattern != "" && onPMessage != nil:
+		onPMessage(message.Pattern, message.Channel, message.Payload)
+	default:
+		this.messages <- message
+	}
+}
+
+func (this *Subscription) issue(command string, channels []string) error {
+	if len(channels) == 0 {
+		return nil
+	}
+
+	this.writing.Lock()
+	defer this.writing.Unlock()
+
+	if this.conn == nil {
+		return errors.New("Subscription never connected")
+	}
+	select {
+	case <-this.done:
+		return errors.New("Subscription is already closed")
+	default:
+	}
+
+	comm, err := buildCommand(append([]string{command}, channels...))
+	if err != nil {
+		return err
+	}
+	if _, err = this.conn.Write(comm); err != nil {
+		return err
+	}
+
+	//redis confirms each channel separately
+	for range channels {
+		if _, ok := <-this.acks; !ok {
+			return errors.New("Subscription closed before redis confirmed the " + command)
+		}
+	}
+	return nil
+}
+
+//SUBSCRIBE command - 
+//Add starts listening to more channels; it returns once redis has confirmed that it is listening to them
+func (this *Subscription) Add(channels ...string) error {
+	return this.issue("SUBSCRIBE", channels)
+}
+
+//UNSUBSCRIBE command - 
+//Remove stops listening to some channels; it returns once redis has confirmed that it is no longer listening to them
+func (this *Subscription) Remove(channels ...string) error {
+	return this.issue("UNSUBSCRIBE", channels)
+}
+
+//PSUBSCRIBE command - 
+//AddPattern starts listening to every channel matching the patterns; it returns once redis has confirmed that it is listening to them
+func (this *Subscription) AddPattern(patterns ...string) error {
+	return this.issue("PSUBSCRIBE", patterns)
+}
+
+//PUNSUBSCRIBE command - 
+//RemovePattern stops listening to some patterns; it returns once redis has confirmed that it is no longer listening to them
+func (this *Subscription) RemovePattern(patterns ...string) error {
+	return this.issue("PUNSUBSCRIBE", patterns)
+}
+
+//OnMessage registers a function to be called with every message received on a channel that was subscribed to directly.
+//Once it is set, those messages no longer go through Messages.
+//The callbacks are run from the Subscription's read loop, so they shouldn't call Add or Remove themselves, and a slow callback holds up every message after it.
+//Passing nil goes back to sending the messages through Messages
+func (this *Subscription) OnMessage(callback func(channel, payload string)) {
+	this.handling.Lock()
+	defer this.handling.Unlock()
+	this.onMessage = callback
+}
+
+//OnPMessage registers a function to be called with every message received through a pattern subscription.
+//It works the same way as OnMessage, but is also given the pattern that matched
+func (this *Subscription) OnPMessage(callback func(pattern, channel, payload string)) {
+	this.handling.Lock()
+	defer this.handling.Unlock()
+	this.onPMessage = callback
+}
+
+//Messages returns the channel that every message received gets sent through.
+//It gets closed when the Subscription is closed.
+//If the messages aren't read, Add and Remove can end up waiting for them to be read before they see their confirmations
+func (this *Subscription) Messages() <-chan Message {
+	return this.messages
+}
+
+//BinaryMessage is a single message that was published on a redis channel, with its payload left as raw bytes (ie, for serialized events)
+type BinaryMessage struct {
+	Pattern string //	only set for messages that were received through a pattern subscription
+	Channel string
+	Payload []byte
+}
+
+//A BinarySubscription is a Subscription whose messages come through as BinaryMessages.
+//Everything but Messages works the same way as it does on a Subscription,
+//but setting OnMessage or OnPMessage takes those messages away from Messages, the same as it would on a Subscription
+type BinarySubscription struct {
+	*Subscription
+	messages chan BinaryMessage
+}
+
+//SubscribeBinary creates a BinarySubscription that is listening to all of the channels specified.
+//Payloads are delivered byte for byte as they were published, including any null bytes or invalid UTF-8.
+//Like Subscribe, it uses its own connection, so it should be closed when it isn't needed anymore
+func (this *Client) SubscribeBinary(channels ...string) *BinarySubscription {
+	sub := &BinarySubscription{
+		Subscription: this.Subscribe(channels...),
+		messages:     make(chan BinaryMessage, messageBufferSize),
+	}
+	go func() {
+		defer close(sub.messages)
+		for message := range sub.Subscription.messages {
+			sub.messages <- BinaryMessage{message.Pattern, message.Channel, []byte(message.Payload)}
+		}
+	}()
+	return sub
+}
+
+//Messages returns the channel that every message received gets sent through.
+//It gets closed when the BinarySubscription is closed
+func (this *BinarySubscription) Messages() <-chan BinaryMessage {
+	return this.messages
+}
+
+//Close stops listening to every channel, and closes the Subscription's connection
+func (this *Subscription) Close() error {
+	select {
+	case <-this.done:
+		return errors.New("Already closed this subscription")
+	default:
+	}
+	close(this.done)
+
+	if this.conn == nil {
+		return nil
+	}
+	return this.conn.Close()
+}
@@ -0,0 +1,185 @@
+package redis
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubscription(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	a := r.Channel("Test_Subscription_A")
+	b := r.Channel("Test_Subscription_B")
+
+	sub := r.Subscribe("Test_Subscription_A")
+
+	receive := func(expected Message) {
+		select {
+		case m, ok := <-sub.Messages():
+			if !ok {
+				t.Error("Messages should not be closed yet")
+			} else if m != expected {
+				t.Error("Should receive", expected, "not", m)
+			}
+		case <-time.After(2 * time.Second):
+			t.Error("Never received", expected)
+		}
+	}
+
+	if res := <-a.Publish("first"); res != 1 {
+		t.Error("Should have 1 listener on A, not", res)
+	}
+	receive(Message{Channel: "Test_Subscription_A", Payload: "first"})
+
+	if err := sub.Add("Test_Subscription_B"); err != nil {
+		t.Error("Should be able to add a channel -", err)
+	}
+	if res := <-b.Publish("second"); res != 1 {
+		t.Error("Should have 1 listener on B, not", res)
+	}
+	receive(Message{Channel: "Test_Subscription_B", Payload: "second"})
+
+	if err := sub.Remove("Test_Subscription_A"); err != nil {
+		t.Error("Should be able to remove a channel -", err)
+	}
+	if res := <-a.Publish("third"); res != 0 {
+		t.Error("Should not have any listeners on A anymore, not", res)
+	}
+	if res := <-b.Publish("fourth"); res != 1 {
+		t.Error("Should still have 1 listener on B, not", res)
+	}
+	receive(Message{Channel: "Test_Subscription_B", Payload: "fourth"})
+
+	if err := sub.Close(); err != nil {
+		t.Error("Should be able to close the subscription -", err)
+	}
+	if err := sub.Close(); err == nil {
+		t.Error("Should not be able to close the subscription twice")
+	}
+	if err := sub.Add("Test_Subscription_A"); err == nil {
+		t.Error("Should not be able to add channels to a closed subscription")
+	}
+	select {
+	case _, ok := <-sub.Messages():
+		if ok {
+			t.Error("Should not receive any more messages")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Messages should be closed after closing the subscription")
+	}
+}
+
+func TestSubscriptionCallbacks(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	messages := make(chan Message, 2)
+	sub := r.Subscribe()
+	defer sub.Close()
+	sub.OnMessage(func(channel, payload string) {
+		messages <- Message{Channel: channel, Payload: payload}
+	})
+	sub.OnPMessage(func(pattern, channel, payload string) {
+		messages <- Message{Pattern: pattern, Channel: channel, Payload: payload}
+	})
+
+	if err := sub.Add("Test_Callbacks_A"); err != nil {
+		t.Error("Should be able to add a channel -", err)
+	}
+	if err := sub.AddPattern("Test_Callbacks_P*"); err != nil {
+		t.Error("Should be able to add a pattern -", err)
+	}
+
+	receive := func(expected Message) {
+		select {
+		case m := <-messages:
+			if m != expected {
+				t.Error("Should receive", expected, "not", m)
+			}
+		case <-time.After(2 * time.Second):
+			t.Error("Never received", expected)
+		}
+	}
+
+	<-r.Channel("Test_Callbacks_A").Publish("direct")
+	receive(Message{Channel: "Test_Callbacks_A", Payload: "direct"})
+
+	<-r.Channel("Test_Callbacks_P1").Publish("matched")
+	receive(Message{Pattern: "Test_Callbacks_P*", Channel: "Test_Callbacks_P1", Payload: "matched"})
+
+	if err := sub.RemovePattern("Test_Callbacks_P*"); err != nil {
+		t.Error("Should be able to remove a pattern -", err)
+	}
+	if res := <-r.Channel("Test_Callbacks_P1").Publish("ignored"); res != 0 {
+		t.Error("Should not have any listeners on the pattern anymore, not", res)
+	}
+}
+
+func TestPSubscribe(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	sub := r.PSubscribe("Test_PSubscribe_*")
+	defer sub.Close()
+
+	if res := <-r.Publish("Test_PSubscribe_A", "hello"); res != 1 {
+		t.Error("The pattern subscription should have received the message, but", res, "did")
+	}
+	select {
+	case m := <-sub.Messages():
+		if m != (Message{Pattern: "Test_PSubscribe_*", Channel: "Test_PSubscribe_A", Payload: "hello"}) {
+			t.Error("Should have received hello through the pattern, not", m)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Never received the message")
+	}
+
+	if res := <-r.Publish("Test_Other", "ignored"); res != 0 {
+		t.Error("Nobody should be listening on a channel that doesn't match, but", res, "were")
+	}
+}
+
+func TestSubscribeBinary(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	payload := []byte{0, 1, 255, '\r', '\n', 0, 0xc3, 0x28}
+	sub := r.SubscribeBinary("Test_SubscribeBinary")
+	if res := <-r.Channel("Test_SubscribeBinary").PublishBytes(payload); res != 1 {
+		t.Error("Should have 1 listener, not", res)
+	}
+
+	select {
+	case m, ok := <-sub.Messages():
+		if !ok || m.Channel != "Test_SubscribeBinary" || !bytes.Equal(m.Payload, payload) {
+			t.Error("Should receive", payload, "byte for byte, not", m)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Never received the binary message")
+	}
+
+	sub.Close()
+	select {
+	case _, ok := <-sub.Messages():
+		if ok {
+			t.Error("Should not receive any more messages")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Messages should be closed after closing the subscription")
+	}
+}
+
+func TestBinaryMessageParsing(t *testing.T) {
+	payload := "\x00\xff\r\n\x00"
+	frame := "*3\r\n$7\r\nmessage\r\n$1\r\nc\r\n$5\r\n" + payload + "\r\n"
+	res, err := getResponse(strings.NewReader(frame))
+	if err != nil || res == nil || len(res.subresponses) != 3 {
+		t.Fatal("Should have parsed the message frame, not", res, err)
+	}
+	if got := res.subresponses[2].val; !bytes.Equal([]byte(got), []byte(payload)) {
+		t.Error("The payload should be parsed byte for byte, not", []byte(got))
+	}
+}
@@ -0,0 +1,149 @@
+package redis
+
+//A SyncSortedSet is a zset whose methods wait for redis and give back the result directly, along with any error, instead of a channel.
+//It's meant for scripts and tests, where there's nothing else to do while waiting;
+//if a command gives back nothing without failing (like a member that isn't there), the error is ErrNoValue
+type SyncSortedSet struct {
+	set SortedSet
+}
+
+//Sync gives a blocking view of this zset; see SyncSortedSet.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this SortedSet) Sync() SyncSortedSet {
+	return SyncSortedSet{this}
+}
+
+//Async gives back the regular zset, whose methods return channels.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this SyncSortedSet) Async() SortedSet {
+	return this.set
+}
+
+//collect gives the zset on a fresh ErrorCollector, so each call only sees its own errors
+func (this SyncSortedSet) collect() (SortedSet, *ErrorCollector) {
+	c := CollectErrors(this.set.client)
+	return this.set.Use(c), c
+}
+
+//syncResult works out the error for a call, from whether it got anything off of its channel
+func syncResult(c *ErrorCollector, ok bool) error {
+	if err := c.LastError(); err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNoValue
+	}
+	return nil
+}
+
+//EXISTS command - 
+//Exists returns whether there is a zset at this key
+func (this SyncSortedSet) Exists() (bool, error) {
+	set, c := this.collect()
+	res, ok := <-set.Exists()
+	return res, syncResult(c, ok)
+}
+
+//DEL command - 
+//Delete deletes the zset; returns whether it was there to be deleted
+func (this SyncSortedSet) Delete() (bool, error) {
+	set, c := this.collect()
+	res, ok := <-set.Delete()
+	return res, syncResult(c, ok)
+}
+
+//ZADD command - 
+//Add adds a member to the zset or updates its score; returns whether it was a new member
+func (this SyncSortedSet) Add(item string, score float64) (bool, error) {
+	set, c := this.collect()
+	res, ok := <-set.Add(item, score)
+	return res, syncResult(c, ok)
+}
+
+//ZREM command - 
+//Remove removes a member from the zset; returns whether it was there to be removed
+func (this SyncSortedSet) Remove(item string) (bool, error) {
+	set, c := this.collect()
+	res, ok := <-set.Remove(item)
+	return res, syncResult(c, ok)
+}
+
+//ZINCRBY command - 
+//IncrementBy increments the score of a member; returns the new score
+func (this SyncSortedSet) IncrementBy(item string, score float64) (float64, error) {
+	set, c := this.collect()
+	res, ok := <-set.IncrementBy(item, score)
+	return res, syncResult(c, ok)
+}
+
+//ZCARD command - 
+//Size returns the number of members of the zset
+func (this SyncSortedSet) Size() (int, error) {
+	set, c := this.collect()
+	res, ok := <-set.Size()
+	return res, syncResult(c, ok)
+}
+
+//ZSCORE command - 
+//ScoreOf returns the score of a member; gives ErrNoValue if it isn't in the zset
+func (this SyncSortedSet) ScoreOf(item string) (float64, error) {
+	set, c := this.collect()
+	res, ok := <-set.ScoreOf(item)
+	return res, syncResult(c, ok)
+}
+
+//ZMSCORE command - 
+//ScoresOf returns the score of every member given, in the same order, with nil for any member that isn't in the zset
+func (this SyncSortedSet) ScoresOf(members ...string) ([]*float64, error) {
+	set, c := this.collect()
+	res, ok := <-set.ScoresOf(members...)
+	return res, syncResult(c, ok)
+}
+
+//ZRANK command - 
+//IndexOf returns the index of a member, counting up from the lowest score; gives ErrNoValue if it isn't in the zset
+func (this SyncSortedSet) IndexOf(item string) (int, error) {
+	set, c := this.collect()
+	res, ok := <-set.IndexOf(item)
+	return res, syncResult(c, ok)
+}
+
+//ZREVRANK command - 
+//ReverseIndexOf returns the index of a member, counting down from the highest score; gives ErrNoValue if it isn't in the zset
+func (this SyncSortedSet) ReverseIndexOf(item string) (int, error) {
+	set, c := this.collect()
+	res, ok := <-set.ReverseIndexOf(item)
+	return res, syncResult(c, ok)
+}
+
+//ZRANGE command - 
+//IndexedBetween returns all members between the indices
+func (this SyncSortedSet) IndexedBetween(start, stop int) ([]string, error) {
+	set, c := this.collect()
+	res, ok := <-set.IndexedBetween(start, stop)
+	return res, syncResult(c, ok)
+}
+
+//ZREVRANGE command - 
+//ReverseIndexedBetween returns all members between the reverse indices
+func (this SyncSortedSet) ReverseIndexedBetween(start, stop int) ([]string, error) {
+	set, c := this.collect()
+	res, ok := <-set.ReverseIndexedBetween(start, stop)
+	return res, syncResult(c, ok)
+}
+
+//ZRANGE WITHSCORES command - 
+//IndexedBetweenWithScores returns all members between the indices along with their scores
+func (this SyncSortedSet) IndexedBetweenWithScores(start, stop int) (map[string]float64, error) {
+	set, c := this.collect()
+	res, ok := <-set.IndexedBetweenWithScores(start, stop)
+	return res, syncResult(c, ok)
+}
+
+//ZREVRANGE WITHSCORES command - 
+//ReverseIndexedBetweenWithScores returns all members between the reverse indices along with their scores
+func (this SyncSortedSet) ReverseIndexedBetweenWithScores(start, stop int) (map[string]float64, error) {
+	set, c := this.collect()
+	res, ok := <-set.ReverseIndexedBetweenWithScores(start, stop)
+	return res, syncResult(c, ok)
+}
@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"time"
+)
+
+//a throttle is a token bucket that limits how quickly commands can be sent to redis.
+//It starts full, and refills at a steady rate until it holds a full second's worth of tokens
+type throttle struct {
+	tokens chan nothing
+	stop   chan nothing
+}
+
+func newThrottle(perSecond int) *throttle {
+	this := &throttle{
+		tokens: make(chan nothing, perSecond),
+		stop:   make(chan nothing),
+	}
+	for i := 0; i < perSecond; i++ {
+		this.tokens <- nothing{}
+	}
+
+	interval := time.Second / time.Duration(perSecond)
+	if interval <= 0 {
+		interval = time.Nanosecond
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		//closing the tokens releases anybody still waiting once we've stopped
+		defer close(this.tokens)
+		for {
+			select {
+			case <-ticker.C:
+				select {
+				case this.tokens <- nothing{}:
+				default:
+					//the bucket is already full
+				}
+			case <-this.stop:
+				return
+			}
+		}
+	}()
+	return this
+}
+
+//wait blocks until "count" commands are allowed to be sent
+//a nil throttle never blocks
+func (this *throttle) wait(count int) {
+	this.waitUntil(nil, count)
+}
+
+//waitUntil is like wait, but gives up once "done" is closed;
+//returns whether or not the commands are allowed to be sent
+func (this *throttle) waitUntil(done <-chan struct{}, count int) bool {
+	if this == nil {
+		return true
+	}
+	for i := 0; i < count; i++ {
+		select {
+		case <-this.tokens:
+		case <-done:
+			return false
+		}
+	}
+	return true
+}
+
+func (this *throttle) Close() {
+	if this == nil {
+		return
+	}
+	close(this.stop)
+}
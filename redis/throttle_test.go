@@ -0,0 +1,50 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThrottle(t *testing.T) {
+	th := newThrottle(10)
+	defer th.Close()
+
+	start := time.Now()
+	th.wait(10)
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Error("A full bucket should not make us wait, but waited", elapsed)
+	}
+
+	start = time.Now()
+	th.wait(5)
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond || elapsed > 700*time.Millisecond {
+		t.Error("Should have waited about half a second for 5 more tokens, not", elapsed)
+	}
+
+	var none *throttle
+	start = time.Now()
+	none.wait(1000)
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Error("A nil throttle should never wait, but waited", elapsed)
+	}
+}
+
+func TestThrottledClient(t *testing.T) {
+	config := DefaultConfiguration()
+	config.ConnectionCount = 1
+	config.MaxCommandsPerSecond = 10
+	r, err := New(config)
+	if err != nil {
+		t.Fatal("Can't load redis - " + err.Error())
+	}
+	defer r.Close()
+
+	s := r.String("Test_Throttle")
+	start := time.Now()
+	for i := 0; i < 15; i++ {
+		<-s.Set("A")
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Error("15 commands at 10 per second should take about half a second, not", elapsed)
+	}
+}
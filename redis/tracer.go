@@ -0,0 +1,114 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+//A Tracer is told about each command a Client sends, so that every command can be followed as a span (ie, with OpenTelemetry).
+//StartSpan is given the context the command was issued with (the ContextClient's, or context.Background() for a Client), so that the span can be started as a child of one already in it,
+//the name of the command (ie, "SET"), and its attributes:
+//	"db.system" - always "redis"
+//	"db.redis.key" - the key the command was issued for, if it was issued through one of the primitives
+//	"db.redis.database_index" - the DBid from the config
+//	"db.redis.args" - how many arguments were sent along with the command's name
+type Tracer interface {
+	StartSpan(ctx context.Context, name string, attributes map[string]interface{}) Span
+}
+
+//A Span follows a single command: any error the command runs into is recorded on it, and it is ended once the command has finished,
+//so that its duration covers waiting for a connection, sending the command, and reading the reply
+type Span interface {
+	RecordError(error)
+	End()
+}
+
+//WithTracer has every command this client sends (including the ones sent through a ContextClient made from it) start a span on the tracer; a nil tracer stops the tracing.
+//The Tracer interface is kept small so that this package doesn't need to depend on OpenTelemetry itself; an OpenTelemetry tracer can be adapted to it like
+//	type otelTracer struct{ trace.Tracer }
+//	func (t otelTracer) StartSpan(ctx context.Context, name string, attributes map[string]interface{}) redis.Span {
+//		_, span := t.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+//		for k, v := range attributes {
+//			span.SetAttributes(attribute.String(k, fmt.Sprint(v)))
+//		}
+//		return otelSpan{span}
+//	}
+//	type otelSpan struct{ trace.Span }
+//	func (s otelSpan) RecordError(e error) { s.Span.RecordError(e); s.Span.SetStatus(codes.Error, e.Error()) }
+//	func (s otelSpan) End()                { s.Span.End() }
+//Only commands sent one at a time are traced; the ones sent together (in a Pipeline, Batch, or Transaction) are not.
+//Like the error callback, it should be set up before the client is put to use.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *Client) WithTracer(tracer Tracer) *Client {
+	this.tracer = tracer
+	return this
+}
+
+//trace starts a span for a command, if the client has a tracer;
+//gives back the command to send in its place, along with what to call once it has finished
+func (this *Client) trace(ctx context.Context, c command) (command, func()) {
+	if this.tracer == nil {
+		return c, func() {}
+	}
+
+	args := c.arguments()
+	name := ""
+	if len(args) > 0 {
+		name = strings.ToUpper(args[0])
+	}
+	attributes := map[string]interface{}{
+		"db.system":               "redis",
+		"db.redis.database_index": this.config.DBid,
+		"db.redis.args":           len(args) - 1,
+	}
+	if key, ok := commandKey(c); ok {
+		attributes["db.redis.key"] = key
+	}
+
+	span := this.tracer.StartSpan(ctx, name, attributes)
+	return tracedCommand{c, span, new(sync.Once)}, span.End
+}
+
+//commandKey gives the key a command was issued for, looking past anything else that was wrapped around it
+func commandKey(c command) (string, bool) {
+	for {
+		switch w := c.(type) {
+		case keyedCommand:
+			return w.key, true
+		case checkedCommand:
+			c = w.command
+		case tracedCommand:
+			c = w.command
+		default:
+			return "", false
+		}
+	}
+}
+
+//tracedCommand is a command with a span following it.
+//Like a checkedCommand, it can hear about the same failure more than once, so only the first is recorded on the span
+type tracedCommand struct {
+	command
+	span Span
+	once *sync.Once
+}
+
+func (this tracedCommand) recordError(e error) {
+	if !recordable(this.command, e) {
+		return
+	}
+	this.once.Do(func() {
+		this.span.RecordError(e)
+	})
+	if r, ok := this.command.(errorRecorder); ok {
+		r.recordError(e)
+	}
+}
+
+func (this tracedCommand) wrapError(e error) error {
+	if w, ok := this.command.(errorWrapper); ok {
+		return w.wrapError(e)
+	}
+	return e
+}
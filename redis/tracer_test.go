@@ -0,0 +1,152 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSpan struct {
+	name       string
+	attributes map[string]interface{}
+	ctx        context.Context
+	mutex      sync.Mutex
+	errors     []error
+	ended      chan struct{}
+}
+
+func (this *fakeSpan) RecordError(e error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.errors = append(this.errors, e)
+}
+
+func (this *fakeSpan) End() {
+	close(this.ended)
+}
+
+//wait gives back the errors recorded on the span, once it has ended
+func (this *fakeSpan) wait(t *testing.T) []error {
+	select {
+	case <-this.ended:
+	case <-time.After(time.Second):
+		t.Fatal("The span", this.name, "should have ended")
+	}
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.errors
+}
+
+type fakeTracer struct {
+	spans chan *fakeSpan
+}
+
+func (this fakeTracer) StartSpan(ctx context.Context, name string, attributes map[string]interface{}) Span {
+	span := &fakeSpan{name: name, attributes: attributes, ctx: ctx, ended: make(chan struct{})}
+	this.spans <- span
+	return span
+}
+
+//getTracedRedis gives a client (with a tracer) connected to a stand in for redis, which refuses any command mentioning "refused", and answers OK to everything else
+func getTracedRedis(t *testing.T) (*Client, fakeTracer, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Can't listen -", err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buffer := make([]byte, 256)
+				for {
+					n, err := conn.Read(buffer)
+					if err != nil {
+						return
+					}
+					if bytes.Contains(buffer[:n], []byte("refused")) {
+						conn.Write([]byte("-ERR refused\r\n"))
+					} else {
+						conn.Write([]byte("+OK\r\n"))
+					}
+				}
+			}()
+		}
+	}()
+
+	config := DefaultConfiguration()
+	config.NetAddress = listener.Addr().String()
+	config.ConnectionCount = 1
+	r, err := New(config)
+	if err != nil {
+		listener.Close()
+		t.Fatal("Can't connect -", err)
+	}
+	r.SetErrorCallback(func(error, string) {})
+
+	tracer := fakeTracer{make(chan *fakeSpan, 10)}
+	r.WithTracer(tracer)
+	return r, tracer, func() {
+		r.Close()
+		listener.Close()
+	}
+}
+
+func TestTracer(t *testing.T) {
+	r, tracer, done := getTracedRedis(t)
+	defer done()
+
+	<-r.String("Test_Tracer").Set("value")
+	span := <-tracer.spans
+	if errs := span.wait(t); len(errs) != 0 {
+		t.Error("A successful command should not record any errors, not", errs)
+	}
+	if span.name != "SET" {
+		t.Error("The span should be named after the command, not", span.name)
+	}
+	if span.attributes["db.redis.key"] != "Test_Tracer" || span.attributes["db.redis.args"] != 2 || span.attributes["db.redis.database_index"] != 0 {
+		t.Error("The span should have the key, arg count, and db as attributes, not", span.attributes)
+	}
+
+	<-r.String("refused").Set("value")
+	span = <-tracer.spans
+	if errs := span.wait(t); len(errs) != 1 || errs[0].Error() != "ERR refused" {
+		t.Error("A refused command should record its error once, not", errs)
+	}
+}
+
+func TestTracerContext(t *testing.T) {
+	r, tracer, done := getTracedRedis(t)
+	defer done()
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "parent")
+	<-r.WithContext(ctx).String("Test_TracerContext").Set("value")
+	span := <-tracer.spans
+	span.wait(t)
+	if span.ctx.Value(ctxKey{}) != "parent" {
+		t.Error("The span should be started from the ContextClient's context")
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	<-r.WithContext(canceled).String("Test_TracerContext").Set("value")
+	span = <-tracer.spans
+	if errs := span.wait(t); len(errs) != 1 {
+		t.Error("A canceled command should record that it failed, not", errs)
+	}
+
+	r.WithTracer(nil)
+	<-r.String("Test_TracerContext").Set("value")
+	select {
+	case span := <-tracer.spans:
+		t.Error("Nothing should be traced without a tracer, not", span.name)
+	default:
+	}
+}
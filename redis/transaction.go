@@ -1,10 +1,14 @@
 package redis
 
-import ()
+import (
+	"errors"
+	"sync"
+)
 
 type pipe struct {
 	commands     []command
 	fErrCallback errCallbackFunc
+	config       Config
 }
 
 func (this *pipe) Execute(command command) {
@@ -15,48 +19,156 @@ func (this *pipe) errCallback(err error, s string) {
 	this.fErrCallback.Call(err, s)
 }
 
+func (this *pipe) configuration() Config {
+	return this.config
+}
+
 func (this Client) piping(callback func(SafeExecutor) bool, queued bool) {
-	p := new(pipe)
-	p.commands = make([]command, 0, 5)
-	p.fErrCallback = this.fErrCallback
+	p := this.newPipe()
 	var result bool
 	defer func() {
-		var bundle []byte
-		for _, command := range p.commands {
-			comm, err := buildCommand(command.arguments())
-			if err != nil {
-				this.errCallback(err, "piping")
-			}
-			bundle = append(bundle, comm...)
-		}
-		this.useConnection(func(c *Connection) {
-			c.Write(bundle)
-			if !result {
-				//everything was discarded - just get basic result and don't bother waiting for everything else
-				getResponse(c)
-				return
-			}
-			if queued {
-				//get rid of all of the "queued" responses
-				for i := 0; i < len(p.commands)-1; i++ {
-					getResponse(c)
-				}
-				//the first reply is going to be a multi-bulk, with all of the other replies as subresponses
-				//get rid of the multi-bulk, and just get the other replies as normal
-				//(this is a little bit hacky, perhaps I'll make it less so in future versions)
-				getString(c)
-				p.commands = p.commands[1 : len(p.commands)-1]
-			}
+		if !result {
+			//everything was discarded - there's no need to send any of it
 			for _, command := range p.commands {
-				c.output(command)
+				fail(command, errDiscarded)
 			}
-		})
+			return
+		}
+		this.send(p.commands, queued)
 	}()
 	result = callback(p)
 }
 
+func (this Client) newPipe() *pipe {
+	p := new(pipe)
+	p.commands = make([]command, 0, 5)
+	p.fErrCallback = this.fErrCallback
+	p.config = this.config
+	return p
+}
+
+//send writes all of the commands at once, then reads each of their replies.
+//If queued, they are a MULTI ... EXEC transaction, and the replies are read from within EXEC's
+func (this Client) send(commands []command, queued bool) {
+	var bundle []byte
+	for _, command := range commands {
+		comm, err := buildCommand(command.arguments())
+		if err != nil {
+			this.errCallback(err, "piping")
+		}
+		bundle = append(bundle, comm...)
+	}
+	this.limiter.wait(len(commands))
+	err := this.useConnection(func(c *Connection) {
+		c.Write(bundle)
+		if queued {
+			if err := this.execute(c, commands[1:len(commands)-1]); err != nil {
+				this.errCallback(err, "EXEC")
+			}
+			return
+		}
+		for _, command := range commands {
+			c.output(command)
+		}
+	})
+	if err != nil {
+		for _, command := range commands {
+			fail(command, err)
+		}
+		this.errCallback(err, "piping")
+	}
+}
+
+//errDiscarded is given to the commands of a transaction that was discarded
+var errDiscarded = errors.New("the transaction was discarded")
+
+//execute reads the replies to a MULTI, the commands queued after it, and the EXEC that ends it.
+//Each command is given its reply from within EXEC's reply, unless the transaction never ran
+//(a command couldn't be queued, or a watched key changed), in which case they are all failed;
+//returns why the transaction didn't run (errDiscarded if a watched key changed), or nil if it did
+func (this Client) execute(c *Connection, commands []command) error {
+	if _, err := c.response(); err != nil {
+		//MULTI itself didn't work, so everything after it was run as normal, and EXEC will fail
+		for _, command := range commands {
+			c.output(command)
+		}
+		c.response()
+		return err
+	}
+
+	var aborted error
+	for _, command := range commands {
+		if _, err := c.response(); err != nil {
+			this.commandError(err, command)
+			aborted = err
+		}
+	}
+
+	var kind [1]byte
+	if _, err := c.Read(kind[:]); c.check(err) != nil {
+		for _, command := range commands {
+			fail(command, err)
+		}
+		return err
+	}
+	line, err := getString(c)
+	c.check(err)
+	if err == nil && kind[0] == isError {
+		err = errors.New(line)
+	} else if err == nil && (kind[0] != isMultibulk || line == "-1") {
+		//a null reply means a watched key was changed, so nothing ran
+		err = errDiscarded
+	}
+	if err != nil {
+		if aborted != nil {
+			err = aborted
+		}
+		for _, command := range commands {
+			fail(command, err)
+		}
+		return err
+	}
+
+	//EXEC's reply is a multi-bulk with all of the other replies as its subresponses,
+	//so with its header out of the way the other replies can be read as normal
+	for _, command := range commands {
+		c.output(command)
+	}
+	return nil
+}
+
+//pipelined runs the commands issued by the callback in a Pipeline when the executor is a Client.
+//Any other executor is already batching its commands (or managing its own connections), so they are just issued on it directly
+func pipelined(e SafeExecutor, callback func(SafeExecutor)) {
+	switch client := e.(type) {
+	case *Client:
+		client.Pipeline(callback)
+	case Client:
+		client.Pipeline(callback)
+	default:
+		callback(e)
+	}
+}
+
+//pipelinedInBackground is like pipelined, but doesn't hold up the caller while a Client's pipeline makes its round trip:
+//the pipeline is run in a goroutine, which goes on to call gather once every command has been issued.
+//Any other executor has the commands issued on it right away (so that they make it into its batch), and gather is still called in a goroutine
+func pipelinedInBackground(e SafeExecutor, callback func(SafeExecutor), gather func()) {
+	switch e.(type) {
+	case *Client, Client:
+		go func() {
+			pipelined(e, callback)
+			gather()
+		}()
+	default:
+		callback(e)
+		go gather()
+	}
+}
+
 //Pipeline creates an Executor that will force every command issued on it to be sent at the same time (thus saving on network costs).
-//It waits until the end of the function to execute them
+//It waits until the end of the function to execute them.
+//Use Transaction instead for a pipeline whose commands are run atomically, or Batch for one whose commands are issued from all over
 func (this Client) Pipeline(callback func(SafeExecutor)) {
 	this.piping(func(e SafeExecutor) bool {
 		callback(e)
@@ -65,7 +177,11 @@ func (this Client) Pipeline(callback func(SafeExecutor)) {
 }
 
 //Transaction creates an Executor that will tell redis to queue all of the commands and complete them atomically
-//(this prevents other clients from issuing commands in between yours)
+//(this prevents other clients from issuing commands in between yours).
+//The whole transaction, MULTI through EXEC, is sent in a single pipeline, and each command gets its reply from within EXEC's reply.
+//If any command can't be queued (ie, it has the wrong number of arguments), none of them are run, and they are all given that error.
+//Panicking within the callback discards the transaction without sending anything.
+//Use Begin instead for a transaction that Watches keys first
 func (this Client) Transaction(callback func(SafeExecutor)) {
 	this.piping(func(p SafeExecutor) (result bool) {
 		NilCommand(p, "MULTI")
@@ -83,3 +199,128 @@ func (this Client) Transaction(callback func(SafeExecutor)) {
 		return true
 	}, true)
 }
+
+//A Transaction is an executor which queues every command issued on it, then runs them all atomically with Exec, like the Transaction callback does.
+//It can also Watch keys first, so that Exec runs nothing if any of them change before then (optimistic locking);
+//ie, Watch a key, read it as normal, then issue the writes that depend on what was read on the Transaction and Exec it, retrying if it gives back false.
+//The channels the queued commands give back don't get anything until Exec has finished, so don't wait on them before calling it.
+//Once it has been Exec'd or Discarded, the Transaction is empty again and can be used for the next one
+type Transaction struct {
+	client *Client
+	mutex  sync.Mutex
+	pipe   *pipe
+	conn   *Connection //	the connection held on to from the first Watch until Exec or Discard, since WATCH only applies to the connection it was sent on
+}
+
+//Begin creates an empty Transaction, which will be run on this client.
+//(This is a lightweight function - does *not* involve network I/O)
+func (this *Client) Begin() *Transaction {
+	return &Transaction{client: this, pipe: this.newPipe()}
+}
+
+func (this *Transaction) Execute(command command) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.pipe.Execute(command)
+}
+
+func (this *Transaction) errCallback(err error, s string) {
+	this.client.errCallback(err, s)
+}
+
+func (this *Transaction) configuration() Config {
+	return this.client.configuration()
+}
+
+//WATCH command - 
+//Watch has Exec run nothing if any of the keys are changed (by anybody) between now and then.
+//This takes a connection out of the pool for the Transaction until it is Exec'd or Discarded.
+//The channel gets nil once redis is watching the keys, or the error if it couldn't
+func (this *Transaction) Watch(keys ...string) <-chan error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.conn == nil {
+		conn, err := this.client.borrow(nil)
+		if err != nil {
+			return ErrorCommand(failingExecutor{this.client, err}, "WATCH")
+		}
+		this.conn = conn
+	}
+	return ErrorCommand(*this.conn, append([]string{"WATCH"}, keys...)...)
+}
+
+//take empties the Transaction, giving back what it was holding
+func (this *Transaction) take() ([]command, *Connection) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	commands, conn := this.pipe.commands, this.conn
+	this.pipe, this.conn = this.client.newPipe(), nil
+	return commands, conn
+}
+
+//MULTI and EXEC commands - 
+//Exec sends every command issued on the Transaction so far, wrapped in MULTI and EXEC, so that they all run at once;
+//the channel gets true once they have run (and their own channels have their results),
+//or false if a watched key had changed so none of them ran (their channels close without a value).
+//If it couldn't run for any other reason (ie, a command couldn't be queued), the error goes to the error callback, and the channel closes without a value
+func (this *Transaction) Exec() <-chan bool {
+	commands, conn := this.take()
+	out := make(chan bool, 1)
+	go func() {
+		defer close(out)
+		if conn == nil {
+			var err error
+			if conn, err = this.client.borrow(nil); err != nil {
+				for _, command := range commands {
+					fail(command, err)
+				}
+				this.client.errCallback(err, "EXEC")
+				return
+			}
+		}
+		defer this.client.giveBack(conn)
+
+		bundle, _ := buildCommand([]string{"MULTI"})
+		for _, command := range commands {
+			comm, err := buildCommand(command.arguments())
+			if err != nil {
+				this.client.errCallback(err, "piping")
+			}
+			bundle = append(bundle, comm...)
+		}
+		exec, _ := buildCommand([]string{"EXEC"})
+		bundle = append(bundle, exec...)
+
+		this.client.limiter.wait(len(commands) + 2)
+		if _, err := conn.Write(bundle); conn.check(err) != nil {
+			for _, command := range commands {
+				fail(command, err)
+			}
+			this.client.errCallback(err, "EXEC")
+			return
+		}
+		err := this.client.execute(conn, commands)
+		if err == errDiscarded {
+			out <- false
+		} else if err != nil {
+			this.client.errCallback(err, "EXEC")
+		} else {
+			out <- true
+		}
+	}()
+	return out
+}
+
+//UNWATCH command - 
+//Discard throws away every command issued on the Transaction so far without sending them (their channels close without a value),
+//and stops watching any keys
+func (this *Transaction) Discard() {
+	commands, conn := this.take()
+	for _, command := range commands {
+		fail(command, errDiscarded)
+	}
+	if conn != nil {
+		<-NilCommand(*conn, "UNWATCH")
+		this.client.giveBack(conn)
+	}
+}
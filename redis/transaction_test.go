@@ -115,3 +115,142 @@ func TestTransaction(t *testing.T) {
 		t.Error("c should be C")
 	}
 }
+
+func TestTransactionQueueError(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	a := r.String("Transaction_Test_QueueError")
+	<-a.Delete()
+
+	var set, broken <-chan error
+	r.Transaction(func(e SafeExecutor) {
+		set = ErrorCommand(e, "SET", a.key, "A")
+		broken = ErrorCommand(e, "SET", a.key)
+	})
+
+	if err := <-set; err == nil {
+		t.Error("Nothing should run when a command can't be queued")
+	}
+	if err := <-broken; err == nil {
+		t.Error("The command that couldn't be queued should have gotten an error")
+	}
+	if res := <-a.Exists(); res {
+		t.Error("The key should not have been set")
+	}
+	<-a.Set("B")
+	if res := <-a.Get(); res != "B" {
+		t.Error("The client should still be usable after a transaction fails, not", res)
+	}
+	<-a.Delete()
+}
+
+func TestBatch(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	ss := r.SortedSet("Batch_Test")
+	<-ss.Delete()
+	defer func() { <-ss.Delete() }()
+	<-ss.Add("A", 1)
+	<-ss.Add("B", 2)
+
+	batch := r.Batch()
+	a := ss.Use(batch).ScoreOf("A")
+	b := ss.Use(batch).ScoreOf("B")
+	added := ss.Use(batch).Add("C", 3)
+	if batch.Size() != 3 {
+		t.Error("The batch should be holding 3 commands, not", batch.Size())
+	}
+	if res := <-ss.Size(); res != 2 {
+		t.Error("Nothing should be sent before the batch is run, but the size is", res)
+	}
+
+	<-batch.Run()
+	if <-a != 1 || <-b != 2 || !<-added {
+		t.Error("Every command should have its result once the batch has run")
+	}
+	if batch.Size() != 0 {
+		t.Error("The batch should be empty after running, not", batch.Size())
+	}
+	if _, ok := <-batch.Run(); !ok {
+		t.Error("Running an empty batch should still finish")
+	}
+}
+
+func TestTransactionExec(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	i := r.Integer("Transaction_Test_Exec")
+	<-i.Delete()
+	defer func() { <-i.Delete() }()
+
+	tx := r.Begin()
+	first := i.Use(tx).IncrementBy(1)
+	second := i.Use(tx).IncrementBy(2)
+	if res := <-i.Get(); res != 0 {
+		t.Error("Nothing should run before Exec, but got", res)
+	}
+	if !<-tx.Exec() {
+		t.Error("A transaction without any watched keys should always run")
+	}
+	if <-first != 1 || <-second != 3 {
+		t.Error("Each command should get its own reply from within EXEC's")
+	}
+}
+
+func TestTransactionWatch(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	i := r.Integer("Transaction_Test_Watch")
+	<-i.Delete()
+	<-i.Set(1)
+	defer func() { <-i.Delete() }()
+
+	tx := r.Begin()
+	if err := <-tx.Watch(i.key); err != nil {
+		t.Fatal("Should be able to watch a key -", err)
+	}
+	<-i.Set(5)
+	set := i.Use(tx).Set(10)
+	if <-tx.Exec() {
+		t.Error("A transaction should not run once a watched key has changed")
+	}
+	if _, ok := <-set; ok {
+		t.Error("The commands of a transaction that didn't run shouldn't get anything")
+	}
+	if res := <-i.Get(); res != 5 {
+		t.Error("The key should still be 5, not", res)
+	}
+
+	<-tx.Watch(i.key)
+	i.Use(tx).Set(10)
+	if !<-tx.Exec() {
+		t.Error("A transaction should run when its watched keys haven't changed")
+	}
+	if res := <-i.Get(); res != 10 {
+		t.Error("The key should be 10 now, not", res)
+	}
+}
+
+func TestTransactionDiscard(t *testing.T) {
+	r := GetRedis(t)
+	defer r.Close()
+
+	i := r.Integer("Transaction_Test_Discard")
+	<-i.Delete()
+	defer func() { <-i.Delete() }()
+
+	tx := r.Begin()
+	<-tx.Watch(i.key)
+	set := i.Use(tx).Set(10)
+	tx.Discard()
+	if _, ok := <-set; ok {
+		t.Error("A discarded command shouldn't get anything")
+	}
+	if res, ok := <-i.Get(); ok {
+		t.Error("Nothing should have been set, but got", res)
+	}
+}